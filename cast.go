@@ -80,7 +80,7 @@ func memefishCastExprToGCV(cast *ast.CastExpr, o evalOptions) (spanner.GenericCo
 		return gcvctor.NullOf(destType), nil
 	}
 
-	gcv, err := castGCV(src, destType, cast.Expr.SQL())
+	gcv, err := castGCV(src, destType, o.castContext(cast.Expr.SQL()))
 	if err == nil {
 		return gcv, nil
 	}
@@ -90,7 +90,20 @@ func memefishCastExprToGCV(cast *ast.CastExpr, o evalOptions) (spanner.GenericCo
 	return zeroGCV, err
 }
 
-func castGCV(src spanner.GenericColumnValue, destType *sppb.Type, exprSQL string) (spanner.GenericColumnValue, error) {
+// CastGCV converts a value to destType with the semantics of
+// CAST(<src> AS <destType>), including NULL propagation and the temporal
+// default time zone. Unsupported type pairs return an error wrapping
+// [ErrUnsupportedCast]; see [CastSupported]. [WithTimeZone] and
+// [WithRedactedValues] apply; other options are ignored.
+func CastGCV(src spanner.GenericColumnValue, destType *sppb.Type, opts ...EvalOption) (spanner.GenericColumnValue, error) {
+	if src.Type == nil || destType == nil {
+		return zeroGCV, fmt.Errorf("%w: missing source or destination type", ErrUnsupportedCast)
+	}
+	o := applyEvalOptions(opts)
+	return castGCV(src, destType, o.castContext(""))
+}
+
+func castGCV(src spanner.GenericColumnValue, destType *sppb.Type, c castContext) (spanner.GenericColumnValue, error) {
 	srcCode := src.Type.GetCode()
 	destCode := destType.GetCode()
 	if retyped, err := gcvctor.WithEquivalentType(destType, src); err == nil {
@@ -99,40 +112,53 @@ func castGCV(src spanner.GenericColumnValue, destType *sppb.Type, exprSQL string
 	if isNullGCV(src) {
 		return gcvctor.NullOf(destType), nil
 	}
+	if _, ok := lookupCastRule(srcCode, destCode); !ok {
+		return zeroGCV, unsupportedCastError(srcCode, destCode, c)
+	}
+	return castGCVUnchecked(src, destType, c)
+}
 
+// castGCVUnchecked dispatches a non-NULL cast to the castGCVTo* helpers
+// without consulting castTable. Each helper switches on the source type
+// code and must handle exactly the sources castTable lists for its
+// destination; the tests cast every pair through this function to check
+// that the two agree.
+func castGCVUnchecked(src spanner.GenericColumnValue, destType *sppb.Type, c castContext) (spanner.GenericColumnValue, error) {
+	srcCode := src.Type.GetCode()
+	destCode := destType.GetCode()
 	switch destCode {
 	case sppb.TypeCode_BOOL:
-		return castGCVToBool(src, exprSQL)
+		return castGCVToBool(src, c)
 	case sppb.TypeCode_INT64:
-		return castGCVToInt64(src, exprSQL)
+		return castGCVToInt64(src, c)
 	case sppb.TypeCode_FLOAT32:
-		return castGCVToFloat32(src, exprSQL)
+		return castGCVToFloat32(src, c)
 	case sppb.TypeCode_FLOAT64:
-		return castGCVToFloat64(src, exprSQL)
+		return castGCVToFloat64(src, c)
 	case sppb.TypeCode_NUMERIC:
-		return castGCVToNumeric(src, exprSQL)
+		return castGCVToNumeric(src, c)
 	case sppb.TypeCode_STRING:
-		return castGCVToString(src, exprSQL)
+		return castGCVToString(src, c)
 	case sppb.TypeCode_BYTES:
-		return castGCVToBytes(src, exprSQL)
+		return castGCVToBytes(src, c)
 	case sppb.TypeCode_DATE:
-		return castGCVToDate(src, exprSQL)
+		return castGCVToDate(src, c)
 	case sppb.TypeCode_TIMESTAMP:
-		return castGCVToTimestamp(src, exprSQL)
+		return castGCVToTimestamp(src, c)
 	case sppb.TypeCode_UUID:
-		return castGCVToUUID(src, exprSQL)
+		return castGCVToUUID(src, c)
 	case sppb.TypeCode_INTERVAL:
-		return castStringBasedGCV(src, destCode, exprSQL)
+		return castStringBasedGCV(src, destCode, c)
 	case sppb.TypeCode_ARRAY:
-		return castGCVToArray(src, destType, exprSQL)
+		return castGCVToArray(src, destType, c)
 	case sppb.TypeCode_STRUCT:
-		return castGCVToStruct(src, destType, exprSQL)
+		return castGCVToStruct(src, destType, c)
 	default:
-		return zeroGCV, unsupportedCastError(srcCode, destCode, exprSQL)
+		return zeroGCV, unsupportedCastError(srcCode, destCode, c)
 	}
 }
 
-func castGCVToBool(src spanner.GenericColumnValue, exprSQL string) (spanner.GenericColumnValue, error) {
+func castGCVToBool(src spanner.GenericColumnValue, c castContext) (spanner.GenericColumnValue, error) {
 	switch src.Type.GetCode() {
 	case sppb.TypeCode_INT64:
 		v, err := int64FromGCV(src)
@@ -153,14 +179,14 @@ func castGCVToBool(src spanner.GenericColumnValue, exprSQL string) (spanner.Gene
 		case strings.EqualFold(v, "false"):
 			return gcvctor.BoolValue(false), nil
 		default:
-			return zeroGCV, fmt.Errorf("invalid BOOL literal for cast of %s to BOOL: %q", exprSQL, v)
+			return zeroGCV, fmt.Errorf("invalid BOOL literal for cast of %s to BOOL: %s", c.expr(), c.value(v))
 		}
 	default:
-		return zeroGCV, unsupportedCastError(src.Type.GetCode(), sppb.TypeCode_BOOL, exprSQL)
+		return zeroGCV, unsupportedCastError(src.Type.GetCode(), sppb.TypeCode_BOOL, c)
 	}
 }
 
-func castGCVToInt64(src spanner.GenericColumnValue, exprSQL string) (spanner.GenericColumnValue, error) {
+func castGCVToInt64(src spanner.GenericColumnValue, c castContext) (spanner.GenericColumnValue, error) {
 	switch src.Type.GetCode() {
 	case sppb.TypeCode_BOOL:
 		v, err := boolFromGCV(src)
@@ -178,7 +204,7 @@ func castGCVToInt64(src spanner.GenericColumnValue, exprSQL string) (spanner.Gen
 		}
 		i, err := parseSpannerInt64(v)
 		if err != nil {
-			return zeroGCV, err
+			return zeroGCV, c.cause(err)
 		}
 		return gcvctor.Int64Value(i), nil
 	case sppb.TypeCode_FLOAT32, sppb.TypeCode_FLOAT64:
@@ -186,7 +212,7 @@ func castGCVToInt64(src spanner.GenericColumnValue, exprSQL string) (spanner.Gen
 		if err != nil {
 			return zeroGCV, err
 		}
-		i, err := roundFloatToInt64(v, exprSQL)
+		i, err := roundFloatToInt64(v, c)
 		if err != nil {
 			return zeroGCV, err
 		}
@@ -196,30 +222,30 @@ func castGCVToInt64(src spanner.GenericColumnValue, exprSQL string) (spanner.Gen
 		if err != nil {
 			return zeroGCV, err
 		}
-		i, err := roundRatToInt64(v, exprSQL)
+		i, err := roundRatToInt64(v, c)
 		if err != nil {
 			return zeroGCV, err
 		}
 		return gcvctor.Int64Value(i), nil
 	default:
-		return zeroGCV, unsupportedCastError(src.Type.GetCode(), sppb.TypeCode_INT64, exprSQL)
+		return zeroGCV, unsupportedCastError(src.Type.GetCode(), sppb.TypeCode_INT64, c)
 	}
 }
 
-func castGCVToFloat32(src spanner.GenericColumnValue, exprSQL string) (spanner.GenericColumnValue, error) {
+func castGCVToFloat32(src spanner.GenericColumnValue, c castContext) (spanner.GenericColumnValue, error) {
 	switch src.Type.GetCode() {
 	case sppb.TypeCode_INT64:
 		v, err := int64FromGCV(src)
 		if err != nil {
 			return zeroGCV, err
 		}
-		return float32ValueFromFloat64(float64(v))
+		return float32ValueFromFloat64(float64(v), c)
 	case sppb.TypeCode_FLOAT64, sppb.TypeCode_FLOAT32:
 		v, err := float64FromGCV(src, 32)
 		if err != nil {
 			return zeroGCV, err
 		}
-		return float32ValueFromFloat64(v)
+		return float32ValueFromFloat64(v, c)
 	case sppb.TypeCode_NUMERIC:
 		v, err := numericFromGCV(src)
 		if err != nil {
@@ -234,15 +260,15 @@ func castGCVToFloat32(src spanner.GenericColumnValue, exprSQL string) (spanner.G
 		}
 		f, err := parseSpannerFloat(v, 32)
 		if err != nil {
-			return zeroGCV, err
+			return zeroGCV, c.cause(err)
 		}
-		return float32ValueFromFloat64(f)
+		return float32ValueFromFloat64(f, c)
 	default:
-		return zeroGCV, unsupportedCastError(src.Type.GetCode(), sppb.TypeCode_FLOAT32, exprSQL)
+		return zeroGCV, unsupportedCastError(src.Type.GetCode(), sppb.TypeCode_FLOAT32, c)
 	}
 }
 
-func castGCVToFloat64(src spanner.GenericColumnValue, exprSQL string) (spanner.GenericColumnValue, error) {
+func castGCVToFloat64(src spanner.GenericColumnValue, c castContext) (spanner.GenericColumnValue, error) {
 	switch src.Type.GetCode() {
 	case sppb.TypeCode_INT64:
 		v, err := int64FromGCV(src)
@@ -270,15 +296,15 @@ func castGCVToFloat64(src spanner.GenericColumnValue, exprSQL string) (spanner.G
 		}
 		f, err := parseSpannerFloat(v, 64)
 		if err != nil {
-			return zeroGCV, err
+			return zeroGCV, c.cause(err)
 		}
 		return gcvctor.Float64Value(f), nil
 	default:
-		return zeroGCV, unsupportedCastError(src.Type.GetCode(), sppb.TypeCode_FLOAT64, exprSQL)
+		return zeroGCV, unsupportedCastError(src.Type.GetCode(), sppb.TypeCode_FLOAT64, c)
 	}
 }
 
-func castGCVToNumeric(src spanner.GenericColumnValue, exprSQL string) (spanner.GenericColumnValue, error) {
+func castGCVToNumeric(src spanner.GenericColumnValue, c castContext) (spanner.GenericColumnValue, error) {
 	switch src.Type.GetCode() {
 	case sppb.TypeCode_INT64:
 		v, err := int64FromGCV(src)
@@ -291,23 +317,23 @@ func castGCVToNumeric(src spanner.GenericColumnValue, exprSQL string) (spanner.G
 		if err != nil {
 			return zeroGCV, err
 		}
-		return float64ToNumericValue(v, exprSQL)
+		return float64ToNumericValue(v, c)
 	case sppb.TypeCode_STRING:
 		v, err := stringFromGCV(src)
 		if err != nil {
 			return zeroGCV, err
 		}
-		n, err := parseNumericLiteralForCast(v, exprSQL)
+		n, err := parseNumericLiteralForCast(v, c)
 		if err != nil {
 			return zeroGCV, err
 		}
 		return gcvctor.NumericValueChecked(n)
 	default:
-		return zeroGCV, unsupportedCastError(src.Type.GetCode(), sppb.TypeCode_NUMERIC, exprSQL)
+		return zeroGCV, unsupportedCastError(src.Type.GetCode(), sppb.TypeCode_NUMERIC, c)
 	}
 }
 
-func castGCVToString(src spanner.GenericColumnValue, exprSQL string) (spanner.GenericColumnValue, error) {
+func castGCVToString(src spanner.GenericColumnValue, c castContext) (spanner.GenericColumnValue, error) {
 	switch src.Type.GetCode() {
 	case sppb.TypeCode_BOOL:
 		v, err := boolFromGCV(src)
@@ -332,11 +358,11 @@ func castGCVToString(src spanner.GenericColumnValue, exprSQL string) (spanner.Ge
 		if wireValue == commitTimestampPlaceholderString {
 			return gcvctor.StringValue(wireValue), nil
 		}
-		v, err := parseTimestampWireValueForCast(wireValue, exprSQL)
+		v, err := parseTimestampWireValueForCast(wireValue, c)
 		if err != nil {
 			return zeroGCV, err
 		}
-		loc, err := loadSpannerDefaultLocation()
+		loc, err := c.location()
 		if err != nil {
 			return zeroGCV, err
 		}
@@ -365,18 +391,18 @@ func castGCVToString(src spanner.GenericColumnValue, exprSQL string) (spanner.Ge
 			return zeroGCV, err
 		}
 		if !utf8.Valid(v) {
-			return zeroGCV, fmt.Errorf("invalid UTF-8 bytes for STRING cast in expression %q", exprSQL)
+			return zeroGCV, fmt.Errorf("invalid UTF-8 bytes for STRING cast in expression %q", c.expr())
 		}
 		return gcvctor.StringValue(string(v)), nil
 	default:
-		return zeroGCV, unsupportedCastError(src.Type.GetCode(), sppb.TypeCode_STRING, exprSQL)
+		return zeroGCV, unsupportedCastError(src.Type.GetCode(), sppb.TypeCode_STRING, c)
 	}
 }
 
-func castGCVToBytes(src spanner.GenericColumnValue, exprSQL string) (spanner.GenericColumnValue, error) {
+func castGCVToBytes(src spanner.GenericColumnValue, c castContext) (spanner.GenericColumnValue, error) {
 	code := src.Type.GetCode()
 	if code != sppb.TypeCode_STRING && code != sppb.TypeCode_UUID {
-		return zeroGCV, unsupportedCastError(code, sppb.TypeCode_BYTES, exprSQL)
+		return zeroGCV, unsupportedCastError(code, sppb.TypeCode_BYTES, c)
 	}
 
 	v, err := stringFromGCV(src)
@@ -390,12 +416,12 @@ func castGCVToBytes(src spanner.GenericColumnValue, exprSQL string) (spanner.Gen
 
 	u, err := uuid.Parse(v)
 	if err != nil {
-		return zeroGCV, fmt.Errorf("invalid UUID value %q for cast of %s to BYTES: %w", v, exprSQL, err)
+		return zeroGCV, fmt.Errorf("invalid UUID value %s for cast of %s to BYTES: %w", c.value(v), c.expr(), c.cause(err))
 	}
 	return gcvctor.BytesValue(u[:]), nil
 }
 
-func castGCVToDate(src spanner.GenericColumnValue, exprSQL string) (spanner.GenericColumnValue, error) {
+func castGCVToDate(src spanner.GenericColumnValue, c castContext) (spanner.GenericColumnValue, error) {
 	switch src.Type.GetCode() {
 	case sppb.TypeCode_STRING:
 		v, err := stringFromGCV(src)
@@ -404,48 +430,48 @@ func castGCVToDate(src spanner.GenericColumnValue, exprSQL string) (spanner.Gene
 		}
 		d, err := gcvctor.DateStringValue(v)
 		if err != nil {
-			return zeroGCV, fmt.Errorf("invalid DATE literal for cast of %s to DATE: %q: %w", exprSQL, v, err)
+			return zeroGCV, fmt.Errorf("invalid DATE literal for cast of %s to DATE: %s: %w", c.expr(), c.value(v), c.cause(err))
 		}
 		return d, nil
 	case sppb.TypeCode_TIMESTAMP:
-		v, err := timestampFromGCV(src, exprSQL)
+		v, err := timestampFromGCV(src, c)
 		if err != nil {
 			return zeroGCV, err
 		}
-		loc, err := loadSpannerDefaultLocation()
+		loc, err := c.location()
 		if err != nil {
 			return zeroGCV, err
 		}
 		return gcvctor.DateValue(civil.DateOf(v.In(loc))), nil
 	default:
-		return zeroGCV, unsupportedCastError(src.Type.GetCode(), sppb.TypeCode_DATE, exprSQL)
+		return zeroGCV, unsupportedCastError(src.Type.GetCode(), sppb.TypeCode_DATE, c)
 	}
 }
 
-func castGCVToTimestamp(src spanner.GenericColumnValue, exprSQL string) (spanner.GenericColumnValue, error) {
+func castGCVToTimestamp(src spanner.GenericColumnValue, c castContext) (spanner.GenericColumnValue, error) {
 	switch src.Type.GetCode() {
 	case sppb.TypeCode_STRING:
 		v, err := stringFromGCV(src)
 		if err != nil {
 			return zeroGCV, err
 		}
-		return timestampStringValueForCast(v, exprSQL)
+		return timestampStringValueForCast(v, c)
 	case sppb.TypeCode_DATE:
 		v, err := dateFromGCV(src)
 		if err != nil {
 			return zeroGCV, err
 		}
-		loc, err := loadSpannerDefaultLocation()
+		loc, err := c.location()
 		if err != nil {
 			return zeroGCV, err
 		}
 		return gcvctor.TimestampValue(v.In(loc).UTC()), nil
 	default:
-		return zeroGCV, unsupportedCastError(src.Type.GetCode(), sppb.TypeCode_TIMESTAMP, exprSQL)
+		return zeroGCV, unsupportedCastError(src.Type.GetCode(), sppb.TypeCode_TIMESTAMP, c)
 	}
 }
 
-func castGCVToUUID(src spanner.GenericColumnValue, exprSQL string) (spanner.GenericColumnValue, error) {
+func castGCVToUUID(src spanner.GenericColumnValue, c castContext) (spanner.GenericColumnValue, error) {
 	switch src.Type.GetCode() {
 	case sppb.TypeCode_STRING:
 		v, err := stringFromGCV(src)
@@ -454,7 +480,7 @@ func castGCVToUUID(src spanner.GenericColumnValue, exprSQL string) (spanner.Gene
 		}
 		u, err := uuid.Parse(v)
 		if err != nil || !strings.EqualFold(u.String(), v) {
-			return zeroGCV, fmt.Errorf("invalid UUID literal for cast of %s to UUID: %q", exprSQL, v)
+			return zeroGCV, fmt.Errorf("invalid UUID literal for cast of %s to UUID: %s", c.expr(), c.value(v))
 		}
 		return gcvctor.UUIDValue(u), nil
 	case sppb.TypeCode_BYTES:
@@ -463,21 +489,21 @@ func castGCVToUUID(src spanner.GenericColumnValue, exprSQL string) (spanner.Gene
 			return zeroGCV, err
 		}
 		if len(v) != 16 {
-			return zeroGCV, fmt.Errorf("invalid BYTES length for cast of %s to UUID: expected 16, got %d", exprSQL, len(v))
+			return zeroGCV, fmt.Errorf("invalid BYTES length for cast of %s to UUID: expected 16, got %d", c.expr(), len(v))
 		}
 		u, err := uuid.FromBytes(v)
 		if err != nil {
-			return zeroGCV, fmt.Errorf("invalid BYTES value for cast of %s to UUID: %w", exprSQL, err)
+			return zeroGCV, fmt.Errorf("invalid BYTES value for cast of %s to UUID: %w", c.expr(), c.cause(err))
 		}
 		return gcvctor.UUIDValue(u), nil
 	default:
-		return zeroGCV, unsupportedCastError(src.Type.GetCode(), sppb.TypeCode_UUID, exprSQL)
+		return zeroGCV, unsupportedCastError(src.Type.GetCode(), sppb.TypeCode_UUID, c)
 	}
 }
 
-func castStringBasedGCV(src spanner.GenericColumnValue, destCode sppb.TypeCode, exprSQL string) (spanner.GenericColumnValue, error) {
+func castStringBasedGCV(src spanner.GenericColumnValue, destCode sppb.TypeCode, c castContext) (spanner.GenericColumnValue, error) {
 	if src.Type.GetCode() != sppb.TypeCode_STRING {
-		return zeroGCV, unsupportedCastError(src.Type.GetCode(), destCode, exprSQL)
+		return zeroGCV, unsupportedCastError(src.Type.GetCode(), destCode, c)
 	}
 	v, err := stringFromGCV(src)
 	if err != nil {
@@ -485,15 +511,19 @@ func castStringBasedGCV(src spanner.GenericColumnValue, destCode sppb.TypeCode,
 	}
 	switch destCode {
 	case sppb.TypeCode_INTERVAL:
-		return gcvctor.IntervalStringValue(v)
+		gcv, err := gcvctor.IntervalStringValue(v)
+		if err != nil {
+			return zeroGCV, c.cause(err)
+		}
+		return gcv, nil
 	default:
 		return gcvctor.StringBasedValueFromCode(destCode, v), nil
 	}
 }
 
-func castGCVToArray(src spanner.GenericColumnValue, destType *sppb.Type, exprSQL string) (spanner.GenericColumnValue, error) {
+func castGCVToArray(src spanner.GenericColumnValue, destType *sppb.Type, c castContext) (spanner.GenericColumnValue, error) {
 	if src.Type.GetCode() != sppb.TypeCode_ARRAY {
-		return zeroGCV, unsupportedCastError(src.Type.GetCode(), sppb.TypeCode_ARRAY, exprSQL)
+		return zeroGCV, unsupportedCastError(src.Type.GetCode(), sppb.TypeCode_ARRAY, c)
 	}
 	// Real Cloud Spanner does not support element-wise ARRAY casts such as
 	// CAST([1] AS ARRAY<FLOAT64>) or SAFE_CAST(["x"] AS ARRAY<DATE>). The
@@ -504,49 +534,49 @@ func castGCVToArray(src spanner.GenericColumnValue, destType *sppb.Type, exprSQL
 	// https://github.com/google/googlesql/blob/36dd14aa0657ea299725504bc0f938732f58f380/googlesql/public/cast.h#L45-L66
 	// https://github.com/google/googlesql/blob/36dd14aa0657ea299725504bc0f938732f58f380/googlesql/public/cast.cc#L282-L289
 	if !spantype.EquivalentTypes(src.Type, destType) {
-		return zeroGCV, unsupportedArrayCastError(src.Type, destType, exprSQL)
+		return zeroGCV, unsupportedArrayCastError(src.Type, destType, c)
 	}
 	return gcvctor.WithEquivalentType(destType, src)
 }
 
-func castGCVToStruct(src spanner.GenericColumnValue, destType *sppb.Type, exprSQL string) (spanner.GenericColumnValue, error) {
+func castGCVToStruct(src spanner.GenericColumnValue, destType *sppb.Type, c castContext) (spanner.GenericColumnValue, error) {
 	if src.Type.GetCode() != sppb.TypeCode_STRUCT {
-		return zeroGCV, unsupportedCastError(src.Type.GetCode(), sppb.TypeCode_STRUCT, exprSQL)
+		return zeroGCV, unsupportedCastError(src.Type.GetCode(), sppb.TypeCode_STRUCT, c)
 	}
 	srcStructType := src.Type.GetStructType()
 	if srcStructType == nil {
-		return zeroGCV, fmt.Errorf("malformed STRUCT source type%s", exprContextSuffix(exprSQL))
+		return zeroGCV, fmt.Errorf("malformed STRUCT source type%s", exprContextSuffix(c))
 	}
 	srcFields := srcStructType.GetFields()
 	destStructType := destType.GetStructType()
 	if destStructType == nil {
-		return zeroGCV, fmt.Errorf("malformed STRUCT destination type%s", exprContextSuffix(exprSQL))
+		return zeroGCV, fmt.Errorf("malformed STRUCT destination type%s", exprContextSuffix(c))
 	}
 	destFields := destStructType.GetFields()
 	if len(srcFields) != len(destFields) {
-		return zeroGCV, fmt.Errorf("cannot cast STRUCT with %d fields to STRUCT with %d fields%s", len(srcFields), len(destFields), exprContextSuffix(exprSQL))
+		return zeroGCV, fmt.Errorf("%w: STRUCT with %d fields to STRUCT with %d fields%s", ErrUnsupportedCast, len(srcFields), len(destFields), exprContextSuffix(c))
 	}
 	// Cloud Spanner ignores field names during STRUCT CAST and only requires
 	// the number of fields to match, so name parity is intentionally not enforced.
 	listValue, ok := src.Value.GetKind().(*structpb.Value_ListValue)
 	if !ok {
-		return zeroGCV, fmt.Errorf("expected STRUCT wire value, got %T%s", src.Value.GetKind(), exprContextSuffix(exprSQL))
+		return zeroGCV, fmt.Errorf("expected STRUCT wire value, got %T%s", src.Value.GetKind(), exprContextSuffix(c))
 	}
 	if listValue.ListValue == nil {
-		return zeroGCV, fmt.Errorf("malformed STRUCT wire value: missing ListValue detail%s", exprContextSuffix(exprSQL))
+		return zeroGCV, fmt.Errorf("malformed STRUCT wire value: missing ListValue detail%s", exprContextSuffix(c))
 	}
 	values := listValue.ListValue.Values
 	if len(values) != len(srcFields) {
-		return zeroGCV, fmt.Errorf("STRUCT wire value has %d fields, but type has %d fields%s", len(values), len(srcFields), exprContextSuffix(exprSQL))
+		return zeroGCV, fmt.Errorf("STRUCT wire value has %d fields, but type has %d fields%s", len(values), len(srcFields), exprContextSuffix(c))
 	}
 	coerced := make([]*structpb.Value, len(values))
 	for i, v := range values {
 		elemGCV := spanner.GenericColumnValue{Type: srcFields[i].Type, Value: v}
-		casted, err := castGCV(elemGCV, destFields[i].Type, exprSQL)
+		casted, err := castGCV(elemGCV, destFields[i].Type, c)
 		if err != nil {
 			return zeroGCV, fmt.Errorf("cannot cast struct field %d from %v to %v: %w", i, srcFields[i].Type.GetCode(), destFields[i].Type.GetCode(), err)
 		}
-		coerced[i] = gcvToProtoValue(casted)
+		coerced[i] = ToProtoValue(casted)
 	}
 	return spanner.GenericColumnValue{
 		Type:  destType,
@@ -598,34 +628,34 @@ func dateFromGCV(gcv spanner.GenericColumnValue) (civil.Date, error) {
 	return civil.ParseDate(v)
 }
 
-func timestampFromGCV(gcv spanner.GenericColumnValue, exprSQL string) (time.Time, error) {
+func timestampFromGCV(gcv spanner.GenericColumnValue, c castContext) (time.Time, error) {
 	v, err := stringFromGCV(gcv)
 	if err != nil {
 		return time.Time{}, err
 	}
 	if v == commitTimestampPlaceholderString {
-		return time.Time{}, fmt.Errorf("cannot cast pending commit timestamp placeholder%s", exprContextSuffix(exprSQL))
+		return time.Time{}, fmt.Errorf("cannot cast pending commit timestamp placeholder%s", exprContextSuffix(c))
 	}
-	return parseTimestampWireValueForCast(v, exprSQL)
+	return parseTimestampWireValueForCast(v, c)
 }
 
-func parseTimestampWireValueForCast(v, exprSQL string) (time.Time, error) {
-	t, err := parseSpannerTimestampForCast(v)
+func parseTimestampWireValueForCast(v string, c castContext) (time.Time, error) {
+	t, err := parseSpannerTimestampForCast(v, c)
 	if err != nil {
-		return time.Time{}, fmt.Errorf("invalid TIMESTAMP wire value for cast%s: %q: %w", exprContextSuffix(exprSQL), v, err)
+		return time.Time{}, fmt.Errorf("invalid TIMESTAMP wire value for cast%s: %s: %w", exprContextSuffix(c), c.value(v), c.cause(err))
 	}
 	return t, nil
 }
 
-func timestampStringValueForCast(v, exprSQL string) (spanner.GenericColumnValue, error) {
-	t, err := parseSpannerTimestampForCast(v)
+func timestampStringValueForCast(v string, c castContext) (spanner.GenericColumnValue, error) {
+	t, err := parseSpannerTimestampForCast(v, c)
 	if err != nil {
-		return zeroGCV, fmt.Errorf("invalid TIMESTAMP literal for cast of %s to TIMESTAMP: %q: %w", exprSQL, v, err)
+		return zeroGCV, fmt.Errorf("invalid TIMESTAMP literal for cast of %s to TIMESTAMP: %s: %w", c.expr(), c.value(v), c.cause(err))
 	}
 	return gcvctor.TimestampValue(t.UTC()), nil
 }
 
-func parseSpannerTimestampForCast(v string) (time.Time, error) {
+func parseSpannerTimestampForCast(v string, c castContext) (time.Time, error) {
 	if strings.HasSuffix(v, "z") && !hasNamedTimeZoneSuffix(v) {
 		v = strings.TrimSuffix(v, "z") + "Z"
 	}
@@ -642,7 +672,7 @@ func parseSpannerTimestampForCast(v string) (time.Time, error) {
 		return parseSpannerTimestampWithNamedLocation(v)
 	}
 
-	loc, err := loadSpannerDefaultLocation()
+	loc, err := c.location()
 	if err != nil {
 		return time.Time{}, err
 	}
@@ -830,10 +860,10 @@ func parseSpannerFloat(v string, bitSize int) (float64, error) {
 	}
 }
 
-func parseNumericLiteralForCast(v, exprSQL string) (*big.Rat, error) {
+func parseNumericLiteralForCast(v string, c castContext) (*big.Rat, error) {
 	v = strings.TrimSpace(v)
 	if strings.Contains(v, "/") {
-		return nil, fmt.Errorf("invalid NUMERIC literal for cast of %s to NUMERIC: %q", exprSQL, v)
+		return nil, fmt.Errorf("invalid NUMERIC literal for cast of %s to NUMERIC: %s", c.expr(), c.value(v))
 	}
 
 	unsigned := v
@@ -848,7 +878,7 @@ func parseNumericLiteralForCast(v, exprSQL string) (*big.Rat, error) {
 		}
 	}
 	if strings.HasPrefix(unsigned, "0x") || strings.HasPrefix(unsigned, "0X") {
-		return nil, fmt.Errorf("invalid NUMERIC literal for cast of %s to NUMERIC: %q", exprSQL, v)
+		return nil, fmt.Errorf("invalid NUMERIC literal for cast of %s to NUMERIC: %s", c.expr(), c.value(v))
 	}
 
 	mantissa := unsigned
@@ -857,7 +887,7 @@ func parseNumericLiteralForCast(v, exprSQL string) (*big.Rat, error) {
 	if idx := strings.IndexAny(unsigned, "eE"); idx >= 0 {
 		hasExponent = true
 		if strings.ContainsAny(unsigned[idx+1:], "eE") {
-			return nil, fmt.Errorf("invalid NUMERIC literal for cast of %s to NUMERIC: %q", exprSQL, v)
+			return nil, fmt.Errorf("invalid NUMERIC literal for cast of %s to NUMERIC: %s", c.expr(), c.value(v))
 		}
 		expText = unsigned[idx+1:]
 		mantissa = unsigned[:idx]
@@ -867,18 +897,18 @@ func parseNumericLiteralForCast(v, exprSQL string) (*big.Rat, error) {
 	fracDigits := 0
 	if dotIdx := strings.Index(mantissa, "."); dotIdx >= 0 {
 		if strings.LastIndex(mantissa, ".") != dotIdx {
-			return nil, fmt.Errorf("invalid NUMERIC literal for cast of %s to NUMERIC: %q", exprSQL, v)
+			return nil, fmt.Errorf("invalid NUMERIC literal for cast of %s to NUMERIC: %s", c.expr(), c.value(v))
 		}
 		digits = mantissa[:dotIdx] + mantissa[dotIdx+1:]
 		fracDigits = len(mantissa) - 1 - dotIdx
 	}
 	for _, r := range digits {
 		if r < '0' || r > '9' {
-			return nil, fmt.Errorf("invalid NUMERIC literal for cast of %s to NUMERIC: %q", exprSQL, v)
+			return nil, fmt.Errorf("invalid NUMERIC literal for cast of %s to NUMERIC: %s", c.expr(), c.value(v))
 		}
 	}
 	if len(digits) == 0 {
-		return nil, fmt.Errorf("invalid NUMERIC literal for cast of %s to NUMERIC: %q", exprSQL, v)
+		return nil, fmt.Errorf("invalid NUMERIC literal for cast of %s to NUMERIC: %s", c.expr(), c.value(v))
 	}
 
 	trimmedDigits := strings.TrimLeft(digits, "0")
@@ -886,11 +916,11 @@ func parseNumericLiteralForCast(v, exprSQL string) (*big.Rat, error) {
 		return new(big.Rat), nil
 	}
 
-	exp, err := parseNumericExponentForCast(expText, hasExponent, exprSQL, v)
+	exp, err := parseNumericExponentForCast(expText, hasExponent, c, v)
 	if err != nil {
 		return nil, err
 	}
-	scaledInt, err := roundedScaledNumericInt(trimmedDigits, exp, int64(fracDigits), exprSQL, v)
+	scaledInt, err := roundedScaledNumericInt(trimmedDigits, exp, int64(fracDigits), c, v)
 	if err != nil {
 		return nil, err
 	}
@@ -900,47 +930,47 @@ func parseNumericLiteralForCast(v, exprSQL string) (*big.Rat, error) {
 	return new(big.Rat).SetFrac(scaledInt, numericScaleFactor), nil
 }
 
-func parseNumericExponentForCast(expText string, hasExponent bool, exprSQL, original string) (int64, error) {
+func parseNumericExponentForCast(expText string, hasExponent bool, c castContext, original string) (int64, error) {
 	if !hasExponent {
 		return 0, nil
 	}
 	if expText == "" {
-		return 0, fmt.Errorf("invalid NUMERIC literal for cast of %s to NUMERIC: %q", exprSQL, original)
+		return 0, fmt.Errorf("invalid NUMERIC literal for cast of %s to NUMERIC: %s", c.expr(), c.value(original))
 	}
 	exp, err := strconv.ParseInt(expText, 10, 64)
 	if err != nil {
 		var numErr *strconv.NumError
 		if errors.As(err, &numErr) && errors.Is(numErr.Err, strconv.ErrRange) {
-			return 0, fmt.Errorf("NUMERIC value out of range: %q%s", original, exprContextSuffix(exprSQL))
+			return 0, fmt.Errorf("NUMERIC value out of range: %s%s", c.value(original), exprContextSuffix(c))
 		}
-		return 0, fmt.Errorf("invalid NUMERIC literal for cast of %s to NUMERIC: %q", exprSQL, original)
+		return 0, fmt.Errorf("invalid NUMERIC literal for cast of %s to NUMERIC: %s", c.expr(), c.value(original))
 	}
 	return exp, nil
 }
 
-func roundedScaledNumericInt(digits string, exp, fracDigits int64, exprSQL, original string) (*big.Int, error) {
+func roundedScaledNumericInt(digits string, exp, fracDigits int64, c castContext, original string) (*big.Int, error) {
 	scale, ok := safeSubInt64(exp, fracDigits)
 	if !ok {
 		if exp < 0 {
 			return new(big.Int), nil
 		}
-		return nil, fmt.Errorf("NUMERIC value out of range: %q%s", original, exprContextSuffix(exprSQL))
+		return nil, fmt.Errorf("NUMERIC value out of range: %s%s", c.value(original), exprContextSuffix(c))
 	}
 	shift, ok := safeAddInt64(scale, int64(spanner.NumericScaleDigits))
 	if !ok {
 		if scale < 0 {
 			return new(big.Int), nil
 		}
-		return nil, fmt.Errorf("NUMERIC value out of range: %q%s", original, exprContextSuffix(exprSQL))
+		return nil, fmt.Errorf("NUMERIC value out of range: %s%s", c.value(original), exprContextSuffix(c))
 	}
 	digitsLen := int64(len(digits))
 	if shift >= 0 {
 		if digitsLen > int64(spanner.NumericPrecisionDigits)-shift {
-			return nil, fmt.Errorf("NUMERIC value out of range: %q%s", original, exprContextSuffix(exprSQL))
+			return nil, fmt.Errorf("NUMERIC value out of range: %s%s", c.value(original), exprContextSuffix(c))
 		}
 		scaled, ok := new(big.Int).SetString(digits, 10)
 		if !ok {
-			return nil, fmt.Errorf("invalid NUMERIC literal for cast of %s to NUMERIC: %q", exprSQL, original)
+			return nil, fmt.Errorf("invalid NUMERIC literal for cast of %s to NUMERIC: %s", c.expr(), c.value(original))
 		}
 		if shift > 0 {
 			scaled.Mul(scaled, pow10Int(int(shift)))
@@ -959,7 +989,7 @@ func roundedScaledNumericInt(digits string, exp, fracDigits int64, exprSQL, orig
 	}
 	quotient, ok := new(big.Int).SetString(quotientDigits, 10)
 	if !ok {
-		return nil, fmt.Errorf("invalid NUMERIC literal for cast of %s to NUMERIC: %q", exprSQL, original)
+		return nil, fmt.Errorf("invalid NUMERIC literal for cast of %s to NUMERIC: %s", c.expr(), c.value(original))
 	}
 
 	remainderFirstDigit := digits[len(digits)-int(denomDigits)]
@@ -967,7 +997,7 @@ func roundedScaledNumericInt(digits string, exp, fracDigits int64, exprSQL, orig
 		quotient.Add(quotient, big.NewInt(1))
 	}
 	if quotient.Cmp(maxScaledNumeric) > 0 {
-		return nil, fmt.Errorf("NUMERIC value out of range: %q%s", original, exprContextSuffix(exprSQL))
+		return nil, fmt.Errorf("NUMERIC value out of range: %s%s", c.value(original), exprContextSuffix(c))
 	}
 	return quotient, nil
 }
@@ -992,52 +1022,52 @@ func safeSubInt64(a, b int64) (int64, bool) {
 	return a - b, true
 }
 
-func float32ValueFromFloat64(v float64) (spanner.GenericColumnValue, error) {
+func float32ValueFromFloat64(v float64, c castContext) (spanner.GenericColumnValue, error) {
 	f32 := float32(v)
 	if !math.IsInf(v, 0) && math.IsInf(float64(f32), 0) {
-		return zeroGCV, fmt.Errorf("value out of FLOAT32 range: %v", v)
+		return zeroGCV, fmt.Errorf("value out of FLOAT32 range: %s%s", c.number(v), exprContextSuffix(c))
 	}
 	return gcvctor.Float32Value(f32), nil
 }
 
-func float64ToNumericValue(v float64, exprSQL string) (spanner.GenericColumnValue, error) {
+func float64ToNumericValue(v float64, c castContext) (spanner.GenericColumnValue, error) {
 	if math.IsNaN(v) || math.IsInf(v, 0) {
-		return zeroGCV, fmt.Errorf("cannot cast non-finite floating-point value to NUMERIC: %v%s", v, exprContextSuffix(exprSQL))
+		return zeroGCV, fmt.Errorf("cannot cast non-finite floating-point value to NUMERIC: %v%s", v, exprContextSuffix(c))
 	}
 	n := new(big.Rat).SetFloat64(v)
-	n, err := roundRatToNumeric(n, exprSQL)
+	n, err := roundRatToNumeric(n, c)
 	if err != nil {
 		return zeroGCV, err
 	}
 	return gcvctor.NumericValueChecked(n)
 }
 
-func roundFloatToInt64(v float64, exprSQL string) (int64, error) {
+func roundFloatToInt64(v float64, c castContext) (int64, error) {
 	if math.IsNaN(v) || math.IsInf(v, 0) {
-		return 0, fmt.Errorf("cannot cast non-finite floating-point value to INT64: %v%s", v, exprContextSuffix(exprSQL))
+		return 0, fmt.Errorf("cannot cast non-finite floating-point value to INT64: %v%s", v, exprContextSuffix(c))
 	}
 	// Spanner CAST(FLOAT* AS INT64) rounds halfway cases away from zero.
 	rounded := math.Round(v)
 	if rounded < minInt64Float || rounded >= maxInt64FloatExclusive {
-		return 0, fmt.Errorf("floating-point value out of INT64 range: %v%s", v, exprContextSuffix(exprSQL))
+		return 0, fmt.Errorf("floating-point value out of INT64 range: %s%s", c.number(v), exprContextSuffix(c))
 	}
 	return int64(rounded), nil
 }
 
-func roundRatToInt64(v *big.Rat, exprSQL string) (int64, error) {
+func roundRatToInt64(v *big.Rat, c castContext) (int64, error) {
 	rounded := roundRatHalfAwayFromZero(v)
 	if !rounded.IsInt64() {
-		return 0, fmt.Errorf("NUMERIC value out of INT64 range: %s%s", v.FloatString(spanner.NumericScaleDigits), exprContextSuffix(exprSQL))
+		return 0, fmt.Errorf("NUMERIC value out of INT64 range: %s%s", c.number(v.FloatString(spanner.NumericScaleDigits)), exprContextSuffix(c))
 	}
 	return rounded.Int64(), nil
 }
 
-func roundRatToNumeric(v *big.Rat, exprSQL string) (*big.Rat, error) {
+func roundRatToNumeric(v *big.Rat, c castContext) (*big.Rat, error) {
 	scaled := new(big.Rat).Mul(v, new(big.Rat).SetInt(numericScaleFactor))
 	rounded := roundRatHalfAwayFromZero(scaled)
 
 	if new(big.Int).Abs(rounded).Cmp(maxScaledNumeric) > 0 {
-		return nil, fmt.Errorf("NUMERIC value out of range: %s%s", v.FloatString(spanner.NumericScaleDigits), exprContextSuffix(exprSQL))
+		return nil, fmt.Errorf("NUMERIC value out of range: %s%s", c.number(v.FloatString(spanner.NumericScaleDigits)), exprContextSuffix(c))
 	}
 	return new(big.Rat).SetFrac(rounded, numericScaleFactor), nil
 }
@@ -1056,11 +1086,62 @@ func pow10Int(exp int) *big.Int {
 	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)
 }
 
-func exprContextSuffix(exprSQL string) string {
-	if exprSQL == "" {
+// castContext carries the source expression of a cast for error messages,
+// whether the values in those messages must be redacted, and the default
+// time zone of temporal casts.
+type castContext struct {
+	exprSQL string
+	redact  bool
+	loc     *time.Location // nil for Spanner's default time zone
+}
+
+// location returns the time zone for temporal casts without an explicit
+// time zone.
+func (c castContext) location() (*time.Location, error) {
+	if c.loc != nil {
+		return c.loc, nil
+	}
+	return loadSpannerDefaultLocation()
+}
+
+// expr returns the source expression SQL, or RedactedPlaceholder.
+func (c castContext) expr() string {
+	if c.redact && c.exprSQL != "" {
+		return RedactedPlaceholder
+	}
+	return c.exprSQL
+}
+
+// value returns the quoted source value text, or RedactedPlaceholder.
+func (c castContext) value(v string) string {
+	if c.redact {
+		return RedactedPlaceholder
+	}
+	return strconv.Quote(v)
+}
+
+// number formats a numeric source value, or returns RedactedPlaceholder.
+func (c castContext) number(v any) string {
+	if c.redact {
+		return RedactedPlaceholder
+	}
+	return fmt.Sprint(v)
+}
+
+// cause hides the message of an underlying parse error, which usually quotes
+// the input, while keeping it available to errors.Is and errors.As.
+func (c castContext) cause(err error) error {
+	if !c.redact {
+		return err
+	}
+	return RedactError(err)
+}
+
+func exprContextSuffix(c castContext) string {
+	if c.exprSQL == "" {
 		return ""
 	}
-	return ": " + exprSQL
+	return ": " + c.expr()
 }
 
 func formatNumericString(v string) string {
@@ -1087,15 +1168,15 @@ func formatSpannerFloat(v float64, bitSize int) string {
 	}
 }
 
-func unsupportedCastError(srcCode, destCode sppb.TypeCode, exprSQL string) error {
+func unsupportedCastError(srcCode, destCode sppb.TypeCode, c castContext) error {
 	err := fmt.Errorf("%w from %v to %v", ErrUnsupportedCast, srcCode, destCode)
-	if exprSQL != "" {
-		return fmt.Errorf("%w: %s", err, exprSQL)
+	if c.exprSQL != "" {
+		return fmt.Errorf("%w: %s", err, c.expr())
 	}
 	return err
 }
 
-func unsupportedArrayCastError(srcType, destType *sppb.Type, exprSQL string) error {
+func unsupportedArrayCastError(srcType, destType *sppb.Type, c castContext) error {
 	srcElemType := srcType.GetArrayElementType()
 	destElemType := destType.GetArrayElementType()
 	srcElemCode := sppb.TypeCode_TYPE_CODE_UNSPECIFIED
@@ -1107,8 +1188,8 @@ func unsupportedArrayCastError(srcType, destType *sppb.Type, exprSQL string) err
 		destElemCode = destElemType.GetCode()
 	}
 	err := fmt.Errorf("%w from ARRAY<%v> to ARRAY<%v>", ErrUnsupportedCast, srcElemCode, destElemCode)
-	if exprSQL != "" {
-		return fmt.Errorf("%w: %s", err, exprSQL)
+	if c.exprSQL != "" {
+		return fmt.Errorf("%w: %s", err, c.expr())
 	}
 	return err
 }
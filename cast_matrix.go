@@ -0,0 +1,234 @@
+package memebridge
+
+//go:generate go run ./internal/cmd/castmatrix -o docs/cast-matrix.md
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spantype"
+)
+
+// castRule describes one supported non-identity CAST from a source TypeCode
+// to a destination TypeCode.
+type castRule struct {
+	// fallible reports whether a non-NULL source value can fail the cast at
+	// evaluation time, so that SAFE_CAST yields NULL instead of an error.
+	fallible bool
+}
+
+// castTable is the CAST capability matrix, keyed by destination and then
+// source TypeCode. castGCV rejects pairs missing from this table with
+// ErrUnsupportedCast before dispatching to the castGCVTo* helpers, and
+// CastSupported/SafeCastMayYieldNull answer from the same entries.
+//
+// The helpers still switch on the source TypeCode themselves, so adding or
+// removing a cast means changing both this table and the helper for its
+// destination. TestCastTableMatchesHelpers fails when they drift apart, and
+// TestCastSupportedMatchesCastEvaluation checks the exported API.
+//
+// Identity casts between equivalent types are always supported and are not
+// listed. ARRAY and STRUCT entries only admit the container pair; element and
+// field compatibility is checked separately (see castTypesSupported).
+var castTable = map[sppb.TypeCode]map[sppb.TypeCode]castRule{
+	sppb.TypeCode_BOOL: {
+		sppb.TypeCode_INT64:  {},
+		sppb.TypeCode_STRING: {fallible: true},
+	},
+	sppb.TypeCode_INT64: {
+		sppb.TypeCode_BOOL:    {},
+		sppb.TypeCode_STRING:  {fallible: true},
+		sppb.TypeCode_FLOAT32: {fallible: true},
+		sppb.TypeCode_FLOAT64: {fallible: true},
+		sppb.TypeCode_NUMERIC: {fallible: true},
+	},
+	sppb.TypeCode_FLOAT32: {
+		sppb.TypeCode_INT64:   {},
+		sppb.TypeCode_FLOAT64: {fallible: true},
+		sppb.TypeCode_NUMERIC: {},
+		sppb.TypeCode_STRING:  {fallible: true},
+	},
+	sppb.TypeCode_FLOAT64: {
+		sppb.TypeCode_INT64:   {},
+		sppb.TypeCode_FLOAT32: {},
+		sppb.TypeCode_NUMERIC: {},
+		sppb.TypeCode_STRING:  {fallible: true},
+	},
+	sppb.TypeCode_NUMERIC: {
+		sppb.TypeCode_INT64:   {},
+		sppb.TypeCode_FLOAT32: {fallible: true},
+		sppb.TypeCode_FLOAT64: {fallible: true},
+		sppb.TypeCode_STRING:  {fallible: true},
+	},
+	sppb.TypeCode_STRING: {
+		sppb.TypeCode_BOOL:      {},
+		sppb.TypeCode_INT64:     {},
+		sppb.TypeCode_FLOAT32:   {},
+		sppb.TypeCode_FLOAT64:   {},
+		sppb.TypeCode_NUMERIC:   {},
+		sppb.TypeCode_BYTES:     {fallible: true},
+		sppb.TypeCode_DATE:      {},
+		sppb.TypeCode_TIMESTAMP: {},
+		sppb.TypeCode_UUID:      {},
+		sppb.TypeCode_INTERVAL:  {},
+	},
+	sppb.TypeCode_BYTES: {
+		sppb.TypeCode_STRING: {},
+		sppb.TypeCode_UUID:   {},
+	},
+	sppb.TypeCode_DATE: {
+		sppb.TypeCode_STRING: {fallible: true},
+		// PENDING_COMMIT_TIMESTAMP() has no DATE representation.
+		sppb.TypeCode_TIMESTAMP: {fallible: true},
+	},
+	sppb.TypeCode_TIMESTAMP: {
+		sppb.TypeCode_STRING: {fallible: true},
+		sppb.TypeCode_DATE:   {},
+	},
+	sppb.TypeCode_UUID: {
+		sppb.TypeCode_STRING: {fallible: true},
+		sppb.TypeCode_BYTES:  {fallible: true},
+	},
+	sppb.TypeCode_INTERVAL: {
+		sppb.TypeCode_STRING: {fallible: true},
+	},
+	sppb.TypeCode_ARRAY: {
+		sppb.TypeCode_ARRAY: {},
+	},
+	sppb.TypeCode_STRUCT: {
+		sppb.TypeCode_STRUCT: {},
+	},
+}
+
+// castMatrixTypeCodes lists the TypeCodes shown by WriteCastMatrixMarkdown,
+// in display order.
+var castMatrixTypeCodes = []sppb.TypeCode{
+	sppb.TypeCode_BOOL,
+	sppb.TypeCode_INT64,
+	sppb.TypeCode_FLOAT32,
+	sppb.TypeCode_FLOAT64,
+	sppb.TypeCode_NUMERIC,
+	sppb.TypeCode_STRING,
+	sppb.TypeCode_BYTES,
+	sppb.TypeCode_DATE,
+	sppb.TypeCode_TIMESTAMP,
+	sppb.TypeCode_UUID,
+	sppb.TypeCode_INTERVAL,
+	sppb.TypeCode_JSON,
+	sppb.TypeCode_ARRAY,
+	sppb.TypeCode_STRUCT,
+}
+
+func lookupCastRule(srcCode, destCode sppb.TypeCode) (castRule, bool) {
+	rule, ok := castTable[destCode][srcCode]
+	return rule, ok
+}
+
+// CastSupported reports whether CAST(<from> AS <to>) is supported by
+// memebridge. A supported cast may still fail for particular values; see
+// [SafeCastMayYieldNull].
+//
+// Casts between equivalent types are always supported. ARRAY casts require
+// equivalent element types, and STRUCT casts require the same number of
+// fields with each field pair castable (field names are ignored).
+func CastSupported(from, to *sppb.Type) bool {
+	_, ok := castTypesSupported(from, to)
+	return ok
+}
+
+// SafeCastMayYieldNull reports whether SAFE_CAST(<from> AS <to>) can return
+// NULL for a non-NULL input, that is, whether the cast is supported but can
+// fail for some values of the source type. It returns false for unsupported
+// casts, which are errors even under SAFE_CAST.
+func SafeCastMayYieldNull(from, to *sppb.Type) bool {
+	fallible, ok := castTypesSupported(from, to)
+	return ok && fallible
+}
+
+// castTypesSupported reports whether from can be cast to to, and whether
+// that cast is fallible for non-NULL values.
+func castTypesSupported(from, to *sppb.Type) (fallible bool, ok bool) {
+	if from == nil || to == nil {
+		return false, false
+	}
+	if spantype.EquivalentTypes(from, to) {
+		return false, true
+	}
+	rule, ok := lookupCastRule(from.GetCode(), to.GetCode())
+	if !ok {
+		return false, false
+	}
+	switch to.GetCode() {
+	case sppb.TypeCode_ARRAY:
+		// Non-equivalent ARRAY casts are rejected by castGCVToArray.
+		return false, false
+	case sppb.TypeCode_STRUCT:
+		fromFields := from.GetStructType().GetFields()
+		toFields := to.GetStructType().GetFields()
+		if from.GetStructType() == nil || to.GetStructType() == nil || len(fromFields) != len(toFields) {
+			return false, false
+		}
+		for i := range fromFields {
+			fieldFallible, ok := castTypesSupported(fromFields[i].GetType(), toFields[i].GetType())
+			if !ok {
+				return false, false
+			}
+			fallible = fallible || fieldFallible
+		}
+		return fallible, true
+	default:
+		return rule.fallible, true
+	}
+}
+
+// WriteCastMatrixMarkdown writes the CAST capability matrix as a Markdown
+// table with source types as rows and destination types as columns.
+//
+// Cells are "✓" for casts that always succeed for non-NULL inputs, "✓?" for
+// casts that can fail for some values (SAFE_CAST may yield NULL), "=" for
+// identity-only ARRAY/STRUCT pairs, and empty for unsupported casts.
+func WriteCastMatrixMarkdown(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("| from \\ to |")
+	for _, dest := range castMatrixTypeCodes {
+		fmt.Fprintf(&sb, " %v |", dest)
+	}
+	sb.WriteString("\n|---|")
+	for range castMatrixTypeCodes {
+		sb.WriteString(":---:|")
+	}
+	sb.WriteString("\n")
+	for _, src := range castMatrixTypeCodes {
+		fmt.Fprintf(&sb, "| %v |", src)
+		for _, dest := range castMatrixTypeCodes {
+			fmt.Fprintf(&sb, " %s |", castMatrixCell(src, dest))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+	sb.WriteString("✓: always succeeds for non-NULL input. ")
+	sb.WriteString("✓?: may fail for some values; SAFE_CAST yields NULL. ")
+	sb.WriteString("=: only between equivalent types (STRUCT: same field count, castable fields).\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func castMatrixCell(src, dest sppb.TypeCode) string {
+	switch {
+	case src == dest && (src == sppb.TypeCode_ARRAY || src == sppb.TypeCode_STRUCT):
+		return "="
+	case src == dest:
+		return "✓"
+	}
+	rule, ok := lookupCastRule(src, dest)
+	switch {
+	case !ok:
+		return ""
+	case rule.fallible:
+		return "✓?"
+	default:
+		return "✓"
+	}
+}
@@ -0,0 +1,47 @@
+package memebridge
+
+import (
+	"errors"
+	"testing"
+
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+)
+
+func TestCastTableMatchesHelpers(t *testing.T) {
+	// samples holds a non-NULL value of each scalar type of the matrix.
+	samples := map[sppb.TypeCode]string{
+		sppb.TypeCode_BOOL:      `TRUE`,
+		sppb.TypeCode_INT64:     `1`,
+		sppb.TypeCode_FLOAT32:   `CAST(1 AS FLOAT32)`,
+		sppb.TypeCode_FLOAT64:   `1.5`,
+		sppb.TypeCode_NUMERIC:   `NUMERIC '1'`,
+		sppb.TypeCode_STRING:    `'1'`,
+		sppb.TypeCode_BYTES:     `b'1'`,
+		sppb.TypeCode_DATE:      `DATE '2024-01-01'`,
+		sppb.TypeCode_TIMESTAMP: `TIMESTAMP '2024-01-01T00:00:00Z'`,
+		sppb.TypeCode_UUID:      `CAST('00000000-0000-0000-0000-000000000000' AS UUID)`,
+		sppb.TypeCode_INTERVAL:  `INTERVAL 1 DAY`,
+		sppb.TypeCode_JSON:      `JSON '1'`,
+	}
+	for _, srcCode := range castMatrixTypeCodes {
+		for _, destCode := range castMatrixTypeCodes {
+			if srcCode == destCode || srcCode == sppb.TypeCode_ARRAY || srcCode == sppb.TypeCode_STRUCT ||
+				destCode == sppb.TypeCode_ARRAY || destCode == sppb.TypeCode_STRUCT {
+				continue
+			}
+			literal, ok := samples[srcCode]
+			if !ok {
+				t.Fatalf("no sample value of %v", srcCode)
+			}
+			src, err := ParseExprToGCV(literal)
+			if err != nil {
+				t.Fatalf("%s: %v", literal, err)
+			}
+			_, listed := lookupCastRule(srcCode, destCode)
+			_, err = castGCVUnchecked(src, &sppb.Type{Code: destCode}, castContext{})
+			if handled := !errors.Is(err, ErrUnsupportedCast); handled != listed {
+				t.Errorf("%v -> %v: castTable lists it = %v, but the helper returns %v", srcCode, destCode, listed, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package memebridge_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spantype/typector"
+	"github.com/apstndb/spanvalue/gcvctor"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/apstndb/memebridge"
+)
+
+// castMatrixSamples pairs each scalar type with a non-NULL literal of that type.
+var castMatrixSamples = []struct {
+	typ     *sppb.Type
+	typeSQL string
+	literal string
+}{
+	{typector.Bool(), "BOOL", `TRUE`},
+	{typector.Int64(), "INT64", `1`},
+	{typector.Float32(), "FLOAT32", `CAST(1.5 AS FLOAT32)`},
+	{typector.Float64(), "FLOAT64", `1.5`},
+	{typector.Numeric(), "NUMERIC", `NUMERIC "1"`},
+	{typector.String(), "STRING", `"1"`},
+	{typector.Bytes(), "BYTES", `b"1"`},
+	{typector.Date(), "DATE", `DATE "2024-01-01"`},
+	{typector.Timestamp(), "TIMESTAMP", `TIMESTAMP "2024-01-01T00:00:00Z"`},
+	{typector.UUID(), "UUID", `CAST("94a01a73-d90a-432d-a03f-5db58ea8058f" AS UUID)`},
+	{typector.Interval(), "INTERVAL", `INTERVAL 1 DAY`},
+	{typector.JSON(), "JSON", `JSON '{"a":1}'`},
+}
+
+func TestCastSupportedMatchesCastEvaluation(t *testing.T) {
+	for _, from := range castMatrixSamples {
+		for _, to := range castMatrixSamples {
+			t.Run(from.typeSQL+"->"+to.typeSQL, func(t *testing.T) {
+				supported := memebridge.CastSupported(from.typ, to.typ)
+				_, err := memebridge.ParseExprToGCV(fmt.Sprintf("SAFE_CAST(%s AS %s)", from.literal, to.typeSQL))
+				if gotUnsupported := errors.Is(err, memebridge.ErrUnsupportedCast); gotUnsupported == supported {
+					t.Errorf("CastSupported = %v, but SAFE_CAST error = %v", supported, err)
+				}
+				if supported && err != nil {
+					t.Errorf("SAFE_CAST of supported pair returned error: %v", err)
+				}
+			})
+		}
+	}
+}
+
+func TestSafeCastMayYieldNull(t *testing.T) {
+	tests := []struct {
+		from, to *sppb.Type
+		want     bool
+	}{
+		{typector.String(), typector.Int64(), true},
+		{typector.Int64(), typector.String(), false},
+		{typector.Int64(), typector.Int64(), false},
+		{typector.Float64(), typector.Int64(), true},
+		{typector.Bytes(), typector.String(), true},
+		{typector.JSON(), typector.String(), false},
+		{typector.NameCodeToStructType("a", sppb.TypeCode_INT64), typector.NameCodeToStructType("b", sppb.TypeCode_STRING), false},
+		{typector.NameCodeToStructType("a", sppb.TypeCode_STRING), typector.NameCodeToStructType("b", sppb.TypeCode_DATE), true},
+	}
+	for _, tt := range tests {
+		if got := memebridge.SafeCastMayYieldNull(tt.from, tt.to); got != tt.want {
+			t.Errorf("SafeCastMayYieldNull(%v, %v) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestCastSupportedContainers(t *testing.T) {
+	int64Pair := &sppb.Type{Code: sppb.TypeCode_STRUCT, StructType: &sppb.StructType{Fields: []*sppb.StructType_Field{
+		{Name: "a", Type: typector.Int64()},
+		{Name: "b", Type: typector.Int64()},
+	}}}
+	// literal is a value of from, for checking that CastGCV agrees.
+	tests := []struct {
+		from, to *sppb.Type
+		literal  string
+		want     bool
+	}{
+		{typector.ElemCodeToArrayType(sppb.TypeCode_INT64), typector.ElemCodeToArrayType(sppb.TypeCode_INT64), `[1]`, true},
+		{typector.ElemCodeToArrayType(sppb.TypeCode_INT64), typector.ElemCodeToArrayType(sppb.TypeCode_FLOAT64), `[1]`, false},
+		{typector.NameCodeToStructType("a", sppb.TypeCode_INT64), typector.NameCodeToStructType("b", sppb.TypeCode_FLOAT64), `STRUCT(1 AS a)`, true},
+		{typector.NameCodeToStructType("a", sppb.TypeCode_INT64), typector.NameCodeToStructType("b", sppb.TypeCode_DATE), `STRUCT(1 AS a)`, false},
+		{typector.NameCodeToStructType("a", sppb.TypeCode_INT64), int64Pair, `STRUCT(1 AS a)`, false},
+		{typector.NameCodeToStructType("a", sppb.TypeCode_INT64), typector.Int64(), `STRUCT(1 AS a)`, false},
+		{nil, typector.Int64(), "", false},
+	}
+	for _, tt := range tests {
+		if got := memebridge.CastSupported(tt.from, tt.to); got != tt.want {
+			t.Errorf("CastSupported(%v, %v) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+		if tt.literal == "" {
+			continue
+		}
+		_, err := memebridge.CastGCV(must(memebridge.ParseExprToGCV(tt.literal)), tt.to)
+		if unsupported := errors.Is(err, memebridge.ErrUnsupportedCast); unsupported == tt.want {
+			t.Errorf("CastGCV(%s, %v) error = %v, want ErrUnsupportedCast: %v", tt.literal, tt.to, err, !tt.want)
+		}
+	}
+}
+
+func TestWriteCastMatrixMarkdown(t *testing.T) {
+	var sb strings.Builder
+	if err := memebridge.WriteCastMatrixMarkdown(&sb); err != nil {
+		t.Fatal(err)
+	}
+	got := sb.String()
+	for _, want := range []string{
+		"| from \\ to | BOOL | INT64 |",
+		"| STRING | ✓? | ✓? |",
+		"| JSON |",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("WriteCastMatrixMarkdown output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestCastGCV(t *testing.T) {
+	got, err := memebridge.CastGCV(gcvctor.StringValue("2024-01-01"), typector.Date())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := must(memebridge.ParseExprToGCV(`CAST("2024-01-01" AS DATE)`))
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("CastGCV mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := memebridge.CastGCV(gcvctor.BoolValue(true), typector.Date()); !errors.Is(err, memebridge.ErrUnsupportedCast) {
+		t.Errorf("CastGCV(BOOL → DATE) error = %v, want ErrUnsupportedCast", err)
+	}
+	if _, err := memebridge.CastGCV(gcvctor.StringValue("x"), typector.Int64()); err == nil {
+		t.Error("CastGCV(\"x\" → INT64) succeeded, want error")
+	}
+	if _, err := memebridge.CastGCV(gcvctor.StringValue("hunter2"), typector.Int64(), memebridge.WithRedactedValues()); err == nil || strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("CastGCV with WithRedactedValues error = %v, want an error without the value", err)
+	}
+}
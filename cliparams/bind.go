@@ -0,0 +1,134 @@
+package cliparams
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"cloud.google.com/go/spanner"
+	"github.com/cloudspannerecosystem/memefish"
+	"github.com/cloudspannerecosystem/memefish/ast"
+	"github.com/cloudspannerecosystem/memefish/token"
+)
+
+// Diagnostics reports mismatches between supplied parameters and the
+// parameters a statement references. Neither list is an error by itself:
+// Spanner rejects statements with unknown parameters, while unused
+// parameters are silently dropped from the bound statement.
+type Diagnostics struct {
+	// Unknown lists parameters referenced by the SQL but not supplied, sorted.
+	Unknown []string
+	// Unused lists supplied parameters that the SQL does not reference, sorted.
+	Unused []string
+}
+
+// ReferencedParams returns the sorted, de-duplicated names of the query
+// parameters (@name) referenced anywhere in nodes.
+func ReferencedParams[T ast.Node](nodes ...T) []string {
+	seen := make(map[string]bool)
+	ast.InspectMany(nodes, func(n ast.Node) bool {
+		if p, ok := n.(*ast.Param); ok {
+			seen[p.Name] = true
+		}
+		return true
+	})
+	return slices.Sorted(maps.Keys(seen))
+}
+
+// Bind parses sql as a single statement (query, DML or DDL) and returns a
+// [spanner.Statement] whose Params contain only the referenced entries of
+// params, together with diagnostics for unknown and unused parameters.
+func Bind(sql string, params map[string]spanner.GenericColumnValue) (spanner.Statement, Diagnostics, error) {
+	stmt, err := memefish.ParseStatement("", sql)
+	if err != nil {
+		return spanner.Statement{}, Diagnostics{}, fmt.Errorf("cliparams: parsing statement: %w", err)
+	}
+	bound, diags := bindStatements([]string{sql}, []ast.Statement{stmt}, params)
+	return bound[0], diags, nil
+}
+
+// BindStatement is like [Bind] for an already-parsed memefish statement. The
+// returned SQL is stmt.SQL(), memefish's canonical rendering of the statement.
+func BindStatement(stmt ast.Statement, params map[string]spanner.GenericColumnValue) (spanner.Statement, Diagnostics) {
+	bound, diags := bindStatements([]string{stmt.SQL()}, []ast.Statement{stmt}, params)
+	return bound[0], diags
+}
+
+// BindScript splits a multi-statement script at terminating semicolons and
+// binds each statement like [Bind]. Statements consisting only of comments
+// are skipped, and each returned SQL is the original statement text.
+// Diagnostics cover the whole script: a parameter is unused only if no
+// statement references it. filename is used only in error positions.
+func BindScript(filename, sql string, params map[string]spanner.GenericColumnValue) ([]spanner.Statement, Diagnostics, error) {
+	raws, err := memefish.SplitRawStatements(filename, sql)
+	if err != nil {
+		return nil, Diagnostics{}, fmt.Errorf("cliparams: splitting script: %w", err)
+	}
+
+	var (
+		texts []string
+		stmts []ast.Statement
+	)
+	for _, raw := range raws {
+		if isBlankStatement(filename, raw.Statement) {
+			continue
+		}
+		// Parse the statement within the full script so that error positions
+		// refer to script lines.
+		padded := blankOut(sql[:raw.Pos]) + raw.Statement
+		stmt, err := memefish.ParseStatement(filename, padded)
+		if err != nil {
+			return nil, Diagnostics{}, fmt.Errorf("cliparams: parsing statement: %w", err)
+		}
+		texts = append(texts, strings.TrimSpace(raw.Statement))
+		stmts = append(stmts, stmt)
+	}
+	bound, diags := bindStatements(texts, stmts, params)
+	return bound, diags, nil
+}
+
+// blankOut replaces everything but newlines in s with spaces, preserving
+// line and byte offsets.
+func blankOut(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c != '\n' {
+			b[i] = ' '
+		}
+	}
+	return string(b)
+}
+
+func isBlankStatement(filename, stmt string) bool {
+	lex := &memefish.Lexer{File: &token.File{FilePath: filename, Buffer: stmt}}
+	if err := lex.NextToken(); err != nil {
+		return false
+	}
+	return lex.Token.Kind == token.TokenEOF
+}
+
+func bindStatements(texts []string, stmts []ast.Statement, params map[string]spanner.GenericColumnValue) ([]spanner.Statement, Diagnostics) {
+	var diags Diagnostics
+	used := make(map[string]bool)
+	bound := make([]spanner.Statement, len(stmts))
+	for i, stmt := range stmts {
+		filtered := make(map[string]spanner.GenericColumnValue)
+		for _, name := range ReferencedParams(stmt) {
+			used[name] = true
+			if gcv, ok := params[name]; ok {
+				filtered[name] = gcv
+			} else if !slices.Contains(diags.Unknown, name) {
+				diags.Unknown = append(diags.Unknown, name)
+			}
+		}
+		bound[i] = spanner.Statement{SQL: texts[i], Params: StatementParams(filtered)}
+	}
+	for _, name := range slices.Sorted(maps.Keys(params)) {
+		if !used[name] {
+			diags.Unused = append(diags.Unused, name)
+		}
+	}
+	slices.Sort(diags.Unknown)
+	return bound, diags
+}
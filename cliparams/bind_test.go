@@ -0,0 +1,105 @@
+package cliparams_test
+
+import (
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/spanner"
+	"github.com/apstndb/spanvalue/gcvctor"
+	"github.com/cloudspannerecosystem/memefish"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/apstndb/memebridge/cliparams"
+)
+
+func TestBind(t *testing.T) {
+	params := map[string]spanner.GenericColumnValue{
+		"id":    gcvctor.Int64Value(1),
+		"extra": gcvctor.StringValue("x"),
+	}
+	for _, tt := range []struct {
+		sql         string
+		wantParams  []string
+		wantUnknown []string
+	}{
+		{sql: "SELECT * FROM Singers WHERE SingerId = @id AND Name = @name", wantParams: []string{"id"}, wantUnknown: []string{"name"}},
+		{sql: "UPDATE Singers SET Name = 'a' WHERE SingerId = @id", wantParams: []string{"id"}},
+		{sql: "INSERT INTO Singers (SingerId) VALUES (@id), (@id)", wantParams: []string{"id"}},
+		{sql: "CREATE TABLE T (Id INT64) PRIMARY KEY (Id)"},
+	} {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, diags, err := cliparams.Bind(tt.sql, params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stmt.SQL != tt.sql {
+				t.Errorf("SQL = %q, want %q", stmt.SQL, tt.sql)
+			}
+			var gotParams []string
+			for name := range stmt.Params {
+				gotParams = append(gotParams, name)
+			}
+			if diff := cmp.Diff(tt.wantParams, gotParams, cmpopts.EquateEmpty(), cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("Params mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantUnknown, diags.Unknown, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Unknown mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, _, err := cliparams.Bind("SELECT FROM", params); err == nil {
+		t.Error("want parse error, got nil")
+	}
+}
+
+func TestBindStatement(t *testing.T) {
+	stmt, err := memefish.ParseStatement("", "SELECT @a, @b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bound, diags := cliparams.BindStatement(stmt, map[string]spanner.GenericColumnValue{"a": gcvctor.Int64Value(1), "c": gcvctor.Int64Value(3)})
+	if len(bound.Params) != 1 || bound.Params["a"] == nil {
+		t.Errorf("Params = %v, want only a", bound.Params)
+	}
+	want := cliparams.Diagnostics{Unknown: []string{"b"}, Unused: []string{"c"}}
+	if diff := cmp.Diff(want, diags); diff != "" {
+		t.Errorf("Diagnostics mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBindScript(t *testing.T) {
+	script := `-- setup
+INSERT INTO T (Id) VALUES (@id);
+SELECT @id, @other;
+/* trailing comment only */`
+	params := map[string]spanner.GenericColumnValue{
+		"id":     gcvctor.Int64Value(1),
+		"other":  gcvctor.Int64Value(2),
+		"unused": gcvctor.Int64Value(3),
+	}
+	stmts, diags, err := cliparams.BindScript("script.sql", script, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stmts) != 2 {
+		t.Fatalf("got %d statements, want 2: %v", len(stmts), stmts)
+	}
+	if stmts[0].SQL != "-- setup\nINSERT INTO T (Id) VALUES (@id)" || len(stmts[0].Params) != 1 {
+		t.Errorf("stmts[0] = %+v", stmts[0])
+	}
+	if stmts[1].SQL != "SELECT @id, @other" || len(stmts[1].Params) != 2 {
+		t.Errorf("stmts[1] = %+v", stmts[1])
+	}
+	if diff := cmp.Diff(cliparams.Diagnostics{Unused: []string{"unused"}}, diags); diff != "" {
+		t.Errorf("Diagnostics mismatch (-want +got):\n%s", diff)
+	}
+
+	t.Run("error position", func(t *testing.T) {
+		_, _, err := cliparams.BindScript("script.sql", "SELECT 1;\n\nSELECT FROM;", nil)
+		if err == nil || !strings.Contains(err.Error(), "script.sql:3:") {
+			t.Errorf("want error at script.sql:3, got %v", err)
+		}
+	})
+}
@@ -8,22 +8,51 @@
 // literal, and — when [WithBareTypeAsNull] is enabled — a value that parses
 // as a bare type (for example "ARRAY<STRING>") yields a typed NULL
 // parameter instead, which is how PLAN-mode tools declare parameter types
-// without values.
+// without values. [WithRawValues] adds a shell-friendly "TYPE=raw" syntax
+// (for example "d:DATE=2024-01-01") that avoids nested quoting, and
+// [WithBarewordAsString] takes unquoted text as a STRING. Values too large
+// for a command line can be read from files or standard input ("@file:",
+// "@bytes:", "@json:", "@-") through [WithFS] and [WithStdin].
+//
+// [FormatParams] prints a parameter map as a table, or as assignments, a
+// SET PARAM script or JSON that parse back to the same parameters.
+//
+// Parameters holding credentials or personal data can be marked sensitive
+// with [WithSensitiveNames] or [WithSensitivePattern]; their values are then
+// redacted from errors, [FormatParams] output and [Flag.String].
+//
+// [WithExpectedTypes] coerces values to the types of the columns they are
+// compared with or written to, and [WithColumnSpecs] also checks them
+// against column limits such as STRING(n) and NOT NULL.
+//
+// CLIs can register a [Flag] with the standard flag package or pflag to
+// collect repeated --param flags, validating each occurrence as it is set.
+//
+// Parameter sets can also be loaded from files with [LoadFile] (or the
+// [ReadJSON], [ReadParamsFile] and [ReadSetParamScript] readers), which is
+// the shared implementation behind --params-file style flags.
 //
 // Callers that only bind parameters referenced by the SQL (for example
-// spanner-mycli in NORMAL mode) should filter the input map to used names
-// before calling [ParseMap]. With [WithBareTypeAsNull] enabled, bare-type
-// values are parsed eagerly; filtering avoids producing typed NULL params
-// for names that the statement does not reference.
+// spanner-mycli in NORMAL mode) can use [Bind], [BindStatement] or
+// [BindScript], which walk the statement for @name references, drop unused
+// parameters and report unknown ones. With [WithBareTypeAsNull] enabled,
+// bare-type values are parsed eagerly; to avoid even parsing unreferenced
+// values, filter the input map with [ReferencedParams] before [ParseMap].
 package cliparams
 
 import (
 	"fmt"
+	"io/fs"
+	"maps"
+	"regexp"
+	"slices"
 	"strings"
 
 	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
 	"github.com/apstndb/spanvalue/gcvctor"
 	"github.com/cloudspannerecosystem/memefish"
+	"github.com/cloudspannerecosystem/memefish/ast"
 
 	"github.com/apstndb/memebridge"
 )
@@ -32,12 +61,26 @@ import (
 type Option func(*config)
 
 type config struct {
-	separator      string
-	bareTypeAsNull bool
+	separator        string
+	bareTypeAsNull   bool
+	allErrors        bool
+	rawValues        bool
+	barewordAsString bool
+	expectedTypes    map[string]*sppb.Type
+	columnSpecs      map[string]memebridge.ColumnSpec
+	expectedType     *sppb.Type // set per parameter by parseParam
+	fsys             fs.FS
+	maxArrayElements int
+	stdin            *stdinSource
+	evalOpts         []memebridge.EvalOption
+
+	sensitiveNames    map[string]bool
+	sensitivePatterns []*regexp.Regexp
+	redact            bool // set per parameter by parseParam
 }
 
 func newConfig(opts []Option) config {
-	cfg := config{separator: ":"}
+	cfg := config{separator: ":", maxArrayElements: defaultMaxArrayElements}
 	for _, opt := range opts {
 		if opt != nil {
 			opt(&cfg)
@@ -64,6 +107,56 @@ func WithBareTypeAsNull() Option {
 	return func(cfg *config) { cfg.bareTypeAsNull = true }
 }
 
+// WithAllErrors makes [ParseAssignments] and [ParseMap] validate every
+// parameter instead of stopping at the first failure. All failures are
+// returned together as an [errors.Join] of errors sorted by parameter name
+// (and then by input position); per-parameter failures are [*ParamError]
+// values. Use [errors.As] or the Unwrap() []error method to inspect them.
+func WithAllErrors() Option {
+	return func(cfg *config) { cfg.allErrors = true }
+}
+
+// WithExpectedTypes coerces parameter values to known types by name, as
+// inferred by [memebridge.Schema.InferParamTypes] or declared by the caller.
+// A value for a parameter with an expected type is evaluated with
+// [memebridge.WithExpectedType], so that "d:'2024-01-01'" yields a DATE when
+// types["d"] is DATE. Parameters without an entry, bare-type typed NULLs
+// ([WithBareTypeAsNull], SET PARAM name TYPE) and typed JSON objects keep
+// their declared types. [ParseValue] ignores this option since it has no
+// parameter name.
+func WithExpectedTypes(types map[string]*sppb.Type) Option {
+	return func(cfg *config) { cfg.expectedTypes = types }
+}
+
+// WithColumnSpecs checks parameter values by name against column specs,
+// such as those of the columns a DML statement writes (see
+// [memebridge.Column.Spec]), with [memebridge.ValidateColumnValue]. A
+// value that violates its spec, such as a string longer than n for a
+// STRING(n) column, is an error wrapping the
+// [*memebridge.ConstraintViolation] values. A parameter with a spec and no
+// expected type from [WithExpectedTypes] is coerced to the spec type.
+func WithColumnSpecs(specs map[string]memebridge.ColumnSpec) Option {
+	return func(cfg *config) { cfg.columnSpecs = specs }
+}
+
+// WithEvalOptions passes opts to every memebridge evaluation and cast, for
+// example [memebridge.WithTimeZone] or
+// [memebridge.WithLegacyArrayWirePassthrough]. Per-parameter settings such as
+// the expected type of [WithExpectedTypes] take precedence.
+func WithEvalOptions(opts ...memebridge.EvalOption) Option {
+	return func(cfg *config) { cfg.evalOpts = append(cfg.evalOpts, opts...) }
+}
+
+// evalOptions returns the memebridge options for one evaluation: those of
+// WithEvalOptions, then extra, then redaction for a sensitive parameter.
+func (cfg config) evalOptions(extra ...memebridge.EvalOption) []memebridge.EvalOption {
+	opts := append(slices.Clip(cfg.evalOpts), extra...)
+	if cfg.redact {
+		opts = append(opts, memebridge.WithRedactedValues())
+	}
+	return opts
+}
+
 // SplitAssignment splits one "name<separator>value" argument. The name must
 // be non-empty; the value may contain further separator occurrences.
 func SplitAssignment(arg string, opts ...Option) (name, value string, err error) {
@@ -83,66 +176,171 @@ func SplitAssignment(arg string, opts ...Option) (name, value string, err error)
 
 // ParseValue converts one parameter value string into a
 // [spanner.GenericColumnValue]: a GoogleSQL expression literal, or — with
-// [WithBareTypeAsNull] — a bare type yielding a typed NULL.
+// [WithBareTypeAsNull] — a bare type yielding a typed NULL. [WithRawValues]
+// and [WithBarewordAsString] enable the shell-friendly forms, and [WithFS]
+// and [WithStdin] enable "@file:"-style external sources.
 func ParseValue(value string, opts ...Option) (spanner.GenericColumnValue, error) {
-	cfg := newConfig(opts)
+	return parseValue(value, newConfig(opts))
+}
+
+func parseValue(value string, cfg config) (spanner.GenericColumnValue, error) {
+	if src, ok := parseSource(value); ok {
+		return src.read(cfg)
+	}
 	if cfg.bareTypeAsNull {
-		if typ, err := memefish.ParseType("", value); err == nil {
-			t, err := memebridge.MemefishTypeToSpannerpbType(typ)
-			if err != nil {
-				return spanner.GenericColumnValue{}, fmt.Errorf("cliparams: generating typed NULL for %q: %w", value, err)
-			}
-			return gcvctor.NullOf(t), nil
+		if typ, err := parseType(value); err == nil {
+			return typedNull(typ, value, cfg)
 		}
 		// Not a type; fall through to expression parsing.
 	}
-	expr, err := memefish.ParseExpr("", value)
+	if cfg.rawValues {
+		if gcv, ok, err := parseRawValue(value, cfg); ok {
+			return gcv, err
+		}
+	}
+	expr, err := parseExpr(value)
+	if cfg.barewordAsString && (err != nil || isBareword(expr)) {
+		return barewordToGCV(value, cfg)
+	}
 	if err != nil {
-		return spanner.GenericColumnValue{}, fmt.Errorf("cliparams: parsing expression %q: %w", value, err)
+		return spanner.GenericColumnValue{}, fmt.Errorf("cliparams: parsing expression %s: %w", cfg.quote(value), cfg.cause(err))
 	}
-	gcv, err := memebridge.MemefishExprToGCV(expr)
+	gcv, err := memebridge.MemefishExprToGCV(expr, cfg.evalOptions(memebridge.WithExpectedType(cfg.expectedType))...)
 	if err != nil {
-		return spanner.GenericColumnValue{}, fmt.Errorf("cliparams: generating value for %q: %w", value, err)
+		return spanner.GenericColumnValue{}, fmt.Errorf("cliparams: generating value for %s: %w", cfg.quote(value), err)
 	}
 	return gcv, nil
 }
 
+// parseParam is [ParseValue] for a named parameter, applying the expected
+// type registered with [WithExpectedTypes], the redaction of
+// [WithSensitiveNames] and the checks of [WithColumnSpecs].
+func parseParam(name, value string, opts []Option) (spanner.GenericColumnValue, error) {
+	return parseParamWith(name, value, opts, parseValue)
+}
+
+// parseParamWith is parseParam with value parsed by parse, for parameter
+// values in other forms such as the typed objects of [ReadJSON].
+func parseParamWith(name, value string, opts []Option, parse func(string, config) (spanner.GenericColumnValue, error)) (spanner.GenericColumnValue, error) {
+	cfg := newConfig(opts)
+	spec, hasSpec := cfg.columnSpecs[name]
+	cfg.expectedType = cfg.expectedTypes[name]
+	if cfg.expectedType == nil && hasSpec {
+		cfg.expectedType = spec.Type
+	}
+	cfg.redact = cfg.isSensitive(name)
+	gcv, err := parse(value, cfg)
+	if err != nil || !hasSpec {
+		return gcv, err
+	}
+	if err := memebridge.ValidateColumnValue(gcv, spec); err != nil {
+		return spanner.GenericColumnValue{}, fmt.Errorf("cliparams: value %s violates its column spec: %w", cfg.quote(value), err)
+	}
+	return gcv, nil
+}
+
+// parseType is memefish.ParseType with lexer panics turned into errors; see
+// recoverParse.
+func parseType(s string) (ast.Type, error) {
+	return recoverParse(memefish.ParseType, s)
+}
+
+// parseExpr is memefish.ParseExpr with lexer panics turned into errors.
+func parseExpr(s string) (ast.Expr, error) {
+	return recoverParse(memefish.ParseExpr, s)
+}
+
+// recoverParse calls a memefish parse function, reporting lexer failures on
+// the first token (such as an unterminated string literal), which memefish
+// raises as a *memefish.Error panic, as errors.
+func recoverParse[T any](parse func(filename, s string) (T, error), s string) (node T, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			merr, ok := r.(*memefish.Error)
+			if !ok {
+				panic(r)
+			}
+			err = merr
+		}
+	}()
+	return parse("", s)
+}
+
+func typedNull(typ ast.Type, value string, cfg config) (spanner.GenericColumnValue, error) {
+	t, err := memebridge.MemefishTypeToSpannerpbType(typ)
+	if err != nil {
+		return spanner.GenericColumnValue{}, fmt.Errorf("cliparams: generating typed NULL for %s: %w", cfg.quote(value), cfg.cause(err))
+	}
+	return gcvctor.NullOf(t), nil
+}
+
 // ParseAssignments parses raw "name<separator>value" arguments into a
-// parameter map. Duplicate names are an error.
+// parameter map. Duplicate names are an error. By default the first failure
+// is returned; with [WithAllErrors] every argument is validated.
 func ParseAssignments(args []string, opts ...Option) (map[string]spanner.GenericColumnValue, error) {
+	cfg := newConfig(opts)
+	errs := errorCollector{all: cfg.allErrors}
 	params := make(map[string]spanner.GenericColumnValue, len(args))
-	for _, arg := range args {
+	for i, arg := range args {
 		name, value, err := SplitAssignment(arg, opts...)
 		if err != nil {
-			return nil, err
+			if errs.add("", i, err) {
+				break
+			}
+			continue
 		}
 		if _, ok := params[name]; ok {
-			return nil, fmt.Errorf("cliparams: duplicate parameter name %q", name)
+			if errs.add(name, i, cfg.paramError(name, value, ErrDuplicateParameter)) {
+				break
+			}
+			continue
 		}
-		gcv, err := ParseValue(value, opts...)
+		gcv, err := parseParam(name, value, opts)
 		if err != nil {
-			return nil, fmt.Errorf("cliparams: parameter %q: %w", name, err)
+			// Reserve the name so that later duplicates are still reported.
+			params[name] = spanner.GenericColumnValue{}
+			if errs.add(name, i, cfg.paramError(name, value, err)) {
+				break
+			}
+			continue
 		}
 		params[name] = gcv
 	}
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
 	return params, nil
 }
 
 // ParseMap converts an already-split name→value map (the shape produced by
 // flag libraries with map values) into a parameter map. The separator
-// option is irrelevant here.
+// option is irrelevant here. Names are processed in sorted order, so the
+// reported error is deterministic; with [WithAllErrors] every entry is
+// validated.
 func ParseMap(values map[string]string, opts ...Option) (map[string]spanner.GenericColumnValue, error) {
+	cfg := newConfig(opts)
+	errs := errorCollector{all: cfg.allErrors}
 	params := make(map[string]spanner.GenericColumnValue, len(values))
-	for name, value := range values {
+	for i, name := range slices.Sorted(maps.Keys(values)) {
+		value := values[name]
 		if name == "" {
-			return nil, fmt.Errorf("cliparams: empty parameter name")
+			if errs.add(name, i, fmt.Errorf("cliparams: empty parameter name")) {
+				break
+			}
+			continue
 		}
-		gcv, err := ParseValue(value, opts...)
+		gcv, err := parseParam(name, value, opts)
 		if err != nil {
-			return nil, fmt.Errorf("cliparams: parameter %q: %w", name, err)
+			if errs.add(name, i, cfg.paramError(name, value, err)) {
+				break
+			}
+			continue
 		}
 		params[name] = gcv
 	}
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
 	return params, nil
 }
 
@@ -1,16 +1,20 @@
 package cliparams_test
 
 import (
+	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"cloud.google.com/go/spanner"
 	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
 	"github.com/apstndb/spantype/typector"
+	"github.com/cloudspannerecosystem/memefish"
 	"github.com/google/go-cmp/cmp"
 	"google.golang.org/protobuf/testing/protocmp"
 	"google.golang.org/protobuf/types/known/structpb"
 
+	"github.com/apstndb/memebridge"
 	"github.com/apstndb/memebridge/cliparams"
 )
 
@@ -169,3 +173,140 @@ func TestStatementParams(t *testing.T) {
 		t.Errorf("StatementParams(nil) = %v, want nil", got)
 	}
 }
+
+func TestParseAssignmentsAllErrors(t *testing.T) {
+	_, err := cliparams.ParseAssignments(
+		[]string{`z:(`, `a:1`, `m:CAST("x" AS INT64)`, `a:2`, `noseparator`},
+		cliparams.WithAllErrors(),
+	)
+	if err == nil {
+		t.Fatal("want error, got nil")
+	}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("want joined error, got %T: %v", err, err)
+	}
+	var names []string
+	for _, e := range joined.Unwrap() {
+		var pe *cliparams.ParamError
+		if !errors.As(e, &pe) {
+			names = append(names, "")
+			continue
+		}
+		names = append(names, pe.Name)
+	}
+	if diff := cmp.Diff([]string{"", "a", "m", "z"}, names); diff != "" {
+		t.Errorf("error order mismatch (-want +got):\n%s", diff)
+	}
+
+	var dup *cliparams.ParamError
+	if !errors.As(joined.Unwrap()[1], &dup) || !errors.Is(dup, cliparams.ErrDuplicateParameter) || dup.Value != "2" {
+		t.Errorf("want duplicate ParamError for a with value 2, got %v", joined.Unwrap()[1])
+	}
+	var bad *cliparams.ParamError
+	if !errors.As(joined.Unwrap()[2], &bad) || bad.Value != `CAST("x" AS INT64)` {
+		t.Errorf("want ParamError carrying raw value, got %v", joined.Unwrap()[2])
+	}
+}
+
+func TestParseMapAllErrorsDeterministic(t *testing.T) {
+	values := map[string]string{"b": "(", "a": "(", "c": "1", "d": "["}
+	want := ""
+	for range 10 {
+		_, err := cliparams.ParseMap(values, cliparams.WithAllErrors())
+		if err == nil {
+			t.Fatal("want error, got nil")
+		}
+		if want == "" {
+			want = err.Error()
+			continue
+		}
+		if err.Error() != want {
+			t.Fatalf("nondeterministic error:\n%s\nvs\n%s", err, want)
+		}
+	}
+	if strings.Index(want, `"a"`) > strings.Index(want, `"b"`) || strings.Index(want, `"b"`) > strings.Index(want, `"d"`) {
+		t.Errorf("errors not sorted by name:\n%s", want)
+	}
+
+	_, err := cliparams.ParseMap(values)
+	var pe *cliparams.ParamError
+	if !errors.As(err, &pe) || pe.Name != "a" {
+		t.Errorf("default mode: want first sorted parameter a, got %v", err)
+	}
+}
+
+func TestWithExpectedTypes(t *testing.T) {
+	schema, err := memebridge.ParseSchema("", `CREATE TABLE Orders (Id INT64, created_date DATE, Amount NUMERIC) PRIMARY KEY (Id)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt, err := memefish.ParseStatement("", "SELECT * FROM Orders WHERE created_date = @d AND Amount > @amt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	types, err := schema.InferParamTypes(stmt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := cliparams.ParseAssignments(
+		[]string{`d:'2024-01-01'`, `amt:1`, `other:'x'`, `typed:ARRAY<INT64>`},
+		cliparams.WithExpectedTypes(types), cliparams.WithBareTypeAsNull(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, code := range map[string]sppb.TypeCode{
+		"d":     sppb.TypeCode_DATE,
+		"amt":   sppb.TypeCode_NUMERIC,
+		"other": sppb.TypeCode_STRING,
+		"typed": sppb.TypeCode_ARRAY,
+	} {
+		if got[name].Type.GetCode() != code {
+			t.Errorf("param %s type = %v, want %v", name, got[name].Type.GetCode(), code)
+		}
+	}
+
+	t.Run("incompatible value", func(t *testing.T) {
+		_, err := cliparams.ParseMap(map[string]string{"d": "'not a date'"}, cliparams.WithExpectedTypes(types))
+		var perr *cliparams.ParamError
+		if !errors.As(err, &perr) || perr.Name != "d" {
+			t.Errorf("want ParamError for d, got %v", err)
+		}
+	})
+}
+
+func TestWithColumnSpecs(t *testing.T) {
+	schema, err := memebridge.ParseSchema("", `CREATE TABLE Users (Id INT64, Code STRING(3), Tags ARRAY<STRING(4)>) PRIMARY KEY (Id)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users, _ := schema.Table("Users")
+	specs := map[string]memebridge.ColumnSpec{}
+	for _, col := range users.Columns {
+		specs[strings.ToLower(col.Name)] = col.Spec()
+	}
+
+	if _, err := cliparams.ParseAssignments([]string{`code:'abc'`, `tags:['a']`}, cliparams.WithColumnSpecs(specs)); err != nil {
+		t.Fatal(err)
+	}
+	_, err = cliparams.ParseAssignments([]string{`tags:['a', 'toolong']`}, cliparams.WithColumnSpecs(specs))
+	var perr *cliparams.ParamError
+	var violation *memebridge.ConstraintViolation
+	if !errors.As(err, &perr) || perr.Name != "tags" || !errors.As(err, &violation) || violation.Element != 2 {
+		t.Errorf("want ParamError for tags with a violation by element 2, got %v", err)
+	}
+}
+
+func TestWithEvalOptions(t *testing.T) {
+	value := `CAST(TIMESTAMP "2024-01-01T00:00:00Z" AS DATE)`
+	got, err := cliparams.ParseValue(value, cliparams.WithEvalOptions(memebridge.WithTimeZone(time.UTC)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := gcvOf(typector.Date(), structpb.NewStringValue("2024-01-01"))
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
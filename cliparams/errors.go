@@ -0,0 +1,83 @@
+package cliparams
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrDuplicateParameter is wrapped by a [*ParamError] when the same parameter
+// name is assigned more than once.
+var ErrDuplicateParameter = errors.New("duplicate parameter name")
+
+// ErrRedactedValue is wrapped by a [*ParamError] when [ReadJSON] reads the
+// placeholder that [FormatParams] writes for a sensitive parameter, so that
+// redacted output is not mistaken for the real value.
+var ErrRedactedValue = errors.New("value is redacted")
+
+// ParamError reports a failure to parse one parameter. Err is the underlying
+// error, typically from memefish parsing or memebridge evaluation, and is
+// available through errors.Is / errors.As.
+type ParamError struct {
+	// Name is the parameter name.
+	Name string
+	// Value is the raw value string as given by the caller, or
+	// memebridge.RedactedPlaceholder for a sensitive parameter (see
+	// [WithSensitiveNames]).
+	Value string
+	// Err is the underlying error.
+	Err error
+	// File and Line locate the parameter when it was loaded from a file (see
+	// [LoadFile]). Line is 1-origin; both are zero for command-line input.
+	File string
+	Line int
+}
+
+func (e *ParamError) Error() string {
+	if e.File != "" || e.Line > 0 {
+		return fmt.Sprintf("cliparams: %s:%d: parameter %q: %v", e.File, e.Line, e.Name, e.Err)
+	}
+	return fmt.Sprintf("cliparams: parameter %q: %v", e.Name, e.Err)
+}
+
+func (e *ParamError) Unwrap() error {
+	return e.Err
+}
+
+// errorCollector accumulates parse errors. Without [WithAllErrors] it keeps
+// only the first error; with it, errors are reported sorted by parameter
+// name and then by input position so that the result is deterministic.
+type errorCollector struct {
+	all     bool
+	entries []collectedError
+}
+
+type collectedError struct {
+	name  string
+	index int
+	err   error
+}
+
+// add records err and reports whether parsing should stop.
+func (c *errorCollector) add(name string, index int, err error) bool {
+	c.entries = append(c.entries, collectedError{name: name, index: index, err: err})
+	return !c.all
+}
+
+func (c *errorCollector) err() error {
+	switch len(c.entries) {
+	case 0:
+		return nil
+	case 1:
+		return c.entries[0].err
+	}
+	slices.SortStableFunc(c.entries, func(a, b collectedError) int {
+		return cmp.Or(cmp.Compare(a.name, b.name), cmp.Compare(a.index, b.index))
+	})
+	errs := make([]error, len(c.entries))
+	for i, e := range c.entries {
+		errs[i] = e.err
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,148 @@
+package cliparams
+
+import (
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/spanner"
+
+	"github.com/apstndb/memebridge"
+)
+
+// Flag collects repeated "name<separator>value" command-line flags into a
+// parameter map. It implements [flag.Value] and the Type method of
+// github.com/spf13/pflag, so one value can be registered with either:
+//
+//	params := cliparams.NewFlag(cliparams.WithBareTypeAsNull())
+//	flag.Var(params, "param", params.Usage())
+//
+// Each value is parsed as it is set, so a malformed value is reported
+// against the flag occurrence that supplied it. Repeating a name is an
+// error.
+//
+// The flag and pflag packages report a rejected value as "invalid value
+// %q for flag ..." with the raw argument, and print that message to the
+// output of the flag set, so a sensitive value (see [WithSensitiveNames])
+// would be shown despite the redaction done by Set. With sensitive
+// parameters, create the flag set with flag.ContinueOnError, discard its
+// output and report [Flag.Err] instead:
+//
+//	fs := flag.NewFlagSet("cmd", flag.ContinueOnError)
+//	fs.SetOutput(io.Discard)
+//	fs.Var(params, "param", params.Usage())
+//	if err := fs.Parse(args); err != nil {
+//		if perr := params.Err(); perr != nil {
+//			err = perr
+//		}
+//		log.Fatal(err)
+//	}
+type Flag struct {
+	opts   []Option
+	raw    []string
+	params map[string]spanner.GenericColumnValue
+	err    error
+}
+
+// NewFlag returns an empty Flag that parses values with opts. The zero
+// Flag is also usable and parses with the default options.
+// [WithAllErrors] has no effect, since each value is validated on its own.
+func NewFlag(opts ...Option) *Flag {
+	return &Flag{
+		opts:   opts,
+		params: make(map[string]spanner.GenericColumnValue),
+	}
+}
+
+// String returns the assignments set so far, in the order they were given.
+// Values of sensitive parameters (see [WithSensitiveNames]) are redacted.
+func (f *Flag) String() string {
+	if f == nil {
+		return ""
+	}
+	cfg := newConfig(f.opts)
+	args := make([]string, len(f.raw))
+	for i, arg := range f.raw {
+		args[i] = arg
+		if name, _, err := SplitAssignment(arg, f.opts...); err == nil && cfg.isSensitive(name) {
+			args[i] = name + cfg.separator + memebridge.RedactedPlaceholder
+		}
+	}
+	return strings.Join(args, ", ")
+}
+
+// Set parses one assignment and adds it to the parameter map.
+func (f *Flag) Set(arg string) error {
+	if f.params == nil {
+		*f = *NewFlag(f.opts...)
+	}
+	if err := f.set(arg); err != nil {
+		f.err = err
+		return err
+	}
+	return nil
+}
+
+func (f *Flag) set(arg string) error {
+	name, value, err := SplitAssignment(arg, f.opts...)
+	if err != nil {
+		return err
+	}
+	if _, ok := f.params[name]; ok {
+		return newConfig(f.opts).paramError(name, value, ErrDuplicateParameter)
+	}
+	gcv, err := parseParam(name, value, f.opts)
+	if err != nil {
+		return newConfig(f.opts).paramError(name, value, err)
+	}
+	f.params[name] = gcv
+	f.raw = append(f.raw, arg)
+	return nil
+}
+
+// Err returns the error of the last call to Set that failed, or nil. Unlike
+// the error the flag package builds from it, sensitive values are redacted.
+func (f *Flag) Err() error {
+	return f.err
+}
+
+// Type returns the value type name shown by pflag in help output.
+func (f *Flag) Type() string {
+	return "param"
+}
+
+// Params returns the parsed parameters. The map is shared with f.
+func (f *Flag) Params() map[string]spanner.GenericColumnValue {
+	return f.params
+}
+
+// StatementParams returns the parsed parameters in the shape of
+// [spanner.Statement] Params; see the package-level [StatementParams].
+func (f *Flag) StatementParams() map[string]any {
+	return StatementParams(f.params)
+}
+
+// Usage returns help text describing the accepted syntaxes under the
+// options f was created with, suitable as the usage argument of flag.Var.
+func (f *Flag) Usage() string {
+	cfg := newConfig(f.opts)
+	sep := cfg.separator
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "query parameter as name%svalue (repeatable). value is a GoogleSQL literal expression, e.g.\n", sep)
+	fmt.Fprintf(&sb, "  n%s1, s%s\"text\", d%sDATE \"2024-01-01\", a%s[1, 2], st%sSTRUCT<x INT64>(1), t%sCAST(NULL AS STRING)", sep, sep, sep, sep, sep, sep)
+	if cfg.bareTypeAsNull {
+		fmt.Fprintf(&sb, "\na bare type declares a typed NULL parameter, e.g. p%sARRAY<STRING>", sep)
+	}
+	if cfg.rawValues {
+		fmt.Fprintf(&sb, "\nTYPE=raw gives an unquoted value cast to TYPE, e.g. s%sSTRING=O'Brien, d%sDATE=2024-01-01, ids%sARRAY<INT64>=1,2,3, b%sBYTES@base64=3q2+7w==", sep, sep, sep, sep)
+	}
+	if cfg.fsys != nil {
+		fmt.Fprintf(&sb, "\n[TYPE]@file:PATH reads a JSON array or .npy vector, @bytes:PATH a BYTES value, @json:PATH a JSON value, e.g. emb%sARRAY<FLOAT32>@file:vec.json", sep)
+	}
+	if cfg.stdin != nil {
+		fmt.Fprintf(&sb, "\n@- reads the value from standard input, e.g. q%s@-", sep)
+	}
+	if cfg.barewordAsString {
+		sb.WriteString("\nvalues that are not expressions are taken as STRING text")
+	}
+	return sb.String()
+}
@@ -0,0 +1,76 @@
+package cliparams_test
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"strings"
+	"testing"
+
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+
+	"github.com/apstndb/memebridge/cliparams"
+)
+
+func TestFlag(t *testing.T) {
+	params := cliparams.NewFlag(cliparams.WithBareTypeAsNull())
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Var(params, "param", params.Usage())
+
+	if err := fs.Parse([]string{"--param", "a:1", "--param", `s:"x:y"`, "--param", "n:ARRAY<STRING>"}); err != nil {
+		t.Fatal(err)
+	}
+	got := params.Params()
+	for name, code := range map[string]sppb.TypeCode{
+		"a": sppb.TypeCode_INT64,
+		"s": sppb.TypeCode_STRING,
+		"n": sppb.TypeCode_ARRAY,
+	} {
+		if got[name].Type.GetCode() != code {
+			t.Errorf("param %s type = %v, want %v", name, got[name].Type.GetCode(), code)
+		}
+	}
+	if len(params.StatementParams()) != 3 {
+		t.Errorf("StatementParams() = %v, want 3 entries", params.StatementParams())
+	}
+	if got, want := params.String(), `a:1, s:"x:y", n:ARRAY<STRING>`; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if params.Type() != "param" {
+		t.Errorf("Type() = %q, want param", params.Type())
+	}
+}
+
+func TestFlagErrors(t *testing.T) {
+	t.Run("invalid value names the flag", func(t *testing.T) {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		fs.Var(cliparams.NewFlag(), "param", "")
+		err := fs.Parse([]string{"--param", "ok:1", "--param", "bad:("})
+		if err == nil || !strings.Contains(err.Error(), "-param") || !strings.Contains(err.Error(), `"bad"`) {
+			t.Errorf("want error naming flag and parameter, got %v", err)
+		}
+	})
+	t.Run("duplicate", func(t *testing.T) {
+		var params cliparams.Flag
+		if err := params.Set("a:1"); err != nil {
+			t.Fatal(err)
+		}
+		if err := params.Set("a:2"); !errors.Is(err, cliparams.ErrDuplicateParameter) {
+			t.Errorf("want ErrDuplicateParameter, got %v", err)
+		}
+	})
+	t.Run("separator", func(t *testing.T) {
+		params := cliparams.NewFlag(cliparams.WithSeparator("="))
+		if err := params.Set("a=1"); err != nil {
+			t.Fatal(err)
+		}
+		if err := params.Set("b:1"); err == nil {
+			t.Error("want error for missing separator, got nil")
+		}
+		if !strings.Contains(params.Usage(), "name=value") {
+			t.Errorf("Usage() does not mention separator: %s", params.Usage())
+		}
+	})
+}
@@ -0,0 +1,272 @@
+package cliparams
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"cloud.google.com/go/spanner"
+	"cloud.google.com/go/spanner/admin/database/apiv1/databasepb"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spantype"
+	"github.com/apstndb/spanvalue"
+	"github.com/cloudspannerecosystem/memefish/char"
+	"github.com/cloudspannerecosystem/memefish/token"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/apstndb/memebridge"
+)
+
+// OutputFormat selects how [FormatParams] renders a parameter map.
+type OutputFormat int
+
+const (
+	// FormatTable renders an aligned NAME/TYPE/VALUE table for display.
+	// Long arrays are truncated (see [WithMaxArrayElements]), so the output
+	// is not meant to be parsed back.
+	FormatTable OutputFormat = iota
+	// FormatAssignments renders one "name<separator>value" line per
+	// parameter, accepted by [ParseAssignments], [Flag] and [ReadParamsFile].
+	FormatAssignments
+	// FormatSetParam renders a "SET PARAM name = value;" script accepted by
+	// [ReadSetParamScript].
+	FormatSetParam
+	// FormatJSON renders a JSON object of typed parameters accepted by
+	// [ReadJSON]. The values of sensitive parameters are the string
+	// "<redacted>", which ReadJSON rejects with [ErrRedactedValue].
+	FormatJSON
+)
+
+var outputFormatNames = []string{
+	FormatTable:       "table",
+	FormatAssignments: "assignments",
+	FormatSetParam:    "set-param",
+	FormatJSON:        "json",
+}
+
+// String returns the name accepted by [ParseOutputFormat].
+func (f OutputFormat) String() string {
+	if f < 0 || int(f) >= len(outputFormatNames) {
+		return fmt.Sprintf("OutputFormat(%d)", int(f))
+	}
+	return outputFormatNames[f]
+}
+
+// ParseOutputFormat parses an output format name: "table", "assignments",
+// "set-param" or "json".
+func ParseOutputFormat(name string) (OutputFormat, error) {
+	if i := slices.Index(outputFormatNames, strings.ToLower(name)); i >= 0 {
+		return OutputFormat(i), nil
+	}
+	return 0, fmt.Errorf("cliparams: unknown output format %q (want %s)", name, strings.Join(outputFormatNames, ", "))
+}
+
+// defaultMaxArrayElements is the FormatTable array truncation limit.
+const defaultMaxArrayElements = 10
+
+// WithMaxArrayElements sets how many elements of each array [FormatParams]
+// shows in [FormatTable] output before eliding the rest; n <= 0 disables
+// truncation. The default is 10. Other formats never truncate.
+func WithMaxArrayElements(n int) Option {
+	return func(cfg *config) { cfg.maxArrayElements = n }
+}
+
+// FormatParams writes params to w in the given format, one parameter per
+// line in name order. Values are rendered as typed GoogleSQL literals, so
+// that every format except [FormatTable] parses back to the same types and
+// values: typed NULLs as CAST(NULL AS T), arrays as ARRAY<T>[...] and
+// structs as STRUCT<...>(...). [WithSeparator] applies to
+// [FormatAssignments].
+//
+// Values of sensitive parameters (see [WithSensitiveNames]) are rendered as
+// [memebridge.RedactedPlaceholder] with their types kept, in every format;
+// such output does not parse back.
+func FormatParams(w io.Writer, params map[string]spanner.GenericColumnValue, format OutputFormat, opts ...Option) error {
+	cfg := newConfig(opts)
+	names := slices.Sorted(maps.Keys(params))
+
+	var buf bytes.Buffer
+	switch format {
+	case FormatTable:
+		tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tTYPE\tVALUE")
+		for _, name := range names {
+			lit, err := cfg.formatParamLiteral(name, params[name], cfg.maxArrayElements)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", name, typeSQL(params[name].Type), lit)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	case FormatAssignments, FormatSetParam:
+		for _, name := range names {
+			lit, err := cfg.formatParamLiteral(name, params[name], 0)
+			if err != nil {
+				return err
+			}
+			if format == FormatAssignments {
+				fmt.Fprintf(&buf, "%s%s%s\n", name, cfg.separator, lit)
+			} else {
+				fmt.Fprintf(&buf, "SET PARAM %s = %s;\n", identSQL(name), lit)
+			}
+		}
+	case FormatJSON:
+		obj := make(map[string]typedJSONParam, len(params))
+		for _, name := range names {
+			typed, err := typedJSON(params[name])
+			if err != nil {
+				return fmt.Errorf("cliparams: formatting parameter %q: %w", name, err)
+			}
+			if cfg.isSensitive(name) {
+				typed.Value = redactedJSON
+			}
+			obj[name] = typed
+		}
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(obj); err != nil {
+			return fmt.Errorf("cliparams: formatting JSON: %w", err)
+		}
+	default:
+		return fmt.Errorf("cliparams: unknown output format %v", format)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// FormatLiteral renders gcv as the typed GoogleSQL literal that
+// [FormatParams] writes, which [ParseValue] parses back to the same type and
+// value.
+func FormatLiteral(gcv spanner.GenericColumnValue) (string, error) {
+	return literalSQL(gcv, true, 0)
+}
+
+// redactedJSON is the "value" of a sensitive parameter in FormatJSON output.
+var redactedJSON = json.RawMessage(strconv.Quote(memebridge.RedactedPlaceholder))
+
+func (cfg config) formatParamLiteral(name string, gcv spanner.GenericColumnValue, maxElems int) (string, error) {
+	if cfg.isSensitive(name) {
+		return memebridge.RedactedPlaceholder, nil
+	}
+	lit, err := literalSQL(gcv, true, maxElems)
+	if err != nil {
+		return "", fmt.Errorf("cliparams: formatting parameter %q: %w", name, err)
+	}
+	return lit, nil
+}
+
+// literalSQL renders gcv as a GoogleSQL literal. Typed contexts (ARRAY
+// elements and STRUCT fields) render NULL without a CAST.
+func literalSQL(gcv spanner.GenericColumnValue, toplevel bool, maxElems int) (string, error) {
+	if spanvalue.IsNull(gcv) {
+		if toplevel {
+			return "CAST(NULL AS " + typeSQL(gcv.Type) + ")", nil
+		}
+		return "NULL", nil
+	}
+	switch gcv.Type.GetCode() {
+	case sppb.TypeCode_ARRAY:
+		values := gcv.Value.GetListValue().GetValues()
+		shown := values
+		if maxElems > 0 && len(values) > maxElems {
+			shown = values[:maxElems]
+		}
+		elems := make([]string, 0, len(shown)+1)
+		for _, v := range shown {
+			s, err := literalSQL(spanner.GenericColumnValue{Type: gcv.Type.GetArrayElementType(), Value: v}, false, maxElems)
+			if err != nil {
+				return "", err
+			}
+			elems = append(elems, s)
+		}
+		if len(shown) < len(values) {
+			elems = append(elems, fmt.Sprintf("... +%d more", len(values)-len(shown)))
+		}
+		return typeSQL(gcv.Type) + "[" + strings.Join(elems, ", ") + "]", nil
+	case sppb.TypeCode_STRUCT:
+		fields := gcv.Type.GetStructType().GetFields()
+		values := gcv.Value.GetListValue().GetValues()
+		if len(fields) != len(values) {
+			return "", fmt.Errorf("STRUCT value has %d fields but type has %d", len(values), len(fields))
+		}
+		parts := make([]string, len(fields))
+		for i, field := range fields {
+			s, err := literalSQL(spanner.GenericColumnValue{Type: field.GetType(), Value: values[i]}, false, maxElems)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = s
+		}
+		return typeSQL(gcv.Type) + "(" + strings.Join(parts, ", ") + ")", nil
+	default:
+		return spanvalue.FormatColumnLiteral(gcv)
+	}
+}
+
+// typeSQL renders t as a GoogleSQL type, keeping STRUCT field names.
+func typeSQL(t *sppb.Type) string {
+	switch t.GetCode() {
+	case sppb.TypeCode_ARRAY:
+		return "ARRAY<" + typeSQL(t.GetArrayElementType()) + ">"
+	case sppb.TypeCode_STRUCT:
+		fields := t.GetStructType().GetFields()
+		parts := make([]string, len(fields))
+		for i, field := range fields {
+			if field.GetName() == "" {
+				parts[i] = typeSQL(field.GetType())
+			} else {
+				parts[i] = identSQL(field.GetName()) + " " + typeSQL(field.GetType())
+			}
+		}
+		return "STRUCT<" + strings.Join(parts, ", ") + ">"
+	default:
+		return spantype.FormatTypeNormal(t)
+	}
+}
+
+// identSQL quotes name with backticks unless it is a plain non-keyword
+// identifier.
+func identSQL(name string) string {
+	plain := name != "" && char.IsIdentStart(name[0]) && !token.IsKeyword(name)
+	for i := 1; plain && i < len(name); i++ {
+		plain = char.IsIdentPart(name[i])
+	}
+	if plain {
+		return name
+	}
+	return spanvalue.QuoteIdentifier(databasepb.DatabaseDialect_GOOGLE_STANDARD_SQL, name)
+}
+
+// typedJSON renders gcv in the typed object form accepted by ReadJSON.
+func typedJSON(gcv spanner.GenericColumnValue) (typedJSONParam, error) {
+	typ, err := compactProtoJSON(protojson.Marshal(gcv.Type))
+	if err != nil {
+		return typedJSONParam{}, err
+	}
+	value, err := compactProtoJSON(protojson.Marshal(gcv.Value))
+	if err != nil {
+		return typedJSONParam{}, err
+	}
+	return typedJSONParam{Type: typ, Value: value}, nil
+}
+
+// compactProtoJSON removes the unstable whitespace of protojson output.
+func compactProtoJSON(b []byte, err error) (json.RawMessage, error) {
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, b); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,138 @@
+package cliparams_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/spanner"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/apstndb/memebridge"
+	"github.com/apstndb/memebridge/cliparams"
+)
+
+func formatTestParams(t *testing.T) map[string]spanner.GenericColumnValue {
+	t.Helper()
+	params := make(map[string]spanner.GenericColumnValue)
+	for name, expr := range map[string]string{
+		"i":        `1`,
+		"s":        `"O'Brien: \"quoted\""`,
+		"f32":      `CAST(1.5 AS FLOAT32)`,
+		"nan":      `CAST("nan" AS FLOAT64)`,
+		"n":        `NUMERIC "1.25"`,
+		"b":        `b"\x00\xff"`,
+		"d":        `DATE "2024-01-01"`,
+		"ts":       `TIMESTAMP "2024-01-01T00:00:00Z"`,
+		"j":        `JSON '{"a": [1, 2]}'`,
+		"u":        `CAST("94a01a73-d90a-432d-a03f-5db58ea8058f" AS UUID)`,
+		"iv":       `INTERVAL 1 DAY`,
+		"null_d":   `CAST(NULL AS DATE)`,
+		"null_arr": `CAST(NULL AS ARRAY<STRING>)`,
+		"empty":    `ARRAY<INT64>[]`,
+		"arr":      `[1, NULL, 3]`,
+		"st":       `STRUCT<x INT64, ` + "`select`" + ` STRING>(1, NULL)`,
+		"anon":     `STRUCT(1, "a")`,
+		"structs":  `[STRUCT(1 AS a)]`,
+	} {
+		gcv, err := memebridge.ParseExprToGCV(expr)
+		if err != nil {
+			t.Fatalf("%s: %v", expr, err)
+		}
+		params[name] = gcv
+	}
+	return params
+}
+
+func TestFormatParamsRoundTrip(t *testing.T) {
+	params := formatTestParams(t)
+	for _, tt := range []struct {
+		format cliparams.OutputFormat
+		opts   []cliparams.Option
+		parse  func(out string) (map[string]spanner.GenericColumnValue, error)
+	}{
+		{cliparams.FormatAssignments, nil, func(out string) (map[string]spanner.GenericColumnValue, error) {
+			return cliparams.ParseAssignments(strings.Split(strings.TrimSuffix(out, "\n"), "\n"))
+		}},
+		{cliparams.FormatAssignments, []cliparams.Option{cliparams.WithSeparator("=")}, func(out string) (map[string]spanner.GenericColumnValue, error) {
+			return cliparams.ReadParamsFile("params", strings.NewReader(out), cliparams.WithSeparator("="))
+		}},
+		{cliparams.FormatSetParam, nil, func(out string) (map[string]spanner.GenericColumnValue, error) {
+			return cliparams.ReadSetParamScript("params.sql", strings.NewReader(out))
+		}},
+		{cliparams.FormatJSON, nil, func(out string) (map[string]spanner.GenericColumnValue, error) {
+			return cliparams.ReadJSON("params.json", strings.NewReader(out))
+		}},
+	} {
+		t.Run(tt.format.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := cliparams.FormatParams(&buf, params, tt.format, tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			got, err := tt.parse(buf.String())
+			if err != nil {
+				t.Fatalf("parsing output:\n%s\nerror: %v", buf.String(), err)
+			}
+			if diff := cmp.Diff(params, got, protocmp.Transform()); diff != "" {
+				t.Errorf("round trip mismatch (-want +got):\n%s\noutput:\n%s", diff, buf.String())
+			}
+
+			var again bytes.Buffer
+			if err := cliparams.FormatParams(&again, params, tt.format, tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if again.String() != buf.String() {
+				t.Errorf("output is not deterministic:\n%s\n---\n%s", buf.String(), again.String())
+			}
+		})
+	}
+}
+
+func TestFormatParamsTable(t *testing.T) {
+	params := map[string]spanner.GenericColumnValue{
+		"long": mustParse(t, `[1, 2, 3, 4, 5]`),
+		"d":    mustParse(t, `CAST(NULL AS DATE)`),
+		"s":    mustParse(t, `"x"`),
+	}
+	var buf bytes.Buffer
+	if err := cliparams.FormatParams(&buf, params, cliparams.FormatTable, cliparams.WithMaxArrayElements(3)); err != nil {
+		t.Fatal(err)
+	}
+	want := `NAME  TYPE          VALUE
+d     DATE          CAST(NULL AS DATE)
+long  ARRAY<INT64>  ARRAY<INT64>[1, 2, 3, ... +2 more]
+s     STRING        "x"
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("table mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	for _, f := range []cliparams.OutputFormat{cliparams.FormatTable, cliparams.FormatAssignments, cliparams.FormatSetParam, cliparams.FormatJSON} {
+		got, err := cliparams.ParseOutputFormat(f.String())
+		if err != nil || got != f {
+			t.Errorf("ParseOutputFormat(%q) = %v, %v", f.String(), got, err)
+		}
+	}
+	if _, err := cliparams.ParseOutputFormat("yaml"); err == nil {
+		t.Error("ParseOutputFormat(yaml) succeeded, want error")
+	}
+}
+
+func TestFormatLiteral(t *testing.T) {
+	for name, gcv := range formatTestParams(t) {
+		lit, err := cliparams.FormatLiteral(gcv)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		got, err := cliparams.ParseValue(lit)
+		if err != nil {
+			t.Fatalf("%s: ParseValue(%q): %v", name, lit, err)
+		}
+		if diff := cmp.Diff(gcv, got, protocmp.Transform()); diff != "" {
+			t.Errorf("%s: round trip of %q mismatch (-want +got):\n%s", name, lit, diff)
+		}
+	}
+}
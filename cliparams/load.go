@@ -0,0 +1,429 @@
+package cliparams
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spanvalue/gcvctor"
+	"github.com/cloudspannerecosystem/memefish"
+	"github.com/cloudspannerecosystem/memefish/ast"
+	"github.com/cloudspannerecosystem/memefish/char"
+	"github.com/cloudspannerecosystem/memefish/token"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/apstndb/memebridge"
+)
+
+// LoadFile reads a parameter set from name in fsys, choosing the format by
+// extension: ".json" uses [ReadJSON], ".sql" uses [ReadSetParamScript], and
+// anything else uses [ReadParamsFile]. Errors carry name:line positions.
+func LoadFile(fsys fs.FS, name string, opts ...Option) (map[string]spanner.GenericColumnValue, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("cliparams: %w", err)
+	}
+	defer f.Close()
+
+	switch strings.ToLower(path.Ext(name)) {
+	case ".json":
+		return ReadJSON(name, f, opts...)
+	case ".sql":
+		return ReadSetParamScript(name, f, opts...)
+	default:
+		return ReadParamsFile(name, f, opts...)
+	}
+}
+
+// paramSet accumulates loaded parameters, rejecting duplicates and
+// collecting errors according to [WithAllErrors].
+type paramSet struct {
+	filename string
+	cfg      config
+	params   map[string]spanner.GenericColumnValue
+	errs     errorCollector
+	index    int
+}
+
+func newParamSet(filename string, cfg config) *paramSet {
+	return &paramSet{
+		filename: filename,
+		cfg:      cfg,
+		params:   make(map[string]spanner.GenericColumnValue),
+		errs:     errorCollector{all: cfg.allErrors},
+	}
+}
+
+// add records one loaded parameter produced by parse, and reports whether
+// loading should stop.
+func (s *paramSet) add(name, value string, line int, parse func() (spanner.GenericColumnValue, error)) bool {
+	s.index++
+	if _, ok := s.params[name]; ok {
+		return s.errs.add(name, s.index, s.paramError(name, value, line, ErrDuplicateParameter))
+	}
+	gcv, err := parse()
+	if err != nil {
+		// Reserve the name so that later duplicates are still reported.
+		s.params[name] = spanner.GenericColumnValue{}
+		return s.errs.add(name, s.index, s.paramError(name, value, line, err))
+	}
+	s.params[name] = gcv
+	return false
+}
+
+func (s *paramSet) paramError(name, value string, line int, err error) *ParamError {
+	perr := s.cfg.paramError(name, value, err)
+	perr.File, perr.Line = s.filename, line
+	return perr
+}
+
+// fail records an error that is not attributable to a parameter name, and
+// reports whether loading should stop.
+func (s *paramSet) fail(line int, err error) bool {
+	s.index++
+	return s.errs.add("", s.index, fmt.Errorf("cliparams: %s:%d: %w", s.filename, line, err))
+}
+
+func (s *paramSet) result() (map[string]spanner.GenericColumnValue, error) {
+	if err := s.errs.err(); err != nil {
+		return nil, err
+	}
+	return s.params, nil
+}
+
+// ReadJSON reads a JSON object mapping parameter names to values. Each value
+// is either a GoogleSQL expression string, parsed like [ParseValue]:
+//
+//	{"id": "1", "d": "DATE '2024-01-01'", "tags": "ARRAY<STRING>[]"}
+//
+// or a typed object carrying a protojson-encoded spannerpb.Type and the
+// protojson wire value, which is checked with [memebridge.ValidateGCV] but
+// not re-evaluated:
+//
+//	{"id": {"type": {"code": "INT64"}, "value": "1"}}
+//
+// Both forms are subject to [WithExpectedTypes], [WithColumnSpecs] and
+// [WithEvalOptions], such as [memebridge.WithParamContext].
+// A typed object without "value" (or with a null value) yields a typed NULL.
+// The "<redacted>" value of sensitive parameters in [FormatJSON] output is
+// rejected with [ErrRedactedValue].
+// filename is used only in error positions.
+func ReadJSON(filename string, r io.Reader, opts ...Option) (map[string]spanner.GenericColumnValue, error) {
+	cfg := newConfig(opts)
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("cliparams: reading %s: %w", filename, err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("cliparams: %s:%d: %w", filename, lineAtOffset(data, dec.InputOffset()), err)
+	} else if tok != json.Delim('{') {
+		return nil, fmt.Errorf("cliparams: %s:1: expected a JSON object of parameters", filename)
+	}
+
+	set := newParamSet(filename, cfg)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("cliparams: %s:%d: %w", filename, lineAtOffset(data, dec.InputOffset()), err)
+		}
+		name, _ := tok.(string)
+		line := lineAtOffset(data, dec.InputOffset())
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("cliparams: %s:%d: %w", filename, line, err)
+		}
+		if name == "" {
+			if set.fail(line, errors.New("empty parameter name")) {
+				return set.result()
+			}
+			continue
+		}
+		if set.add(name, string(raw), line, func() (spanner.GenericColumnValue, error) {
+			return parseJSONParamValue(name, raw, opts)
+		}) {
+			return set.result()
+		}
+	}
+	// Consume the closing brace and reject anything after the object.
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("cliparams: %s:%d: %w", filename, lineAtOffset(data, dec.InputOffset()), err)
+	}
+	end := dec.InputOffset()
+	if rest := bytes.TrimLeft(data[end:], " \t\r\n"); len(rest) > 0 {
+		return nil, fmt.Errorf("cliparams: %s:%d: trailing data after the JSON object", filename, lineAtOffset(data, int64(len(data)-len(rest))))
+	}
+	return set.result()
+}
+
+// typedJSONParam is the typed form accepted by ReadJSON.
+type typedJSONParam struct {
+	Type  json.RawMessage `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+func parseJSONParamValue(name string, raw json.RawMessage, opts []Option) (spanner.GenericColumnValue, error) {
+	switch trimmed := bytes.TrimSpace(raw); {
+	case len(trimmed) > 0 && trimmed[0] == '"':
+		var expr string
+		if err := json.Unmarshal(trimmed, &expr); err != nil {
+			return spanner.GenericColumnValue{}, err
+		}
+		return parseParam(name, expr, opts)
+	case len(trimmed) > 0 && trimmed[0] == '{':
+		var typed typedJSONParam
+		dec := json.NewDecoder(bytes.NewReader(trimmed))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&typed); err != nil {
+			return spanner.GenericColumnValue{}, fmt.Errorf("invalid typed parameter: %w", err)
+		}
+		if len(typed.Type) == 0 {
+			return spanner.GenericColumnValue{}, errors.New(`invalid typed parameter: missing "type"`)
+		}
+		var typ sppb.Type
+		if err := protojson.Unmarshal(typed.Type, &typ); err != nil {
+			return spanner.GenericColumnValue{}, fmt.Errorf("invalid typed parameter type: %w", err)
+		}
+		if bytes.Equal(bytes.TrimSpace(typed.Value), redactedJSON) {
+			return spanner.GenericColumnValue{}, fmt.Errorf("%w: output of a sensitive parameter cannot be read back", ErrRedactedValue)
+		}
+		return parseParamWith(name, string(trimmed), opts, func(_ string, cfg config) (spanner.GenericColumnValue, error) {
+			if len(typed.Value) == 0 {
+				return typedParamValue(gcvctor.NullOf(&typ), cfg)
+			}
+			var value structpb.Value
+			if err := protojson.Unmarshal(typed.Value, &value); err != nil {
+				return spanner.GenericColumnValue{}, fmt.Errorf("invalid typed parameter value: %w", cfg.cause(err))
+			}
+			return typedParamValue(spanner.GenericColumnValue{Type: &typ, Value: &value}, cfg)
+		})
+	default:
+		return spanner.GenericColumnValue{}, errors.New("value must be a GoogleSQL expression string or a typed object")
+	}
+}
+
+// typedParamValue checks the wire value of a typed parameter with
+// [memebridge.ValidateGCV] and then evaluates it as a query parameter, so
+// that the expected type and the evaluation options apply as they do to
+// expressions.
+func typedParamValue(gcv spanner.GenericColumnValue, cfg config) (spanner.GenericColumnValue, error) {
+	if err := memebridge.ValidateGCV(gcv); err != nil {
+		return spanner.GenericColumnValue{}, fmt.Errorf("invalid typed parameter value: %w", cfg.cause(err))
+	}
+	param := &ast.Param{Name: "value"}
+	params := map[string]spanner.GenericColumnValue{param.Name: gcv}
+	gcv, err := memebridge.MemefishExprToGCV(param, cfg.evalOptions(memebridge.WithParams(params), memebridge.WithExpectedType(cfg.expectedType))...)
+	if err != nil {
+		return spanner.GenericColumnValue{}, fmt.Errorf("invalid typed parameter: %w", err)
+	}
+	return gcv, nil
+}
+
+func lineAtOffset(data []byte, offset int64) int {
+	offset = min(offset, int64(len(data)))
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+// ReadParamsFile reads "name<separator>value" assignments, one per line, in
+// the syntax of [ParseAssignments]. Blank lines and lines whose first
+// non-blank character is '#' are ignored. A line ending with a backslash
+// continues on the next line; the backslash is replaced by a newline. Errors
+// report the line where the assignment starts. filename is used only in
+// error positions.
+func ReadParamsFile(filename string, r io.Reader, opts ...Option) (map[string]spanner.GenericColumnValue, error) {
+	cfg := newConfig(opts)
+	set := newParamSet(filename, cfg)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64*1024*1024)
+	var (
+		pending   strings.Builder
+		startLine int
+		lineNum   int
+	)
+	flush := func() bool {
+		arg := strings.TrimSpace(pending.String())
+		pending.Reset()
+		if arg == "" {
+			return false
+		}
+		name, value, err := SplitAssignment(arg, opts...)
+		if err != nil {
+			return set.fail(startLine, err)
+		}
+		value = strings.TrimSpace(value)
+		return set.add(name, value, startLine, func() (spanner.GenericColumnValue, error) {
+			return parseParam(name, value, opts)
+		})
+	}
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if pending.Len() == 0 {
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			startLine = lineNum
+		}
+		if body, ok := strings.CutSuffix(strings.TrimRight(line, " \t\r"), `\`); ok {
+			pending.WriteString(body)
+			pending.WriteString("\n")
+			continue
+		}
+		pending.WriteString(line)
+		if flush() {
+			return set.result()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cliparams: reading %s: %w", filename, err)
+	}
+	flush()
+	return set.result()
+}
+
+// ReadSetParamScript reads a SQL script of statements of the form
+//
+//	SET PARAM name = <expression>;
+//	SET PARAM name <type>;
+//
+// as used by spanner-mycli. Statements are split with memefish, so comments
+// and semicolons inside literals are handled. The first form evaluates the
+// expression like [ParseValue]; the second declares a typed NULL parameter.
+// Errors report the line of the offending statement. filename is used only
+// in error positions.
+func ReadSetParamScript(filename string, r io.Reader, opts ...Option) (map[string]spanner.GenericColumnValue, error) {
+	cfg := newConfig(opts)
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("cliparams: reading %s: %w", filename, err)
+	}
+	src := string(data)
+	stmts, err := memefish.SplitRawStatements(filename, src)
+	if err != nil {
+		return nil, fmt.Errorf("cliparams: %w", err)
+	}
+
+	file := &token.File{FilePath: filename, Buffer: src}
+	set := newParamSet(filename, cfg)
+	for _, raw := range stmts {
+		stmt, err := parseSetParam(filename, raw.Statement)
+		if err != nil {
+			if set.fail(file.Position(raw.Pos, raw.Pos).Line+1, err) {
+				break
+			}
+			continue
+		}
+		if stmt == nil {
+			continue
+		}
+		pos := raw.Pos + stmt.pos
+		line := file.Position(pos, pos).Line + 1
+		if set.add(stmt.name, stmt.text, line, stmt.eval(opts)) {
+			break
+		}
+	}
+	return set.result()
+}
+
+// setParamStatement is one parsed SET PARAM statement.
+type setParamStatement struct {
+	pos    token.Pos // offset of SET within the raw statement
+	name   string
+	text   string // expression text, or type text when isType
+	isType bool
+}
+
+func (s *setParamStatement) eval(opts []Option) func() (spanner.GenericColumnValue, error) {
+	return func() (spanner.GenericColumnValue, error) {
+		if !s.isType {
+			return parseParam(s.name, s.text, opts)
+		}
+		typ, err := parseType(s.text)
+		if err != nil {
+			return spanner.GenericColumnValue{}, fmt.Errorf("cliparams: parsing type %q: %w", s.text, err)
+		}
+		return typedNull(typ, s.text, newConfig(opts))
+	}
+}
+
+// parseSetParam parses one raw statement. It returns nil for statements that
+// contain only whitespace and comments.
+func parseSetParam(filename, stmt string) (*setParamStatement, error) {
+	lex := &memefish.Lexer{File: &token.File{FilePath: filename, Buffer: stmt}}
+	next := func() (token.Token, error) {
+		if err := lex.NextToken(); err != nil {
+			return token.Token{}, err
+		}
+		return lex.Token, nil
+	}
+
+	set, err := next()
+	if err != nil {
+		return nil, err
+	}
+	if set.Kind == token.TokenEOF {
+		return nil, nil
+	}
+	if set.Kind != "SET" {
+		return nil, fmt.Errorf("expected SET PARAM statement, got %q", set.Raw)
+	}
+	param, err := next()
+	if err != nil {
+		return nil, err
+	}
+	if !param.IsKeywordLike("PARAM") {
+		return nil, fmt.Errorf("expected PARAM after SET, got %q", param.Raw)
+	}
+	nameTok, err := next()
+	if err != nil {
+		return nil, err
+	}
+	name, ok := identifierName(nameTok)
+	if !ok {
+		return nil, fmt.Errorf("expected parameter name after SET PARAM, got %q", nameTok.Raw)
+	}
+	rest, err := next()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &setParamStatement{pos: set.Pos, name: name}
+	switch rest.Kind {
+	case token.TokenEOF:
+		return nil, fmt.Errorf("SET PARAM %s requires \"= <expression>\" or a type", name)
+	case "=":
+		result.text = strings.TrimSpace(stmt[rest.End:])
+	default:
+		result.text = strings.TrimSpace(stmt[rest.Pos:])
+		result.isType = true
+	}
+	if result.text == "" {
+		return nil, fmt.Errorf("SET PARAM %s has an empty value", name)
+	}
+	return result, nil
+}
+
+// identifierName accepts both identifiers and keywords as parameter names,
+// since query parameters such as @limit are not restricted by keywords.
+func identifierName(tok token.Token) (string, bool) {
+	switch {
+	case tok.Kind == token.TokenIdent:
+		return tok.AsString, true
+	case token.IsKeyword(string(tok.Kind)) && char.EqualFold(string(tok.Kind), tok.Raw):
+		return tok.Raw, true
+	default:
+		return "", false
+	}
+}
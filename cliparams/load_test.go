@@ -0,0 +1,223 @@
+package cliparams_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spantype/typector"
+	"github.com/apstndb/spanvalue/gcvctor"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/apstndb/memebridge"
+	"github.com/apstndb/memebridge/cliparams"
+)
+
+func TestReadJSON(t *testing.T) {
+	input := `{
+  "id": "1",
+  "d": "DATE '2024-01-01'",
+  "typed": {"type": {"code": "INT64"}, "value": "42"},
+  "null_tags": {"type": {"code": "ARRAY", "arrayElementType": {"code": "STRING"}}}
+}`
+	got, err := cliparams.ReadJSON("params.json", strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]spanner.GenericColumnValue{
+		"id":        gcvctor.Int64Value(1),
+		"d":         gcvctor.StringBasedValueFromCode(sppb.TypeCode_DATE, "2024-01-01"),
+		"typed":     gcvOf(typector.Int64(), structpb.NewStringValue("42")),
+		"null_tags": gcvctor.NullOf(typector.ElemCodeToArrayType(sppb.TypeCode_STRING)),
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("ReadJSON mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadJSONTypedChecks(t *testing.T) {
+	int64Type := map[string]*sppb.Type{"p": typector.Int64()}
+	tests := []struct {
+		desc    string
+		value   string
+		opts    []cliparams.Option
+		want    spanner.GenericColumnValue
+		wantErr error
+	}{
+		{"invalid INT64", `{"type": {"code": "INT64"}, "value": "abc"}`, nil, spanner.GenericColumnValue{}, &memebridge.ValueError{}},
+		{"STRUCT arity", `{"type": {"code": "STRUCT", "structType": {"fields": [{"name": "a", "type": {"code": "INT64"}}]}}, "value": []}`, nil, spanner.GenericColumnValue{}, &memebridge.ValueError{}},
+		{"expected type coerces", `{"type": {"code": "INT64"}, "value": "1"}`, []cliparams.Option{cliparams.WithExpectedTypes(map[string]*sppb.Type{"p": typector.Float64()})}, gcvctor.Float64Value(1), nil},
+		{"expected type mismatch", `{"type": {"code": "STRING"}, "value": "1"}`, []cliparams.Option{cliparams.WithExpectedTypes(int64Type)}, spanner.GenericColumnValue{}, nil},
+		{"typed NULL of expected type", `{"type": {"code": "STRING"}}`, []cliparams.Option{cliparams.WithExpectedTypes(int64Type)}, spanner.GenericColumnValue{}, nil},
+		{"column spec", `{"type": {"code": "STRING"}, "value": "toolong"}`, []cliparams.Option{cliparams.WithColumnSpecs(map[string]memebridge.ColumnSpec{"p": {Type: typector.String(), Constraints: memebridge.TypeConstraints{Length: 3}}})}, spanner.GenericColumnValue{}, &memebridge.ConstraintViolation{}},
+		{"param context", `{"type": {"code": "ARRAY", "arrayElementType": {"code": "ARRAY", "arrayElementType": {"code": "INT64"}}}}`, []cliparams.Option{cliparams.WithEvalOptions(memebridge.WithParamContext())}, spanner.GenericColumnValue{}, memebridge.ErrTypeNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := cliparams.ReadJSON("params.json", strings.NewReader(`{"p": `+tt.value+`}`), tt.opts...)
+			if tt.want.Type != nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if diff := cmp.Diff(tt.want, got["p"], protocmp.Transform()); diff != "" {
+					t.Errorf("mismatch (-want +got):\n%s", diff)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("got %v, want error", got)
+			}
+			switch want := tt.wantErr.(type) {
+			case *memebridge.ValueError:
+				if !errors.As(err, &want) {
+					t.Errorf("err = %v, want a ValueError", err)
+				}
+			case *memebridge.ConstraintViolation:
+				if !errors.As(err, &want) {
+					t.Errorf("err = %v, want a ConstraintViolation", err)
+				}
+			case error:
+				if !errors.Is(err, want) {
+					t.Errorf("err = %v, want %v", err, want)
+				}
+			}
+		})
+	}
+
+	_, err := cliparams.ReadJSON("params.json", strings.NewReader(`{"token": {"type": {"code": "INT64"}, "value": "hunter2"}}`), cliparams.WithSensitiveNames("token"))
+	if err == nil || strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("sensitive typed value: err = %v, want an error without the value", err)
+	}
+}
+
+func TestReadJSONErrorPositions(t *testing.T) {
+	input := "{\n  \"ok\": \"1\",\n  \"bad\": \"(\",\n  \"num\": 1\n}"
+	_, err := cliparams.ReadJSON("params.json", strings.NewReader(input), cliparams.WithAllErrors())
+	if err == nil {
+		t.Fatal("want error, got nil")
+	}
+	for _, want := range []string{`params.json:3: parameter "bad"`, `params.json:4: parameter "num"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+}
+
+func TestReadJSONTrailingData(t *testing.T) {
+	for input, want := range map[string]string{
+		`{"a": "1"} garbage`:    "params.json:1: trailing data",
+		"{\"a\": \"1\"}\n\n}":   "params.json:3: trailing data",
+		`{"a": "1"} {"b": "2"}`: "params.json:1: trailing data",
+		`{"a": "1"`:             "params.json:1:",
+	} {
+		if _, err := cliparams.ReadJSON("params.json", strings.NewReader(input)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ReadJSON(%q) err = %v, want one containing %q", input, err, want)
+		}
+	}
+	if _, err := cliparams.ReadJSON("params.json", strings.NewReader("{\"a\": \"1\"}\n")); err != nil {
+		t.Errorf("trailing newline: %v", err)
+	}
+}
+
+func TestReadParamsFile(t *testing.T) {
+	input := `# comment
+a: 1
+
+  # indented comment
+s: "x"
+arr: [1, \
+      2, \
+      3]
+`
+	got, err := cliparams.ReadParamsFile("test.params", strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]spanner.GenericColumnValue{
+		"a":   gcvctor.Int64Value(1),
+		"s":   gcvctor.StringValue("x"),
+		"arr": gcvctor.MustArrayValueOf(typector.Int64(), gcvctor.Int64Value(1), gcvctor.Int64Value(2), gcvctor.Int64Value(3)),
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("ReadParamsFile mismatch (-want +got):\n%s", diff)
+	}
+
+	t.Run("positions", func(t *testing.T) {
+		input := "a: 1\nb: \\\n  (\nnoseparator\na: 2\n"
+		_, err := cliparams.ReadParamsFile("test.params", strings.NewReader(input), cliparams.WithAllErrors())
+		if err == nil {
+			t.Fatal("want error, got nil")
+		}
+		for _, want := range []string{"test.params:4:", `test.params:2: parameter "b"`, `test.params:5: parameter "a"`} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("error %q does not contain %q", err, want)
+			}
+		}
+		var pe *cliparams.ParamError
+		if !errors.As(err, &pe) || pe.File != "test.params" {
+			t.Errorf("want ParamError with file, got %v", err)
+		}
+	})
+}
+
+func TestReadSetParamScript(t *testing.T) {
+	input := `-- parameters for the report
+SET PARAM id = 1;
+SET PARAM name = "a;b"; /* semicolon inside literal */
+SET PARAM tags ARRAY<STRING>;
+set param LIMIT = 10
+`
+	got, err := cliparams.ReadSetParamScript("params.sql", strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]spanner.GenericColumnValue{
+		"id":    gcvctor.Int64Value(1),
+		"name":  gcvctor.StringValue("a;b"),
+		"tags":  gcvctor.NullOf(typector.ElemCodeToArrayType(sppb.TypeCode_STRING)),
+		"LIMIT": gcvctor.Int64Value(10),
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("ReadSetParamScript mismatch (-want +got):\n%s", diff)
+	}
+
+	t.Run("positions", func(t *testing.T) {
+		input := "SET PARAM a = 1;\n\nSET PARAM b = (;\nSELECT 1;\n"
+		_, err := cliparams.ReadSetParamScript("params.sql", strings.NewReader(input), cliparams.WithAllErrors())
+		if err == nil {
+			t.Fatal("want error, got nil")
+		}
+		for _, want := range []string{`params.sql:3: parameter "b"`, "params.sql:4: expected SET PARAM statement"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("error %q does not contain %q", err, want)
+			}
+		}
+	})
+}
+
+func TestLoadFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"p.json":   {Data: []byte(`{"a": "1"}`)},
+		"p.sql":    {Data: []byte(`SET PARAM a = 1;`)},
+		"p.params": {Data: []byte("a: 1\n")},
+	}
+	for _, name := range []string{"p.json", "p.sql", "p.params"} {
+		t.Run(name, func(t *testing.T) {
+			got, err := cliparams.LoadFile(fsys, name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(map[string]spanner.GenericColumnValue{"a": gcvctor.Int64Value(1)}, got, protocmp.Transform()); diff != "" {
+				t.Errorf("LoadFile(%q) mismatch (-want +got):\n%s", name, diff)
+			}
+		})
+	}
+	if _, err := cliparams.LoadFile(fsys, "missing.json"); err == nil {
+		t.Error("want error for missing file, got nil")
+	}
+}
@@ -0,0 +1,163 @@
+package cliparams
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spanvalue/gcvctor"
+	"github.com/cloudspannerecosystem/memefish/ast"
+
+	"github.com/apstndb/memebridge"
+)
+
+// WithRawValues accepts the shell-friendly "TYPE=raw" value syntax, where
+// raw is unquoted text converted with CAST semantics ([memebridge.CastGCV]):
+//
+//	name:STRING=O'Brien             → "O'Brien"
+//	d:DATE=2024-01-01               → CAST('2024-01-01' AS DATE)
+//	ids:ARRAY<INT64>=1,2,3          → [1, 2, 3]
+//	b:BYTES@base64=3q2+7w==         → b"\xde\xad\xbe\xef"
+//	h:ARRAY<BYTES>@hex=dead,beef    → [b"\xde\xad", b"\xbe\xef"]
+//
+// ARRAY elements are comma-separated CSV fields, so an element containing a
+// comma can be double-quoted; an empty raw value is an empty array. The
+// "@base64" and "@hex" encodings apply to BYTES and ARRAY<BYTES>. STRUCT
+// types are not supported. A value is treated as raw only when the text
+// before the first "=" parses as a type, so ordinary expressions are
+// unaffected.
+func WithRawValues() Option {
+	return func(cfg *config) { cfg.rawValues = true }
+}
+
+// WithBarewordAsString makes values that are not GoogleSQL expressions, and
+// bare identifiers such as "hello", into STRING parameters holding the value
+// text verbatim. Values that are valid expressions (numbers, quoted strings,
+// TRUE, ...) keep their expression meaning. An expected type from
+// [WithExpectedTypes] is applied as for a string literal.
+func WithBarewordAsString() Option {
+	return func(cfg *config) { cfg.barewordAsString = true }
+}
+
+// parseRawValue parses value in the "TYPE=raw" syntax. ok is false when value
+// is not in that syntax.
+func parseRawValue(value string, cfg config) (gcv spanner.GenericColumnValue, ok bool, err error) {
+	typeText, raw, found := strings.Cut(value, "=")
+	if !found {
+		return spanner.GenericColumnValue{}, false, nil
+	}
+	var encoding string
+	if before, after, found := strings.Cut(typeText, "@"); found {
+		typeText, encoding = before, strings.ToLower(strings.TrimSpace(after))
+	}
+	typ, err := parseType(strings.TrimSpace(typeText))
+	if err != nil {
+		return spanner.GenericColumnValue{}, false, nil
+	}
+	t, err := memebridge.MemefishTypeToSpannerpbType(typ)
+	if err != nil {
+		return spanner.GenericColumnValue{}, false, nil
+	}
+
+	switch encoding {
+	case "", "base64", "hex":
+	default:
+		return spanner.GenericColumnValue{}, true, fmt.Errorf("cliparams: raw value %s: unknown encoding %q (want base64 or hex)", cfg.quote(value), encoding)
+	}
+	gcv, err = rawToGCV(t, raw, encoding, cfg)
+	if err != nil {
+		return spanner.GenericColumnValue{}, true, fmt.Errorf("cliparams: raw value %s: %w", cfg.quote(value), err)
+	}
+	return gcv, true, nil
+}
+
+func rawToGCV(t *sppb.Type, raw, encoding string, cfg config) (spanner.GenericColumnValue, error) {
+	switch t.GetCode() {
+	case sppb.TypeCode_STRUCT:
+		return spanner.GenericColumnValue{}, fmt.Errorf("raw syntax does not support STRUCT")
+	case sppb.TypeCode_ARRAY:
+		elemType := t.GetArrayElementType()
+		if code := elemType.GetCode(); code == sppb.TypeCode_ARRAY || code == sppb.TypeCode_STRUCT {
+			return spanner.GenericColumnValue{}, fmt.Errorf("raw syntax does not support ARRAY<%v>", code)
+		}
+		fields, err := splitRawArray(raw)
+		if err != nil {
+			return spanner.GenericColumnValue{}, cfg.cause(err)
+		}
+		elems := make([]spanner.GenericColumnValue, len(fields))
+		for i, field := range fields {
+			elem, err := rawScalarToGCV(elemType, field, encoding, cfg)
+			if err != nil {
+				return spanner.GenericColumnValue{}, fmt.Errorf("element %d: %w", i, err)
+			}
+			elems[i] = elem
+		}
+		return gcvctor.ArrayValueOf(elemType, elems...)
+	default:
+		return rawScalarToGCV(t, raw, encoding, cfg)
+	}
+}
+
+func rawScalarToGCV(t *sppb.Type, raw, encoding string, cfg config) (spanner.GenericColumnValue, error) {
+	if encoding == "" {
+		return memebridge.CastGCV(gcvctor.StringValue(raw), t, cfg.evalOptions()...)
+	}
+	if t.GetCode() != sppb.TypeCode_BYTES {
+		return spanner.GenericColumnValue{}, fmt.Errorf("encoding %q requires BYTES, not %v", encoding, t.GetCode())
+	}
+	var (
+		b   []byte
+		err error
+	)
+	switch encoding {
+	case "base64":
+		b, err = base64.StdEncoding.DecodeString(raw)
+	case "hex":
+		b, err = hex.DecodeString(raw)
+	}
+	if err != nil {
+		return spanner.GenericColumnValue{}, fmt.Errorf("decoding %s: %w", encoding, cfg.cause(err))
+	}
+	return gcvctor.BytesValue(b), nil
+}
+
+// splitRawArray splits raw ARRAY elements as one CSV record.
+func splitRawArray(raw string) ([]string, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	r := csv.NewReader(strings.NewReader(raw))
+	r.TrimLeadingSpace = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("splitting elements: %w", err)
+	}
+	if len(records) != 1 {
+		return nil, fmt.Errorf("splitting elements: want one line, got %d", len(records))
+	}
+	return records[0], nil
+}
+
+// isBareword reports whether expr is an identifier or dotted path, which
+// [WithBarewordAsString] treats as plain text.
+func isBareword(expr ast.Expr) bool {
+	switch expr.(type) {
+	case *ast.Ident, *ast.Path:
+		return true
+	}
+	return false
+}
+
+// barewordToGCV converts the verbatim value text to a STRING, coerced like a
+// string literal when an expected type is set.
+func barewordToGCV(value string, cfg config) (spanner.GenericColumnValue, error) {
+	gcv, err := memebridge.MemefishExprToGCV(&ast.StringLiteral{Value: value}, cfg.evalOptions(memebridge.WithExpectedType(cfg.expectedType))...)
+	if err != nil {
+		return spanner.GenericColumnValue{}, fmt.Errorf("cliparams: generating value for %s: %w", cfg.quote(value), err)
+	}
+	return gcv, nil
+}
@@ -0,0 +1,109 @@
+package cliparams_test
+
+import (
+	"testing"
+
+	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spantype/typector"
+	"github.com/apstndb/spanvalue/gcvctor"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/apstndb/memebridge"
+	"github.com/apstndb/memebridge/cliparams"
+)
+
+func TestParseValueRaw(t *testing.T) {
+	raw := []cliparams.Option{cliparams.WithRawValues()}
+	for _, tt := range []struct {
+		value string
+		want  spanner.GenericColumnValue
+	}{
+		{`STRING=O'Brien`, gcvctor.StringValue("O'Brien")},
+		{`STRING=a=b`, gcvctor.StringValue("a=b")},
+		{`DATE=2024-01-01`, mustParse(t, `CAST('2024-01-01' AS DATE)`)},
+		{`TIMESTAMP=2024-01-01 12:00:00`, mustParse(t, `CAST('2024-01-01 12:00:00' AS TIMESTAMP)`)},
+		{`NUMERIC=1.50`, mustParse(t, `CAST('1.50' AS NUMERIC)`)},
+		{`ARRAY<INT64>=1, 2,3`, mustParse(t, `[1, 2, 3]`)},
+		{`ARRAY<STRING>="a,b",c`, mustParse(t, `["a,b", "c"]`)},
+		{`ARRAY<INT64>=`, gcvctor.EmptyArrayOf(typector.Int64())},
+		{`BYTES=abc`, gcvctor.BytesValue([]byte("abc"))},
+		{`BYTES@base64=3q2+7w==`, gcvctor.BytesValue([]byte{0xde, 0xad, 0xbe, 0xef})},
+		{`ARRAY<BYTES>@hex=dead,beef`, mustParse(t, `[b"\xde\xad", b"\xbe\xef"]`)},
+		// Not raw syntax: ordinary expressions are unaffected.
+		{`1`, gcvctor.Int64Value(1)},
+		{`"x=y"`, gcvctor.StringValue("x=y")},
+	} {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := cliparams.ParseValue(tt.value, raw...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("ParseValue(%q) mismatch (-want +got):\n%s", tt.value, diff)
+			}
+		})
+	}
+}
+
+func TestParseValueRawErrors(t *testing.T) {
+	for _, value := range []string{
+		`INT64=x`,
+		`DATE=2024-13-01`,
+		`ARRAY<INT64>=1,x`,
+		`STRING@base64=AA==`,
+		`BYTES@base32=AA==`,
+		`BYTES@hex=zz`,
+		`STRUCT<x INT64>=1`,
+		`ARRAY<INT64>="1`,
+	} {
+		if got, err := cliparams.ParseValue(value, cliparams.WithRawValues()); err == nil {
+			t.Errorf("ParseValue(%q) = %v, want error", value, got)
+		}
+	}
+	if _, err := cliparams.ParseValue(`"unterminated`, cliparams.WithRawValues(), cliparams.WithBareTypeAsNull()); err == nil {
+		t.Error("unterminated string accepted")
+	}
+	if _, err := cliparams.ParseValue(`DATE=2024-01-01`); err == nil {
+		t.Error("raw syntax accepted without WithRawValues")
+	}
+}
+
+func TestParseValueBarewordAsString(t *testing.T) {
+	bareword := cliparams.WithBarewordAsString()
+	for _, tt := range []struct {
+		value string
+		want  spanner.GenericColumnValue
+	}{
+		{`hello`, gcvctor.StringValue("hello")},
+		{`a.b`, gcvctor.StringValue("a.b")},
+		{`hello world!`, gcvctor.StringValue("hello world!")},
+		{`O'Brien`, gcvctor.StringValue("O'Brien")},
+		{`42`, gcvctor.Int64Value(42)},
+		{`"quoted"`, gcvctor.StringValue("quoted")},
+	} {
+		got, err := cliparams.ParseValue(tt.value, bareword)
+		if err != nil {
+			t.Fatalf("ParseValue(%q): %v", tt.value, err)
+		}
+		if diff := cmp.Diff(tt.want, got, protocmp.Transform()); diff != "" {
+			t.Errorf("ParseValue(%q) mismatch (-want +got):\n%s", tt.value, diff)
+		}
+	}
+
+	got, err := cliparams.ParseMap(map[string]string{"d": "2024-01-01x"},
+		bareword, cliparams.WithExpectedTypes(map[string]*sppb.Type{"d": typector.Date()}))
+	if err == nil {
+		t.Errorf("ParseMap = %v, want DATE coercion error", got)
+	}
+}
+
+func mustParse(t *testing.T, expr string) spanner.GenericColumnValue {
+	t.Helper()
+	gcv, err := memebridge.ParseExprToGCV(expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gcv
+}
@@ -0,0 +1,76 @@
+package cliparams
+
+import (
+	"regexp"
+	"strconv"
+
+	"github.com/apstndb/memebridge"
+)
+
+// WithSensitiveNames marks the named parameters as sensitive. The values of
+// sensitive parameters never appear in errors (including [ParamError.Value]
+// and memebridge evaluation errors), [FormatParams] output or
+// [Flag.String]; they are replaced with [memebridge.RedactedPlaceholder]
+// while types are kept. Names are matched exactly. The option may be given
+// more than once; the sets accumulate. [ParseValue] has no parameter name,
+// so it is unaffected.
+func WithSensitiveNames(names ...string) Option {
+	return func(cfg *config) {
+		if cfg.sensitiveNames == nil {
+			cfg.sensitiveNames = make(map[string]bool, len(names))
+		}
+		for _, name := range names {
+			cfg.sensitiveNames[name] = true
+		}
+	}
+}
+
+// WithSensitivePattern marks parameters whose names match re as sensitive,
+// like [WithSensitiveNames]; for example
+// regexp.MustCompile(`(?i)token|secret|password`). The option may be given
+// more than once; a name matching any pattern is sensitive.
+func WithSensitivePattern(re *regexp.Regexp) Option {
+	return func(cfg *config) {
+		if re != nil {
+			cfg.sensitivePatterns = append(cfg.sensitivePatterns, re)
+		}
+	}
+}
+
+func (cfg config) isSensitive(name string) bool {
+	if cfg.sensitiveNames[name] {
+		return true
+	}
+	for _, re := range cfg.sensitivePatterns {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// quote returns value quoted for an error message, or the placeholder when
+// the parameter being parsed is sensitive.
+func (cfg config) quote(value string) string {
+	if cfg.redact {
+		return memebridge.RedactedPlaceholder
+	}
+	return strconv.Quote(value)
+}
+
+// cause hides the message of an underlying error, which may quote the
+// value, when the parameter being parsed is sensitive.
+func (cfg config) cause(err error) error {
+	if cfg.redact {
+		return memebridge.RedactError(err)
+	}
+	return err
+}
+
+// paramError builds the ParamError for name, redacting its value.
+func (cfg config) paramError(name, value string, err error) *ParamError {
+	if cfg.isSensitive(name) {
+		value = memebridge.RedactedPlaceholder
+	}
+	return &ParamError{Name: name, Value: value, Err: err}
+}
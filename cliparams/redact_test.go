@@ -0,0 +1,166 @@
+package cliparams_test
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/apstndb/memebridge"
+	"github.com/apstndb/memebridge/cliparams"
+)
+
+const secret = "hunter2"
+
+func TestSensitiveParamErrors(t *testing.T) {
+	sensitive := []cliparams.Option{
+		cliparams.WithSensitivePattern(regexp.MustCompile(`(?i)token`)),
+		cliparams.WithSensitiveNames("pw"),
+		cliparams.WithRawValues(),
+	}
+	tests := []struct {
+		desc string
+		arg  string
+	}{
+		{"syntax error", `api_token:"` + secret},
+		{"unsupported expression", `api_token:` + secret + `(1)`},
+		{"cast failure", `pw:CAST("` + secret + `" AS INT64)`},
+		{"raw value", `pw:DATE=` + secret},
+		{"raw encoding", `API_TOKEN:BYTES@hex=` + secret},
+		{"interval range", `api_token:INTERVAL '` + secret + `' YEAR TO MONTH`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if _, err := cliparams.ParseAssignments([]string{tt.arg}, cliparams.WithRawValues()); err == nil || !strings.Contains(err.Error(), secret) {
+				t.Fatalf("unredacted error = %v, want one containing the value", err)
+			}
+			_, err := cliparams.ParseAssignments([]string{tt.arg}, sensitive...)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if strings.Contains(err.Error(), secret) {
+				t.Errorf("error %q leaks the value", err)
+			}
+			var perr *cliparams.ParamError
+			if !errors.As(err, &perr) {
+				t.Fatalf("error %v is not a *ParamError", err)
+			}
+			if perr.Value != memebridge.RedactedPlaceholder {
+				t.Errorf("ParamError.Value = %q, want %q", perr.Value, memebridge.RedactedPlaceholder)
+			}
+		})
+	}
+
+	// Other parameters are reported as usual.
+	_, err := cliparams.ParseAssignments([]string{"other:" + secret + "(1)"}, sensitive...)
+	if err == nil || !strings.Contains(err.Error(), secret) {
+		t.Errorf("error for a non-sensitive parameter = %v, want one containing the value", err)
+	}
+}
+
+func TestSensitiveParamsFile(t *testing.T) {
+	_, err := cliparams.ReadJSON("p.json", strings.NewReader(`{"token": "`+secret+`(1)"}`), cliparams.WithSensitiveNames("token"))
+	if err == nil || strings.Contains(err.Error(), secret) {
+		t.Errorf("ReadJSON error = %v, want an error without the value", err)
+	}
+}
+
+func TestFormatParamsSensitive(t *testing.T) {
+	params, err := cliparams.ParseAssignments([]string{`token:"` + secret + `"`, "n:1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []cliparams.OutputFormat{cliparams.FormatTable, cliparams.FormatAssignments, cliparams.FormatSetParam, cliparams.FormatJSON} {
+		var buf bytes.Buffer
+		if err := cliparams.FormatParams(&buf, params, format, cliparams.WithSensitiveNames("token")); err != nil {
+			t.Fatal(err)
+		}
+		out := buf.String()
+		if strings.Contains(out, secret) {
+			t.Errorf("%v output leaks the value:\n%s", format, out)
+		}
+		if !strings.Contains(out, memebridge.RedactedPlaceholder) || !strings.Contains(out, "1") {
+			t.Errorf("%v output = %q, want the placeholder and the other value", format, out)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := cliparams.FormatParams(&buf, params, cliparams.FormatTable, cliparams.WithSensitiveNames("token")); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "STRING  "+memebridge.RedactedPlaceholder) {
+		t.Errorf("table output does not keep the type:\n%s", buf.String())
+	}
+}
+
+func TestSensitiveBareTypeAsNull(t *testing.T) {
+	// A bareword parses as a named type, which has no known kind.
+	arg := "token:" + secret
+	if _, err := cliparams.ParseAssignments([]string{arg}, cliparams.WithBareTypeAsNull()); err == nil || !strings.Contains(err.Error(), secret) {
+		t.Fatalf("unredacted error = %v, want one containing the value", err)
+	}
+	_, err := cliparams.ParseAssignments([]string{arg}, cliparams.WithBareTypeAsNull(), cliparams.WithSensitiveNames("token"))
+	if err == nil || strings.Contains(err.Error(), secret) {
+		t.Errorf("error = %v, want an error without the value", err)
+	}
+}
+
+func TestFlagSensitive(t *testing.T) {
+	params := cliparams.NewFlag(cliparams.WithSensitiveNames("token"))
+	if err := params.Set(`token:"` + secret + `"`); err != nil {
+		t.Fatal(err)
+	}
+	if err := params.Set("n:1"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := params.String(), "token:"+memebridge.RedactedPlaceholder+", n:1"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	err := params.Set(`token:"` + secret + `"`)
+	if !errors.Is(err, cliparams.ErrDuplicateParameter) || strings.Contains(err.Error(), secret) {
+		t.Errorf("duplicate error = %v, want ErrDuplicateParameter without the value", err)
+	}
+}
+
+func TestFlagSensitiveFlagSet(t *testing.T) {
+	params := cliparams.NewFlag(cliparams.WithSensitiveNames("token"))
+	var out bytes.Buffer
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(&out)
+	fs.Var(params, "param", "")
+	err := fs.Parse([]string{"--param", `token:CAST("` + secret + `" AS INT64)`})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	// The flag package quotes the raw argument, so callers must not print
+	// its error or output for sensitive parameters.
+	if !strings.Contains(err.Error(), secret) || !strings.Contains(out.String(), secret) {
+		t.Errorf("flag package error = %v, output = %q, want both to contain the value", err, out.String())
+	}
+	perr := params.Err()
+	var paramErr *cliparams.ParamError
+	if !errors.As(perr, &paramErr) || paramErr.Name != "token" {
+		t.Fatalf("Err() = %v, want a ParamError for token", perr)
+	}
+	if strings.Contains(perr.Error(), secret) {
+		t.Errorf("Err() = %q leaks the value", perr)
+	}
+}
+
+func TestReadJSONRejectsRedactedOutput(t *testing.T) {
+	params, err := cliparams.ParseAssignments([]string{`token:"` + secret + `"`, "n:1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := cliparams.FormatParams(&buf, params, cliparams.FormatJSON, cliparams.WithSensitiveNames("token")); err != nil {
+		t.Fatal(err)
+	}
+	_, err = cliparams.ReadJSON("params.json", &buf)
+	var paramErr *cliparams.ParamError
+	if !errors.Is(err, cliparams.ErrRedactedValue) || !errors.As(err, &paramErr) || paramErr.Name != "token" {
+		t.Errorf("ReadJSON of redacted output: err = %v, want ErrRedactedValue for token", err)
+	}
+}
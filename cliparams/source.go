@@ -0,0 +1,331 @@
+package cliparams
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spantype/typector"
+	"github.com/apstndb/spanvalue/gcvctor"
+
+	"github.com/apstndb/memebridge"
+)
+
+// WithFS enables values that read from files in fsys:
+//
+//	emb:@file:vec.json             JSON array → ARRAY<FLOAT64>
+//	emb:ARRAY<FLOAT32>@file:vec.json
+//	emb:@file:vec.npy              NumPy 1-D <f4/<f8/<i4/<i8 → ARRAY<FLOAT32|FLOAT64|INT64>
+//	img:@bytes:photo.png           file contents → BYTES
+//	doc:@json:payload.json         file contents → JSON
+//
+// An optional type before "@" declares the result type: for "@file:" it must
+// be an ARRAY whose element type each element is cast to, and for the other
+// sources the value is cast to it. Without a declared type, the expected type
+// from [WithExpectedTypes] is used. JSON arrays for "@file:" may contain
+// numbers, strings, booleans and nulls.
+//
+// Paths are [fs.FS] paths, so they are slash-separated and relative to the
+// root of fsys; os.DirFS(".") exposes the working directory. The path "-"
+// reads standard input (see [WithStdin]). Without WithFS, file sources are
+// rejected.
+func WithFS(fsys fs.FS) Option {
+	return func(cfg *config) { cfg.fsys = fsys }
+}
+
+// WithStdin enables the "@-" value, which reads r to EOF and parses the
+// content (without a trailing newline) as a parameter value, and the "-"
+// path of the file sources of [WithFS]. r is read at most once across all
+// values parsed with the returned option; a second read is an error.
+func WithStdin(r io.Reader) Option {
+	src := &stdinSource{r: r}
+	return func(cfg *config) { cfg.stdin = src }
+}
+
+type stdinSource struct {
+	mu       sync.Mutex
+	r        io.Reader
+	consumed bool
+}
+
+func (s *stdinSource) read() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.consumed {
+		return nil, errors.New("standard input already consumed by another parameter")
+	}
+	s.consumed = true
+	return io.ReadAll(s.r)
+}
+
+// valueSource is a parsed "[TYPE]@kind:path" or "[TYPE]@-" value.
+type valueSource struct {
+	value    string
+	typeText string
+	kind     string // "file", "bytes", "json", or "value" for "@-"
+	path     string // "-" for standard input
+}
+
+func parseSource(value string) (*valueSource, bool) {
+	typeText, rest, found := strings.Cut(value, "@")
+	if !found {
+		return nil, false
+	}
+	src := &valueSource{value: value, typeText: strings.TrimSpace(typeText)}
+	if rest == "-" {
+		src.kind, src.path = "value", "-"
+	} else {
+		kind, p, found := strings.Cut(rest, ":")
+		switch kind {
+		case "file", "bytes", "json":
+		default:
+			return nil, false
+		}
+		if !found || p == "" {
+			return nil, false
+		}
+		src.kind, src.path = kind, p
+	}
+	if src.typeText != "" {
+		if _, err := parseType(src.typeText); err != nil {
+			return nil, false
+		}
+	}
+	return src, true
+}
+
+func (s *valueSource) read(cfg config) (spanner.GenericColumnValue, error) {
+	gcv, err := s.readValue(cfg)
+	if err != nil {
+		return spanner.GenericColumnValue{}, fmt.Errorf("cliparams: reading %s: %w", cfg.quote(s.value), err)
+	}
+	return gcv, nil
+}
+
+func (s *valueSource) readValue(cfg config) (spanner.GenericColumnValue, error) {
+	declared := cfg.expectedType
+	if s.typeText != "" {
+		typ, err := parseType(s.typeText)
+		if err != nil {
+			return spanner.GenericColumnValue{}, err
+		}
+		if declared, err = memebridge.MemefishTypeToSpannerpbType(typ); err != nil {
+			return spanner.GenericColumnValue{}, err
+		}
+	}
+	data, err := s.open(cfg)
+	if err != nil {
+		return spanner.GenericColumnValue{}, err
+	}
+
+	var gcv spanner.GenericColumnValue
+	switch s.kind {
+	case "file":
+		gcv, err := fileArrayToGCV(s.path, data, declared, cfg)
+		return gcv, cfg.cause(err)
+	case "bytes":
+		gcv = gcvctor.BytesValue(data)
+	case "json":
+		if gcv, err = gcvctor.JSONStringValue(string(data)); err != nil {
+			return spanner.GenericColumnValue{}, cfg.cause(err)
+		}
+	case "value":
+		// Sources do not nest: the content is an ordinary value.
+		inner := cfg
+		inner.fsys, inner.stdin = nil, nil
+		text := strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+		if gcv, err = parseValue(text, inner); err != nil {
+			return spanner.GenericColumnValue{}, err
+		}
+	}
+	if declared == nil {
+		return gcv, nil
+	}
+	return memebridge.CastGCV(gcv, declared, cfg.evalOptions()...)
+}
+
+func (s *valueSource) open(cfg config) ([]byte, error) {
+	if s.path == "-" {
+		if cfg.stdin == nil {
+			return nil, errors.New("reading standard input requires cliparams.WithStdin")
+		}
+		return cfg.stdin.read()
+	}
+	if cfg.fsys == nil {
+		return nil, errors.New("reading files requires cliparams.WithFS")
+	}
+	return fs.ReadFile(cfg.fsys, path.Clean(s.path))
+}
+
+// fileArrayToGCV converts a JSON array or, for ".npy" files, a NumPy array
+// to an ARRAY value. declared, if set, must be an ARRAY type.
+func fileArrayToGCV(name string, data []byte, declared *sppb.Type, cfg config) (spanner.GenericColumnValue, error) {
+	var elemType *sppb.Type
+	if declared != nil {
+		if declared.GetCode() != sppb.TypeCode_ARRAY {
+			return spanner.GenericColumnValue{}, fmt.Errorf("@file: requires an ARRAY type, not %v", declared.GetCode())
+		}
+		elemType = declared.GetArrayElementType()
+	}
+
+	var (
+		elems []spanner.GenericColumnValue
+		err   error
+	)
+	if strings.EqualFold(path.Ext(name), ".npy") {
+		var npyType *sppb.Type
+		elems, npyType, err = readNPY(data)
+		if err != nil {
+			return spanner.GenericColumnValue{}, err
+		}
+		if elemType == nil {
+			elemType = npyType
+		}
+	} else {
+		if elemType == nil {
+			elemType = typector.Float64()
+		}
+		elems, err = readJSONArray(data)
+		if err != nil {
+			return spanner.GenericColumnValue{}, err
+		}
+	}
+	for i, elem := range elems {
+		if elems[i], err = memebridge.CastGCV(elem, elemType, cfg.evalOptions()...); err != nil {
+			return spanner.GenericColumnValue{}, fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+	return gcvctor.ArrayValueOf(elemType, elems...)
+}
+
+// readJSONArray decodes a JSON array of scalars. Numbers become STRING
+// values holding the number text, so that the final cast parses them with
+// the precision of the element type.
+func readJSONArray(data []byte) ([]spanner.GenericColumnValue, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var values []any
+	if err := dec.Decode(&values); err != nil {
+		return nil, fmt.Errorf("decoding JSON array: %w", err)
+	}
+	if dec.More() {
+		return nil, errors.New("decoding JSON array: trailing data")
+	}
+	elems := make([]spanner.GenericColumnValue, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case nil:
+			elems[i] = gcvctor.NullFromCode(sppb.TypeCode_STRING)
+		case json.Number:
+			elems[i] = gcvctor.StringValue(v.String())
+		case string:
+			elems[i] = gcvctor.StringValue(v)
+		case bool:
+			elems[i] = gcvctor.BoolValue(v)
+		default:
+			return nil, fmt.Errorf("element %d: unsupported JSON value %T", i, v)
+		}
+	}
+	return elems, nil
+}
+
+var (
+	npyMagic   = []byte("\x93NUMPY")
+	npyDescrRE = regexp.MustCompile(`'descr':\s*'([^']*)'`)
+	npyShapeRE = regexp.MustCompile(`'shape':\s*\(([^)]*)\)`)
+)
+
+// readNPY decodes a 1-D little-endian float32, float64, int32 or int64
+// NumPy .npy array.
+func readNPY(data []byte) ([]spanner.GenericColumnValue, *sppb.Type, error) {
+	if !bytes.HasPrefix(data, npyMagic) || len(data) < 10 {
+		return nil, nil, errors.New("not a .npy file")
+	}
+	var header []byte
+	switch major := data[6]; major {
+	case 1:
+		n := int(binary.LittleEndian.Uint16(data[8:10]))
+		if len(data) < 10+n {
+			return nil, nil, errors.New("truncated .npy header")
+		}
+		header, data = data[10:10+n], data[10+n:]
+	case 2, 3:
+		if len(data) < 12 {
+			return nil, nil, errors.New("truncated .npy header")
+		}
+		n := int(binary.LittleEndian.Uint32(data[8:12]))
+		if len(data) < 12+n {
+			return nil, nil, errors.New("truncated .npy header")
+		}
+		header, data = data[12:12+n], data[12+n:]
+	default:
+		return nil, nil, fmt.Errorf("unsupported .npy version %d", major)
+	}
+
+	descr := npyDescrRE.FindSubmatch(header)
+	shape := npyShapeRE.FindSubmatch(header)
+	if descr == nil || shape == nil {
+		return nil, nil, fmt.Errorf("malformed .npy header %q", header)
+	}
+	// A 1-D shape is written as "(N,)".
+	dims := strings.Split(strings.TrimSuffix(strings.TrimSpace(string(shape[1])), ","), ",")
+	if len(dims) != 1 || dims[0] == "" {
+		return nil, nil, fmt.Errorf(".npy shape (%s) is not 1-D", shape[1])
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(dims[0]))
+	if err != nil {
+		return nil, nil, fmt.Errorf(".npy shape (%s): %w", shape[1], err)
+	}
+
+	var (
+		size   int
+		typ    *sppb.Type
+		decode func(b []byte) spanner.GenericColumnValue
+	)
+	switch string(descr[1]) {
+	case "<f4", "=f4":
+		size, typ = 4, typector.Float32()
+		decode = func(b []byte) spanner.GenericColumnValue {
+			return gcvctor.Float32Value(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+		}
+	case "<f8", "=f8":
+		size, typ = 8, typector.Float64()
+		decode = func(b []byte) spanner.GenericColumnValue {
+			return gcvctor.Float64Value(math.Float64frombits(binary.LittleEndian.Uint64(b)))
+		}
+	case "<i4", "=i4":
+		size, typ = 4, typector.Int64()
+		decode = func(b []byte) spanner.GenericColumnValue {
+			return gcvctor.Int64Value(int64(int32(binary.LittleEndian.Uint32(b))))
+		}
+	case "<i8", "=i8":
+		size, typ = 8, typector.Int64()
+		decode = func(b []byte) spanner.GenericColumnValue {
+			return gcvctor.Int64Value(int64(binary.LittleEndian.Uint64(b)))
+		}
+	default:
+		return nil, nil, fmt.Errorf("unsupported .npy dtype %q (want <f4, <f8, <i4 or <i8)", descr[1])
+	}
+	// Check count against the data before multiplying, as a crafted shape
+	// can overflow count*size.
+	if count < 0 || count > len(data)/size || len(data) != count*size {
+		return nil, nil, fmt.Errorf(".npy data has %d bytes, want %d elements of %d bytes", len(data), count, size)
+	}
+	elems := make([]spanner.GenericColumnValue, count)
+	for i := range elems {
+		elems[i] = decode(data[i*size : (i+1)*size])
+	}
+	return elems, typ, nil
+}
@@ -0,0 +1,144 @@
+package cliparams_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spantype/typector"
+	"github.com/apstndb/spanvalue/gcvctor"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/apstndb/memebridge/cliparams"
+)
+
+// npyFile encodes a 1-D .npy (format version 1.0) file.
+func npyFile(descr string, values any) []byte {
+	var body bytes.Buffer
+	if err := binary.Write(&body, binary.LittleEndian, values); err != nil {
+		panic(err)
+	}
+	var n int
+	switch v := values.(type) {
+	case []float32:
+		n = len(v)
+	case []float64:
+		n = len(v)
+	case []int32:
+		n = len(v)
+	}
+	return npyFileWithShape(descr, fmt.Sprintf("(%d,)", n), body.Bytes())
+}
+
+// npyFileWithShape encodes a .npy file with the given shape and raw data,
+// which need not agree.
+func npyFileWithShape(descr, shape string, data []byte) []byte {
+	header := fmt.Sprintf("{'descr': '%s', 'fortran_order': False, 'shape': %s, }", descr, shape)
+	header += strings.Repeat(" ", 63-(10+len(header))%64) + "\n"
+	var buf bytes.Buffer
+	buf.WriteString("\x93NUMPY\x01\x00")
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(len(header)))
+	buf.WriteString(header)
+	buf.Write(data)
+	return buf.Bytes()
+}
+
+var testSourceFS = fstest.MapFS{
+	"vec.json":     {Data: []byte(`[0.5, 1, -2.25]`)},
+	"mixed.json":   {Data: []byte(`["1", 2, null]`)},
+	"bad.json":     {Data: []byte(`[1, {"a": 1}]`)},
+	"dir/f32.npy":  {Data: npyFile("<f4", []float32{0.5, 1.5})},
+	"f64.npy":      {Data: npyFile("<f8", []float64{0.25})},
+	"i32.npy":      {Data: npyFile("<i4", []int32{7, -1})},
+	"big.npy":      {Data: npyFile(">f4", []float32{1})},
+	"huge.npy":     {Data: npyFileWithShape("<f8", "(2305843009213693952,)", nil)},
+	"negative.npy": {Data: npyFileWithShape("<f8", "(-1,)", nil)},
+	"short.npy":    {Data: npyFileWithShape("<f8", "(2,)", make([]byte, 12))},
+	"photo.png":    {Data: []byte{0x89, 'P', 'N', 'G'}},
+	"payload.json": {Data: []byte(`{"a": [1, 2]}`)},
+}
+
+func TestParseValueSources(t *testing.T) {
+	opts := []cliparams.Option{cliparams.WithFS(testSourceFS)}
+	for _, tt := range []struct {
+		value string
+		want  spanner.GenericColumnValue
+	}{
+		{`@file:vec.json`, gcvctor.MustArrayValueOf(typector.Float64(),
+			gcvctor.Float64Value(0.5), gcvctor.Float64Value(1), gcvctor.Float64Value(-2.25))},
+		{`ARRAY<FLOAT32>@file:./vec.json`, gcvctor.MustArrayValueOf(typector.Float32(),
+			gcvctor.Float32Value(0.5), gcvctor.Float32Value(1), gcvctor.Float32Value(-2.25))},
+		{`ARRAY<INT64>@file:mixed.json`, gcvctor.MustArrayValueOf(typector.Int64(),
+			gcvctor.Int64Value(1), gcvctor.Int64Value(2), gcvctor.NullOf(typector.Int64()))},
+		{`@file:dir/f32.npy`, gcvctor.MustArrayValueOf(typector.Float32(),
+			gcvctor.Float32Value(0.5), gcvctor.Float32Value(1.5))},
+		{`@file:f64.npy`, gcvctor.MustArrayValueOf(typector.Float64(), gcvctor.Float64Value(0.25))},
+		{`ARRAY<FLOAT64>@file:i32.npy`, gcvctor.MustArrayValueOf(typector.Float64(),
+			gcvctor.Float64Value(7), gcvctor.Float64Value(-1))},
+		{`@bytes:photo.png`, gcvctor.BytesValue([]byte{0x89, 'P', 'N', 'G'})},
+		{`@json:payload.json`, gcvctor.StringBasedValueFromCode(sppb.TypeCode_JSON, `{"a": [1, 2]}`)},
+		// Not sources.
+		{`"user@file:x"`, gcvctor.StringValue("user@file:x")},
+	} {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := cliparams.ParseValue(tt.value, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("ParseValue(%q) mismatch (-want +got):\n%s", tt.value, diff)
+			}
+		})
+	}
+}
+
+func TestParseValueSourceErrors(t *testing.T) {
+	for _, value := range []string{
+		`@file:missing.json`,
+		`@file:bad.json`,
+		`@file:big.npy`,
+		`@file:huge.npy`,
+		`@file:negative.npy`,
+		`@file:short.npy`,
+		`INT64@file:vec.json`,
+		`ARRAY<INT64>@file:vec.json`,
+		`@json:photo.png`,
+		`@file:/etc/passwd`,
+	} {
+		if got, err := cliparams.ParseValue(value, cliparams.WithFS(testSourceFS)); err == nil {
+			t.Errorf("ParseValue(%q) = %v, want error", value, got)
+		}
+	}
+	if _, err := cliparams.ParseValue(`@file:vec.json`); err == nil || !strings.Contains(err.Error(), "WithFS") {
+		t.Errorf("want error mentioning WithFS, got %v", err)
+	}
+}
+
+func TestParseValueStdin(t *testing.T) {
+	stdin := cliparams.WithStdin(strings.NewReader("DATE '2024-01-01'\n"))
+	got, err := cliparams.ParseAssignments([]string{"q:@-"}, stdin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := got["q"].Type.GetCode(); code != sppb.TypeCode_DATE {
+		t.Errorf("q type = %v, want DATE", code)
+	}
+	if _, err := cliparams.ParseAssignments([]string{"again:@-"}, stdin); err == nil {
+		t.Error("second read of standard input succeeded, want error")
+	}
+
+	got, err = cliparams.ParseAssignments([]string{"b:@bytes:-"},
+		cliparams.WithFS(testSourceFS), cliparams.WithStdin(strings.NewReader("raw\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(gcvctor.BytesValue([]byte("raw\n")), got["b"], protocmp.Transform()); diff != "" {
+		t.Errorf("@bytes:- mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"cloud.google.com/go/spanner"
+	"github.com/cloudspannerecosystem/memefish"
+
+	"github.com/apstndb/memebridge"
+	"github.com/apstndb/memebridge/cliparams"
+)
+
+// batchRequest is one JSON Lines request of the batch command.
+type batchRequest struct {
+	// ID is echoed in the response; it may be any JSON value.
+	ID   json.RawMessage `json:"id"`
+	Expr string          `json:"expr"`
+	// ExpectedType is a GoogleSQL type that the value is coerced to, as by
+	// memebridge.WithExpectedType.
+	ExpectedType string `json:"expected_type,omitempty"`
+	// Params binds @name references, in the form read by cliparams.ReadJSON.
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// batchResponse is the response to one batchRequest. Exactly one of Value
+// and Error is set.
+type batchResponse struct {
+	ID    json.RawMessage `json:"id"`
+	Type  json.RawMessage `json:"type,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+	Error *batchError     `json:"error,omitempty"`
+}
+
+// batchError is a structured evaluation error.
+type batchError struct {
+	// Kind classifies the error: invalid_request, syntax, param,
+	// expected_type, undefined_parameter, unsupported_expr,
+	// unsupported_type, unsupported_cast, cannot_infer_array_element_type
+	// or eval.
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	// File, Line and Column locate a syntax error; Line and Column are
+	// 1-origin.
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+	// Param names the parameter that failed for kind param.
+	Param string `json:"param,omitempty"`
+}
+
+// batchJob is a request in flight; done is closed when resp is set.
+type batchJob struct {
+	line int
+	raw  []byte
+	resp batchResponse
+	done chan struct{}
+}
+
+// runBatch reads JSON Lines requests from standard input until it ends and
+// writes one JSON Lines response per request, in input order. Requests are
+// evaluated by e.workers goroutines, with at most that many more read ahead.
+func runBatch(e *env, args []string) error {
+	if len(args) > 0 {
+		return errors.New("batch: unexpected arguments; requests are read from standard input")
+	}
+	workers := max(e.workers, 1)
+	jobs := make(chan *batchJob, workers)
+	pending := make(chan *batchJob, workers)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				job.resp = e.evalBatchRequest(job.line, job.raw)
+				close(job.done)
+			}
+		}()
+	}
+
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(jobs)
+		defer close(pending)
+		r := bufio.NewReader(e.stdin)
+		for line := 1; ; line++ {
+			raw, err := r.ReadBytes('\n')
+			if raw = bytes.TrimSpace(raw); len(raw) > 0 {
+				job := &batchJob{line: line, raw: raw, done: make(chan struct{})}
+				pending <- job
+				jobs <- job
+			}
+			if err != nil {
+				if err == io.EOF {
+					err = nil
+				}
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	var writeErr error
+	for job := range pending {
+		<-job.done
+		if writeErr == nil {
+			writeErr = e.printJSON(job.resp)
+		}
+	}
+	wg.Wait()
+	if err := <-readErr; err != nil {
+		return fmt.Errorf("reading standard input: %w", err)
+	}
+	return writeErr
+}
+
+// evalBatchRequest evaluates the request on the given input line. The
+// expression is parsed with the request ID as its filename, or with
+// "request<line>" when the ID is not a string.
+func (e *env) evalBatchRequest(line int, raw []byte) batchResponse {
+	var req batchRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return batchResponse{ID: json.RawMessage("null"), Error: &batchError{
+			Kind:    "invalid_request",
+			Message: fmt.Sprintf("line %d: %v", line, err),
+		}}
+	}
+	resp := batchResponse{ID: req.ID}
+	if resp.ID == nil {
+		resp.ID = json.RawMessage("null")
+	}
+	filename := fmt.Sprintf("request%d", line)
+	var id string
+	if json.Unmarshal(req.ID, &id) == nil && id != "" {
+		filename = id
+	}
+
+	gcv, err := e.evalBatchExpr(filename, &req)
+	if err != nil {
+		resp.Error = newBatchError(err)
+		return resp
+	}
+	if resp.Type, err = protoJSON(gcv.Type); err == nil {
+		resp.Value, err = protoJSON(gcv.Value)
+	}
+	if err != nil {
+		resp.Type, resp.Error = nil, newBatchError(err)
+	}
+	return resp
+}
+
+func (e *env) evalBatchExpr(filename string, req *batchRequest) (spanner.GenericColumnValue, error) {
+	opts := e.evalOpts[:len(e.evalOpts):len(e.evalOpts)]
+	if req.ExpectedType != "" {
+		typ, err := memefish.ParseType(filename+".expected_type", req.ExpectedType)
+		if err != nil {
+			return spanner.GenericColumnValue{}, err
+		}
+		t, err := memebridge.MemefishTypeToSpannerpbType(typ)
+		if err != nil {
+			return spanner.GenericColumnValue{}, &expectedTypeError{err}
+		}
+		opts = append(opts, memebridge.WithExpectedType(t))
+	}
+	// Bind parameters even when there are none, so that a reference reports
+	// an undefined parameter rather than an unsupported expression.
+	params := map[string]spanner.GenericColumnValue{}
+	if len(req.Params) > 0 && !bytes.Equal(req.Params, []byte("null")) {
+		var err error
+		params, err = cliparams.ReadJSON(filename+".params", bytes.NewReader(req.Params),
+			cliparams.WithBareTypeAsNull(), cliparams.WithEvalOptions(e.evalOpts...))
+		if err != nil {
+			return spanner.GenericColumnValue{}, err
+		}
+	}
+	opts = append(opts, memebridge.WithParams(params))
+	return memebridge.ParseExprFile(filename, req.Expr, opts...)
+}
+
+// expectedTypeError marks an expected_type that has no Spanner type.
+type expectedTypeError struct{ err error }
+
+func (e *expectedTypeError) Error() string { return "expected_type: " + e.err.Error() }
+func (e *expectedTypeError) Unwrap() error { return e.err }
+
+func newBatchError(err error) *batchError {
+	be := &batchError{Kind: "eval", Message: err.Error()}
+	var (
+		multi      memefish.MultiError
+		single     *memefish.Error
+		paramErr   *cliparams.ParamError
+		expectedTy *expectedTypeError
+	)
+	switch {
+	case errors.As(err, &paramErr):
+		be.Kind, be.Param = "param", paramErr.Name
+	case errors.As(err, &multi) || errors.As(err, &single):
+		be.Kind = "syntax"
+		if len(multi) > 0 {
+			single = multi[0]
+		}
+		if single != nil && single.Position != nil {
+			pos := single.Position
+			be.File, be.Line, be.Column = pos.FilePath, pos.Line+1, pos.Column+1
+		}
+	case errors.As(err, &expectedTy):
+		be.Kind = "expected_type"
+	case errors.Is(err, memebridge.ErrUndefinedParameter):
+		be.Kind = "undefined_parameter"
+	case errors.Is(err, memebridge.ErrUnsupportedExpr):
+		be.Kind = "unsupported_expr"
+	case errors.Is(err, memebridge.ErrUnsupportedType):
+		be.Kind = "unsupported_type"
+	case errors.Is(err, memebridge.ErrUnsupportedCast):
+		be.Kind = "unsupported_cast"
+	case errors.Is(err, memebridge.ErrCannotInferArrayElementType):
+		be.Kind = "cannot_infer_array_element_type"
+	}
+	return be
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRunBatch(t *testing.T) {
+	stdin := `{"id": 1, "expr": "[@d, '2024-01-02']", "expected_type": "ARRAY<DATE>", "params": {"d": "DATE '2024-01-01'"}}
+
+{"id": "typed", "expr": "@n", "params": {"n": {"type": {"code": "INT64"}, "value": "7"}}}
+not json
+{"id": "q4", "expr": "1 +"}
+{"id": 5, "expr": "FN(1)"}
+{"id": 6, "expr": "@missing"}
+{"id": 7, "expr": "@x", "params": {"x": "[1, 'a']"}}
+{"id": 8, "expr": "1", "expected_type": "ARRAY<"}
+{"id": 9, "expr": "CAST('a' AS INT64)"}
+{"expr": "[]"}
+`
+	want := `{"id":1,"type":{"code":"ARRAY","arrayElementType":{"code":"DATE"}},"value":["2024-01-01","2024-01-02"]}
+{"id":"typed","type":{"code":"INT64"},"value":"7"}
+{"id":null,"error":{"kind":"invalid_request","message":"line 4: invalid character 'o' in literal null (expecting 'u')"}}
+{"id":"q4","error":{"kind":"syntax","message":"syntax error: q4:1:4: unexpected token: <eof>","file":"q4","line":1,"column":4}}
+{"id":5,"error":{"kind":"unsupported_expr","message":"unsupported expression: FN(1)"}}
+{"id":6,"error":{"kind":"undefined_parameter","message":"undefined query parameter: @missing"}}
+{"id":7,"error":{"kind":"param","message":"cliparams: request8.params:1: parameter \"x\": cliparams: generating value for \"[1, 'a']\": cannot infer element type for array literal without explicit type","param":"x"}}
+{"id":8,"error":{"kind":"syntax","message":"syntax error: request9.expected_type:1:7: expected token: <ident>, ARRAY, STRUCT, but: <eof> (and 1 other error)","file":"request9.expected_type","line":1,"column":7}}
+{"id":9,"error":{"kind":"eval","message":"strconv.ParseInt: parsing \"a\": invalid syntax"}}
+{"id":null,"error":{"kind":"cannot_infer_array_element_type","message":"cannot infer element type for array literal without explicit type"}}
+`
+	var stdout bytes.Buffer
+	if err := run([]string{"batch", "-workers=4"}, strings.NewReader(stdin), &stdout, io.Discard, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := stdout.String(); got != want {
+		t.Errorf("output mismatch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRunBatchKeepsOrder(t *testing.T) {
+	var stdin, want strings.Builder
+	for i := range 200 {
+		if i%3 == 0 {
+			fmt.Fprintf(&stdin, `{"id": %d, "expr": "FN(%d)"}`+"\n", i, i)
+			fmt.Fprintf(&want, `{"id":%d,"error":{"kind":"unsupported_expr","message":"unsupported expression: FN(%d)"}}`+"\n", i, i)
+			continue
+		}
+		fmt.Fprintf(&stdin, `{"id": %d, "expr": "[%d]"}`+"\n", i, i)
+		fmt.Fprintf(&want, `{"id":%d,"type":{"code":"ARRAY","arrayElementType":{"code":"INT64"}},"value":["%d"]}`+"\n", i, i)
+	}
+	var stdout bytes.Buffer
+	if err := run([]string{"batch", "-workers=8"}, strings.NewReader(stdin.String()), &stdout, io.Discard, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := stdout.String(); got != want.String() {
+		t.Errorf("output mismatch\ngot:\n%s\nwant:\n%s", got, want.String())
+	}
+}
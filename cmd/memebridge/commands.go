@@ -0,0 +1,230 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"cloud.google.com/go/spanner"
+	"github.com/cloudspannerecosystem/memefish"
+	"github.com/cloudspannerecosystem/memefish/ast"
+	"github.com/cloudspannerecosystem/memefish/token"
+
+	"github.com/apstndb/memebridge"
+	"github.com/apstndb/memebridge/cliparams"
+)
+
+// errCheckFailed is returned by check after reporting unsupported
+// constructs, so that the command exits with status 1 without another
+// message.
+var errCheckFailed = errors.New("unsupported constructs found")
+
+func runEval(e *env, args []string) error {
+	ins, err := e.inputs(args)
+	if err != nil {
+		return err
+	}
+	for _, in := range ins {
+		gcv, err := memebridge.ParseExprFile(in.name, in.text, e.evalOpts...)
+		if err != nil {
+			return err
+		}
+		if err := e.printValue(gcv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runType(e *env, args []string) error {
+	ins, err := e.inputs(args)
+	if err != nil {
+		return err
+	}
+	for _, in := range ins {
+		typ, err := memefish.ParseType(in.name, in.text)
+		if err != nil {
+			return err
+		}
+		t, err := memebridge.MemefishTypeToSpannerpbType(typ)
+		if err != nil {
+			return fmt.Errorf("%s: %w", in.name, err)
+		}
+		if err := e.printType(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runCast(e *env, args []string) error {
+	if len(args) == 0 {
+		return errors.New("cast: missing TYPE")
+	}
+	typ, err := memefish.ParseType("TYPE", args[0])
+	if err != nil {
+		return err
+	}
+	t, err := memebridge.MemefishTypeToSpannerpbType(typ)
+	if err != nil {
+		return fmt.Errorf("TYPE: %w", err)
+	}
+	ins, err := e.inputs(args[1:])
+	if err != nil {
+		return err
+	}
+	for _, in := range ins {
+		gcv, err := memebridge.ParseExprFile(in.name, in.text, e.evalOpts...)
+		if err != nil {
+			return err
+		}
+		if gcv, err = memebridge.CastGCV(gcv, t, e.evalOpts...); err != nil {
+			return fmt.Errorf("%s: %w", in.name, err)
+		}
+		if err := e.printValue(gcv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runParams(e *env, args []string) error {
+	opts := []cliparams.Option{
+		cliparams.WithBareTypeAsNull(),
+		cliparams.WithRawValues(),
+		cliparams.WithFS(e.fsys),
+		cliparams.WithEvalOptions(e.evalOpts...),
+	}
+	var (
+		params map[string]spanner.GenericColumnValue
+		err    error
+	)
+	if len(args) > 0 {
+		params, err = cliparams.ParseAssignments(args, append(opts, cliparams.WithStdin(e.stdin))...)
+	} else {
+		params, err = cliparams.ReadParamsFile("-", e.stdin, opts...)
+	}
+	if err != nil {
+		return err
+	}
+	format := cliparams.FormatTable
+	switch e.format {
+	case formatJSON:
+		format = cliparams.FormatJSON
+	case formatSQL:
+		format = cliparams.FormatSetParam
+	}
+	return cliparams.FormatParams(e.stdout, params, format)
+}
+
+// checkIssue is a construct that memebridge cannot evaluate.
+type checkIssue struct {
+	Position string `json:"position"`
+	Expr     string `json:"expr"`
+	Error    string `json:"error"`
+}
+
+func runCheck(e *env, args []string) error {
+	ins, err := e.inputs(args)
+	if err != nil {
+		return err
+	}
+	failed := false
+	for _, in := range ins {
+		issues, err := checkExpr(in, e.evalOpts)
+		if err != nil {
+			return err
+		}
+		failed = failed || len(issues) > 0
+		if e.format == formatJSON {
+			if issues == nil {
+				issues = []checkIssue{}
+			}
+			err = e.printJSON(struct {
+				Input     string       `json:"input"`
+				Supported bool         `json:"supported"`
+				Issues    []checkIssue `json:"issues"`
+			}{in.name, len(issues) == 0, issues})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if len(issues) == 0 {
+			fmt.Fprintf(e.stdout, "%s: ok\n", in.name)
+		}
+		for _, issue := range issues {
+			fmt.Fprintf(e.stdout, "%s: %s: %s\n", issue.Position, issue.Expr, issue.Error)
+		}
+	}
+	if failed {
+		return errCheckFailed
+	}
+	return nil
+}
+
+// checkExpr evaluates every subexpression of in and reports the innermost
+// ones that fail as unsupported, or the whole expression when it fails for
+// another reason such as an out-of-range value. Identifiers and paths are
+// reported through the expression that contains them, since they are also
+// function and field names.
+func checkExpr(in input, opts []memebridge.EvalOption) ([]checkIssue, error) {
+	root, err := memefish.ParseExpr(in.name, in.text)
+	if err != nil {
+		return nil, err
+	}
+	type failure struct {
+		expr ast.Expr
+		err  error
+	}
+	var failures []failure
+	ast.Inspect(root, func(n ast.Node) bool {
+		expr, ok := n.(ast.Expr)
+		if !ok {
+			return true
+		}
+		if expr != root {
+			switch expr.(type) {
+			case *ast.Ident, *ast.Path:
+				return false
+			case *ast.ParenExpr:
+				return true
+			}
+		}
+		_, err := memebridge.MemefishExprToGCV(expr, opts...)
+		if err == nil {
+			return false
+		}
+		if expr == root || isUnsupported(err) {
+			failures = append(failures, failure{expr, err})
+		}
+		return true
+	})
+
+	file := &token.File{FilePath: in.name, Buffer: in.text}
+	var issues []checkIssue
+	for i, f := range failures {
+		// Failures are in preorder, so a later failure within the span of f
+		// is nested in it.
+		innermost := true
+		for _, g := range failures[i+1:] {
+			if f.expr.Pos() <= g.expr.Pos() && g.expr.End() <= f.expr.End() {
+				innermost = false
+				break
+			}
+		}
+		if innermost {
+			issues = append(issues, checkIssue{
+				Position: file.Position(f.expr.Pos(), f.expr.End()).String(),
+				Expr:     f.expr.SQL(),
+				Error:    f.err.Error(),
+			})
+		}
+	}
+	return issues, nil
+}
+
+func isUnsupported(err error) bool {
+	return errors.Is(err, memebridge.ErrUnsupportedExpr) ||
+		errors.Is(err, memebridge.ErrUnsupportedType) ||
+		errors.Is(err, memebridge.ErrUnsupportedCast)
+}
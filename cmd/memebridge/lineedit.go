@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// errInterrupted is returned by readLine when the user presses Ctrl-C.
+var errInterrupted = errors.New("interrupted")
+
+// lineEditor reads lines from a terminal with Emacs-style editing keys,
+// history and tab completion. When its input is not a terminal it reads
+// plain lines without a prompt.
+type lineEditor struct {
+	in  *bufio.Reader
+	out io.Writer
+	// fd is the terminal file descriptor, or -1 when input is not a
+	// terminal.
+	fd      int
+	history []string
+	// complete returns the completions of the word ending at byte offset
+	// pos of line, and the offset where that word starts.
+	complete func(line string, pos int) (start int, candidates []string)
+
+	// State of the line being edited.
+	prompt  string
+	line    []rune
+	pos     int
+	histPos int
+	saved   []rune // the unfinished line while browsing history
+}
+
+func newLineEditor(in io.Reader, out io.Writer) *lineEditor {
+	ed := &lineEditor{in: bufio.NewReader(in), out: out, fd: -1}
+	if f, ok := in.(*os.File); ok {
+		if restore, err := makeRaw(f.Fd()); err == nil {
+			restore()
+			ed.fd = int(f.Fd())
+		}
+	}
+	return ed
+}
+
+// readLine reads one line, returning io.EOF at the end of input or on Ctrl-D
+// at an empty line, and errInterrupted on Ctrl-C.
+func (ed *lineEditor) readLine(prompt string) (string, error) {
+	if ed.fd < 0 {
+		line, err := ed.in.ReadString('\n')
+		if err == io.EOF && line != "" {
+			err = nil
+		}
+		return strings.TrimRight(line, "\r\n"), err
+	}
+	restore, err := makeRaw(uintptr(ed.fd))
+	if err != nil {
+		return "", err
+	}
+	defer restore()
+	return ed.edit(prompt)
+}
+
+// edit runs the editing loop on raw key input.
+func (ed *lineEditor) edit(prompt string) (string, error) {
+	ed.prompt, ed.line, ed.pos = prompt, nil, 0
+	ed.histPos, ed.saved = len(ed.history), nil
+	ed.refresh()
+	for {
+		r, _, err := ed.in.ReadRune()
+		if err != nil {
+			if err == io.EOF && len(ed.line) > 0 {
+				break
+			}
+			return "", err
+		}
+		switch r {
+		case '\r', '\n':
+			return ed.finish(), nil
+		case 1: // Ctrl-A
+			ed.pos = 0
+		case 2: // Ctrl-B
+			ed.pos = max(ed.pos-1, 0)
+		case 3: // Ctrl-C
+			fmt.Fprint(ed.out, "^C\r\n")
+			return "", errInterrupted
+		case 4: // Ctrl-D
+			if len(ed.line) == 0 {
+				fmt.Fprint(ed.out, "\r\n")
+				return "", io.EOF
+			}
+			ed.deleteRange(ed.pos, ed.pos+1)
+		case 5: // Ctrl-E
+			ed.pos = len(ed.line)
+		case 6: // Ctrl-F
+			ed.pos = min(ed.pos+1, len(ed.line))
+		case 8, 127: // Ctrl-H, Backspace
+			ed.deleteRange(ed.pos-1, ed.pos)
+		case '\t':
+			ed.completeWord()
+		case 11: // Ctrl-K
+			ed.deleteRange(ed.pos, len(ed.line))
+		case 14: // Ctrl-N
+			ed.browseHistory(+1)
+		case 16: // Ctrl-P
+			ed.browseHistory(-1)
+		case 21: // Ctrl-U
+			ed.deleteRange(0, ed.pos)
+		case 23: // Ctrl-W
+			start := ed.pos
+			for start > 0 && unicode.IsSpace(ed.line[start-1]) {
+				start--
+			}
+			for start > 0 && !unicode.IsSpace(ed.line[start-1]) {
+				start--
+			}
+			ed.deleteRange(start, ed.pos)
+		case 27: // ESC
+			ed.escape()
+		default:
+			if unicode.IsPrint(r) {
+				ed.line = append(ed.line[:ed.pos], append([]rune{r}, ed.line[ed.pos:]...)...)
+				ed.pos++
+			}
+		}
+		ed.refresh()
+	}
+	return ed.finish(), nil
+}
+
+// finish ends the edited line and adds it to the history.
+func (ed *lineEditor) finish() string {
+	fmt.Fprint(ed.out, "\r\n")
+	line := string(ed.line)
+	if strings.TrimSpace(line) != "" && (len(ed.history) == 0 || ed.history[len(ed.history)-1] != line) {
+		ed.history = append(ed.history, line)
+	}
+	return line
+}
+
+// escape handles the ANSI sequences of the arrow, Home, End and Delete keys.
+func (ed *lineEditor) escape() {
+	b, err := ed.in.ReadByte()
+	if err != nil || (b != '[' && b != 'O') {
+		return
+	}
+	var param []byte
+	for {
+		b, err = ed.in.ReadByte()
+		if err != nil {
+			return
+		}
+		if (b < '0' || b > '9') && b != ';' {
+			break
+		}
+		param = append(param, b)
+	}
+	switch b {
+	case 'A':
+		ed.browseHistory(-1)
+	case 'B':
+		ed.browseHistory(+1)
+	case 'C':
+		ed.pos = min(ed.pos+1, len(ed.line))
+	case 'D':
+		ed.pos = max(ed.pos-1, 0)
+	case 'H':
+		ed.pos = 0
+	case 'F':
+		ed.pos = len(ed.line)
+	case '~':
+		switch string(param) {
+		case "1", "7":
+			ed.pos = 0
+		case "4", "8":
+			ed.pos = len(ed.line)
+		case "3":
+			ed.deleteRange(ed.pos, ed.pos+1)
+		}
+	}
+}
+
+func (ed *lineEditor) deleteRange(from, to int) {
+	from, to = max(from, 0), min(to, len(ed.line))
+	if from >= to {
+		return
+	}
+	ed.line = append(ed.line[:from], ed.line[to:]...)
+	ed.pos = from
+}
+
+// browseHistory replaces the line with an older (delta < 0) or newer
+// history entry, keeping the unfinished line to come back to.
+func (ed *lineEditor) browseHistory(delta int) {
+	next := ed.histPos + delta
+	if next < 0 || next > len(ed.history) {
+		return
+	}
+	if ed.histPos == len(ed.history) {
+		ed.saved = ed.line
+	}
+	ed.histPos = next
+	if next == len(ed.history) {
+		ed.line = ed.saved
+	} else {
+		ed.line = []rune(ed.history[next])
+	}
+	ed.pos = len(ed.line)
+}
+
+// completeWord extends the word before the cursor to the longest prefix
+// shared by its completions, and lists them when that adds nothing.
+func (ed *lineEditor) completeWord() {
+	if ed.complete == nil {
+		return
+	}
+	text := string(ed.line)
+	pos := len(string(ed.line[:ed.pos]))
+	start, candidates := ed.complete(text, pos)
+	if len(candidates) == 0 {
+		fmt.Fprint(ed.out, "\a")
+		return
+	}
+	prefix := candidates[0]
+	for _, c := range candidates[1:] {
+		n := 0
+		for n < len(prefix) && n < len(c) && strings.EqualFold(prefix[n:n+1], c[n:n+1]) {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	if len(candidates) == 1 || len(prefix) > pos-start {
+		if len(candidates) == 1 {
+			prefix = candidates[0]
+		}
+		replaced := []rune(text[:start] + prefix)
+		ed.line = append(replaced, []rune(text[pos:])...)
+		ed.pos = len(replaced)
+		return
+	}
+	fmt.Fprintf(ed.out, "\r\n%s\r\n", strings.Join(candidates, "  "))
+}
+
+// refresh redraws the prompt and line and places the cursor.
+func (ed *lineEditor) refresh() {
+	fmt.Fprintf(ed.out, "\r%s%s\x1b[K", ed.prompt, string(ed.line))
+	if n := len(ed.line) - ed.pos; n > 0 {
+		fmt.Fprintf(ed.out, "\x1b[%dD", n)
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLineEditor(t *testing.T) {
+	tests := []struct {
+		desc    string
+		history []string
+		keys    string
+		want    string
+		wantErr error
+	}{
+		{"plain", nil, "1 + 2\r", "1 + 2", nil},
+		{"backspace", nil, "12\x7f3\r", "13", nil},
+		{"insert after Ctrl-A", nil, "23\x011\r", "123", nil},
+		{"arrow keys", nil, "13\x1b[D2\x1b[C4\r", "1234", nil},
+		{"delete key", nil, "123\x01\x1b[3~\r", "23", nil},
+		{"Ctrl-K and Ctrl-E", nil, "12345\x01\x06\x06\x0b9\x05\r", "129", nil},
+		{"Ctrl-U", nil, "abc def\x15x\r", "x", nil},
+		{"Ctrl-W", nil, "abc def  \x17\r", "abc ", nil},
+		{"history", []string{"first", "second"}, "\x1b[A\x1b[A\x1b[B!\r", "second!", nil},
+		{"history keeps the unfinished line", []string{"old"}, "new\x10\x0e\r", "new", nil},
+		{"UTF-8", nil, "\"あい\"\x02う\r", "\"あいう\"", nil},
+		{"complete common prefix", nil, "CAST(1 AS FL\t64\r", "CAST(1 AS FLOAT64", nil},
+		{"complete single candidate", nil, "cast(1 as boo\t)\r", "cast(1 as BOOL)", nil},
+		{"complete list", nil, "CAST(1 AS \t\r", "CAST(1 AS ", nil},
+		{"Ctrl-C", nil, "1\x03", "", errInterrupted},
+		{"Ctrl-D", nil, "\x04", "", io.EOF},
+		{"end of input", nil, "1 +", "1 +", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			r := &repl{env: &env{}}
+			ed := &lineEditor{
+				in:       bufio.NewReader(strings.NewReader(tt.keys)),
+				out:      io.Discard,
+				fd:       -1,
+				history:  tt.history,
+				complete: r.complete,
+			}
+			got, err := ed.edit("> ")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("line = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+// Command memebridge evaluates GoogleSQL literals, types and query
+// parameters the way the memebridge package does, to check its results
+// without writing a Go program.
+//
+// Usage:
+//
+//	memebridge eval   [flags] [EXPR...]
+//	memebridge type   [flags] [TYPE...]
+//	memebridge cast   [flags] TYPE [EXPR...]
+//	memebridge params [flags] [NAME:VALUE...]
+//	memebridge check  [flags] [EXPR...]
+//	memebridge repl   [flags]
+//	memebridge batch  [flags]
+//
+// eval prints the type and wire value of each expression, type prints the
+// spannerpb.Type of each type, cast converts each expression value to TYPE
+// with CAST semantics, params parses cliparams assignments and prints the
+// bound parameter map, and check reports the constructs of each expression
+// that memebridge cannot evaluate, exiting with status 1 if there are any.
+// repl evaluates expressions interactively, with line editing, tab
+// completion and SET PARAM statements binding @name parameters. batch
+// reads JSON Lines requests of the form
+//
+//	{"id": 1, "expr": "[@d, '2024-01-02']", "expected_type": "ARRAY<DATE>", "params": {"d": "DATE '2024-01-01'"}}
+//
+// from standard input and writes a JSON Lines response with the type and
+// wire value, or a structured error, for each one in input order.
+//
+// Without arguments, standard input is read as a single input; for params
+// it is read as a params file (see cliparams.ReadParamsFile).
+//
+// Flags (after the command name):
+//
+//	-format json|text|sql       output format (default text)
+//	-tz ZONE                    time zone of temporal casts (default America/Los_Angeles)
+//	-legacy-array-passthrough   keep uncoerced array element wire values
+//	-workers N                  requests evaluated concurrently (batch only)
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/apstndb/memebridge"
+)
+
+func main() {
+	err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr, os.DirFS("."))
+	switch {
+	case err == nil:
+	case errors.Is(err, flag.ErrHelp):
+		os.Exit(2)
+	case errors.Is(err, errCheckFailed):
+		os.Exit(1)
+	default:
+		fmt.Fprintln(os.Stderr, "memebridge:", err)
+		os.Exit(1)
+	}
+}
+
+// command is one memebridge subcommand.
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(e *env, args []string) error
+}
+
+var commands = []*command{
+	{"eval", "[EXPR...]", "print the type and wire value of expressions", runEval},
+	{"type", "[TYPE...]", "print the spannerpb.Type of types", runType},
+	{"cast", "TYPE [EXPR...]", "CAST expression values to TYPE", runCast},
+	{"params", "[NAME:VALUE...]", "parse parameter assignments and print the bound map", runParams},
+	{"check", "[EXPR...]", "report constructs that memebridge cannot evaluate", runCheck},
+	{"repl", "", "evaluate expressions interactively", runRepl},
+	{"batch", "", "evaluate JSON Lines requests from standard input", runBatch},
+}
+
+// env is the state shared by a command invocation.
+type env struct {
+	stdin    io.Reader
+	stdout   io.Writer
+	stderr   io.Writer
+	fsys     fs.FS
+	format   outputFormat
+	evalOpts []memebridge.EvalOption
+	workers  int
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer, fsys fs.FS) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		usage(stderr)
+		return flag.ErrHelp
+	}
+	var cmd *command
+	for _, c := range commands {
+		if c.name == args[0] {
+			cmd = c
+		}
+	}
+	if cmd == nil {
+		usage(stderr)
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+
+	fset := flag.NewFlagSet("memebridge "+cmd.name, flag.ContinueOnError)
+	fset.SetOutput(stderr)
+	fset.Usage = func() {
+		fmt.Fprintf(fset.Output(), "usage: memebridge %s [flags] %s\n\n%s.\n\nflags:\n", cmd.name, cmd.args, cmd.summary)
+		fset.PrintDefaults()
+	}
+	format := formatText
+	fset.TextVar(&format, "format", formatText, "output `format`: json, text or sql")
+	tz := fset.String("tz", "", "IANA time `zone` of temporal casts without an explicit zone (default America/Los_Angeles)")
+	legacy := fset.Bool("legacy-array-passthrough", false, "keep array element wire values that do not coerce to the element type")
+	var workers int
+	if cmd.name == "batch" {
+		fset.IntVar(&workers, "workers", runtime.GOMAXPROCS(0), "number of requests evaluated concurrently")
+	}
+	if err := fset.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	e := &env{stdin: stdin, stdout: stdout, stderr: stderr, fsys: fsys, format: format, workers: workers}
+	if *tz != "" {
+		loc, err := time.LoadLocation(*tz)
+		if err != nil {
+			return fmt.Errorf("-tz: %w", err)
+		}
+		e.evalOpts = append(e.evalOpts, memebridge.WithTimeZone(loc))
+	}
+	if *legacy {
+		e.evalOpts = append(e.evalOpts, memebridge.WithLegacyArrayWirePassthrough())
+	}
+	return cmd.run(e, fset.Args())
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: memebridge <command> [flags] [args...]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-7s %-16s %s\n", c.name, c.args, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Inputs are read from the arguments, or from standard input when there are none.
+Run "memebridge <command> -h" for the flags.`)
+}
+
+// input is one expression or type to process, with the name used in error
+// positions.
+type input struct {
+	name string
+	text string
+}
+
+// inputs returns args as inputs named arg1, arg2, ..., or the content of
+// standard input as a single input named "-" when args is empty.
+func (e *env) inputs(args []string) ([]input, error) {
+	if len(args) > 0 {
+		ins := make([]input, len(args))
+		for i, arg := range args {
+			ins[i] = input{name: fmt.Sprintf("arg%d", i+1), text: arg}
+		}
+		return ins, nil
+	}
+	b, err := io.ReadAll(e.stdin)
+	if err != nil {
+		return nil, fmt.Errorf("reading standard input: %w", err)
+	}
+	text := strings.TrimSpace(string(b))
+	if text == "" {
+		return nil, errors.New("no input: pass arguments or write to standard input")
+	}
+	return []input{{name: "-", text: text}}, nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRun(t *testing.T) {
+	fsys := fstest.MapFS{"vec.json": {Data: []byte("[1, 2]")}}
+	tests := []struct {
+		desc  string
+		args  []string
+		stdin string
+		want  string
+	}{
+		{"eval text", []string{"eval", "1", `ARRAY<DATE>["2024-01-01"]`}, "", "INT64\t\"1\"\nARRAY<DATE>\t[\"2024-01-01\"]\n"},
+		{"eval json", []string{"eval", "-format=json", `STRUCT(1 AS x)`}, "", `{"type":{"code":"STRUCT","structType":{"fields":[{"name":"x","type":{"code":"INT64"}}]}},"value":["1"]}` + "\n"},
+		{"eval sql", []string{"eval", "--format", "sql", `CAST(NULL AS ARRAY<INT64>)`}, "", "CAST(NULL AS ARRAY<INT64>)\n"},
+		{"eval stdin", []string{"eval"}, "\"a\"\n", "STRING\t\"a\"\n"},
+		{"legacy array passthrough", []string{"eval", "-legacy-array-passthrough", `ARRAY<INT64>[1, "x"]`}, "", "ARRAY<INT64>\t[\"1\",\"x\"]\n"},
+		{"type", []string{"type", "ARRAY<STRUCT<x INT64>>"}, "", "ARRAY<STRUCT<x INT64>>\n"},
+		{"type json", []string{"type", "-format=json", "DATE"}, "", `{"code":"DATE"}` + "\n"},
+		{"cast", []string{"cast", "DATE", `TIMESTAMP "2024-01-01T00:00:00Z"`}, "", "DATE\t\"2023-12-31\"\n"},
+		{"cast tz", []string{"cast", "-tz", "UTC", "DATE", `TIMESTAMP "2024-01-01T00:00:00Z"`}, "", "DATE\t\"2024-01-01\"\n"},
+		{"params", []string{"params", "-format=sql", "a:1", "d:DATE=2024-01-01", "v:@file:vec.json"}, "", "SET PARAM a = 1;\nSET PARAM d = DATE \"2024-01-01\";\nSET PARAM v = ARRAY<FLOAT64>[1.0, 2.0];\n"},
+		{"params file", []string{"params"}, "# comment\nn:ARRAY<STRING>\n", "NAME  TYPE           VALUE\nn     ARRAY<STRING>  CAST(NULL AS ARRAY<STRING>)\n"},
+		{"check ok", []string{"check", `[1, 2]`}, "", "arg1: ok\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var stdout bytes.Buffer
+			if err := run(tt.args, strings.NewReader(tt.stdin), &stdout, io.Discard, fsys); err != nil {
+				t.Fatal(err)
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("output mismatch\ngot:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunCheck(t *testing.T) {
+	var stdout bytes.Buffer
+	err := run([]string{"check", "1", `STRUCT(FN(1) AS a, [x] AS b)`, `CAST("a" AS INT64)`}, nil, &stdout, io.Discard, nil)
+	if !errors.Is(err, errCheckFailed) {
+		t.Fatalf("err = %v, want errCheckFailed", err)
+	}
+	want := `arg1: ok
+arg2:1:8: FN(1): unsupported expression: FN(1)
+arg2:1:20: [x]: unsupported expression: x
+arg3:1:1: CAST("a" AS INT64): strconv.ParseInt: parsing "a": invalid syntax
+`
+	if got := stdout.String(); got != want {
+		t.Errorf("output mismatch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	for _, args := range [][]string{
+		nil,
+		{"unknown"},
+		{"eval", "-format=yaml", "1"},
+		{"eval", "-tz=Nowhere/Nothing", "1"},
+		{"eval", "FN("},
+		{"eval"},
+		{"cast"},
+	} {
+		if err := run(args, strings.NewReader(""), io.Discard, io.Discard, nil); err == nil {
+			t.Errorf("run(%q) succeeded, want error", args)
+		}
+	}
+}
+
+func TestRunRepl(t *testing.T) {
+	stdin := `ARRAY<INT64>[]
+SET PARAM n = 40;
+SET PARAM d DATE;
+CAST(@n AS STRING)
+[@n, @missing]
+\params
+\unknown
+quit
+"not reached"
+`
+	var stdout, stderr bytes.Buffer
+	if err := run([]string{"repl"}, strings.NewReader(stdin), &stdout, &stderr, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := `ARRAY<INT64>	[]
+STRING	"40"
+NAME  TYPE   VALUE
+d     DATE   CAST(NULL AS DATE)
+n     INT64  40
+`
+	if got := stdout.String(); got != want {
+		t.Errorf("output mismatch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	wantErr := `error: undefined query parameter: @missing
+error: unknown command \unknown; type \help for help
+`
+	if got := stderr.String(); got != wantErr {
+		t.Errorf("error output mismatch\ngot:\n%s\nwant:\n%s", got, wantErr)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spantype"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/apstndb/memebridge/cliparams"
+)
+
+// outputFormat is the value of the -format flag.
+type outputFormat int
+
+const (
+	formatText outputFormat = iota
+	formatJSON
+	formatSQL
+)
+
+var outputFormatNames = []string{
+	formatText: "text",
+	formatJSON: "json",
+	formatSQL:  "sql",
+}
+
+func (f outputFormat) MarshalText() ([]byte, error) {
+	return []byte(outputFormatNames[f]), nil
+}
+
+func (f *outputFormat) UnmarshalText(b []byte) error {
+	for i, name := range outputFormatNames {
+		if string(b) == name {
+			*f = outputFormat(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown format %q (want json, text or sql)", b)
+}
+
+// printValue writes gcv as a JSON object of protojson type and value, as
+// its type and protojson wire value, or as a typed GoogleSQL literal.
+func (e *env) printValue(gcv spanner.GenericColumnValue) error {
+	switch e.format {
+	case formatJSON:
+		typ, err := protoJSON(gcv.Type)
+		if err != nil {
+			return err
+		}
+		value, err := protoJSON(gcv.Value)
+		if err != nil {
+			return err
+		}
+		return e.printJSON(struct {
+			Type  json.RawMessage `json:"type"`
+			Value json.RawMessage `json:"value"`
+		}{typ, value})
+	case formatSQL:
+		lit, err := cliparams.FormatLiteral(gcv)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(e.stdout, lit)
+		return err
+	default:
+		value, err := protoJSON(gcv.Value)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(e.stdout, "%s\t%s\n", spantype.FormatTypeVerbose(gcv.Type), value)
+		return err
+	}
+}
+
+// printType writes t as protojson, or in GoogleSQL syntax for text and sql.
+func (e *env) printType(t *sppb.Type) error {
+	if e.format == formatJSON {
+		typ, err := protoJSON(t)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(e.stdout, "%s\n", typ)
+		return err
+	}
+	_, err := fmt.Fprintln(e.stdout, spantype.FormatTypeVerbose(t))
+	return err
+}
+
+// printJSON writes v as one line of JSON.
+func (e *env) printJSON(v any) error {
+	enc := json.NewEncoder(e.stdout)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+// protoJSON marshals m as compact protojson, whose whitespace is otherwise
+// unstable.
+func protoJSON(m proto.Message) (json.RawMessage, error) {
+	b, err := protojson.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, b); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
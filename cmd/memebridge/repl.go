@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+
+	"cloud.google.com/go/spanner"
+
+	"github.com/apstndb/memebridge"
+	"github.com/apstndb/memebridge/cliparams"
+)
+
+const replHelp = `Enter a GoogleSQL expression to print its type and value.
+
+  SET PARAM name = EXPR;   bind @name for later expressions
+  SET PARAM name TYPE;     bind @name to a typed NULL
+  \params                  list the bound parameters
+  \help                    show this help
+  \q                       quit (also exit, quit or Ctrl-D)
+
+Tab completes type, function, date/time part and parameter names.`
+
+// repl is an interactive session with its bound parameters.
+type repl struct {
+	*env
+	ed     *lineEditor
+	params map[string]spanner.GenericColumnValue
+}
+
+func runRepl(e *env, args []string) error {
+	if len(args) > 0 {
+		return errors.New("repl: unexpected arguments")
+	}
+	r := &repl{env: e, ed: newLineEditor(e.stdin, e.stdout), params: map[string]spanner.GenericColumnValue{}}
+	r.ed.complete = r.complete
+	interactive := r.ed.fd >= 0
+	if interactive {
+		fmt.Fprintln(e.stdout, `memebridge REPL; type \help for help.`)
+	}
+	for {
+		line, err := r.ed.readLine("memebridge> ")
+		switch {
+		case errors.Is(err, errInterrupted):
+			continue
+		case err == io.EOF:
+			return nil
+		case err != nil:
+			return err
+		}
+		quit, err := r.exec(line)
+		if err != nil {
+			fmt.Fprintln(e.stderr, "error:", err)
+		}
+		if quit {
+			return nil
+		}
+	}
+}
+
+// exec runs one input line, reporting whether it asks to quit.
+func (r *repl) exec(line string) (quit bool, err error) {
+	line = strings.TrimSpace(line)
+	stmt := strings.TrimSpace(strings.TrimSuffix(line, ";"))
+	switch {
+	case stmt == "":
+		return false, nil
+	case stmt == `\q` || strings.EqualFold(stmt, "exit") || strings.EqualFold(stmt, "quit"):
+		return true, nil
+	case stmt == `\help` || stmt == `\?`:
+		_, err := fmt.Fprintln(r.stdout, replHelp)
+		return false, err
+	case stmt == `\params`:
+		return false, cliparams.FormatParams(r.stdout, r.params, cliparams.FormatTable)
+	case strings.HasPrefix(stmt, `\`):
+		return false, fmt.Errorf("unknown command %s; type \\help for help", stmt)
+	case strings.EqualFold(strings.Fields(stmt)[0], "SET"):
+		return false, r.setParams(line)
+	default:
+		gcv, err := memebridge.ParseExprFile("input", stmt, r.evalOptions()...)
+		if err != nil {
+			return false, err
+		}
+		return false, r.printValue(gcv)
+	}
+}
+
+// setParams binds the parameters of a SET PARAM statement. Its value may
+// reference parameters bound earlier.
+func (r *repl) setParams(stmt string) error {
+	params, err := cliparams.ReadSetParamScript("input", strings.NewReader(stmt),
+		cliparams.WithEvalOptions(r.evalOptions()...))
+	if err != nil {
+		return err
+	}
+	maps.Copy(r.params, params)
+	return nil
+}
+
+func (r *repl) evalOptions() []memebridge.EvalOption {
+	return append(r.evalOpts[:len(r.evalOpts):len(r.evalOpts)], memebridge.WithParams(r.params))
+}
+
+func (r *repl) complete(line string, pos int) (int, []string) {
+	start, completions := memebridge.Complete(line, pos, r.evalOptions()...)
+	candidates := make([]string, len(completions))
+	for i, c := range completions {
+		candidates[i] = c.Text
+	}
+	return start, candidates
+}
@@ -0,0 +1,11 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package main
+
+import "errors"
+
+// makeRaw is not implemented on this platform, so the REPL reads whole
+// lines without editing.
+func makeRaw(fd uintptr) (restore func(), err error) {
+	return nil, errors.New("raw terminal mode is not supported on this platform")
+}
@@ -0,0 +1,160 @@
+package memebridge
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"unicode/utf8"
+
+	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spantype"
+)
+
+// ColumnSpec is what a column accepts, for checking values with
+// [ValidateColumnValue] before they are sent in a mutation.
+type ColumnSpec struct {
+	// Type is the column type.
+	Type *sppb.Type
+	// Constraints are the length limits of Type.
+	Constraints TypeConstraints
+	// NotNull rejects a NULL value.
+	NotNull bool
+	// ElementsNotNull rejects NULL elements of an ARRAY value.
+	ElementsNotNull bool
+}
+
+// Spec returns the [ColumnSpec] of c. DDL cannot declare ARRAY elements
+// NOT NULL, so ElementsNotNull is false.
+func (c *Column) Spec() ColumnSpec {
+	return ColumnSpec{Type: c.Type, Constraints: c.Constraints, NotNull: c.NotNull}
+}
+
+// ConstraintViolation is one way a value does not satisfy a [ColumnSpec],
+// as reported by [ValidateColumnValue].
+type ConstraintViolation struct {
+	// Type is the column type in DDL spelling, such as ARRAY<STRING(10)>.
+	Type string
+	// Element is the 1-based index of the offending ARRAY element, or 0
+	// for a violation by the whole value.
+	Element int
+	// Problem describes the violation, such as "has 14 characters".
+	Problem string
+}
+
+func (v *ConstraintViolation) Error() string {
+	if v.Element > 0 {
+		return fmt.Sprintf("element %d of %s %s", v.Element, v.Type, v.Problem)
+	}
+	return fmt.Sprintf("%s value %s", v.Type, v.Problem)
+}
+
+// ValidateColumnValue checks gcv against spec: its type, NOT NULL, the
+// character limit of STRING(n), the byte limit of BYTES(n), the
+// vector_length of an ARRAY and NULL elements. It returns nil, or every
+// violation as a [*ConstraintViolation] joined with errors.Join, such as
+//
+//	element 3 of ARRAY<STRING(10)> has 14 characters
+//
+// A value of another type is a single violation.
+func ValidateColumnValue(gcv spanner.GenericColumnValue, spec ColumnSpec) error {
+	typeName, err := FormatSchemaType(spec.Type, spec.Constraints)
+	if err != nil {
+		typeName = spantype.FormatTypeNormal(spec.Type)
+	}
+	violation := func(element int, format string, args ...any) error {
+		return &ConstraintViolation{Type: typeName, Element: element, Problem: fmt.Sprintf(format, args...)}
+	}
+
+	if !spantype.EquivalentTypes(gcv.Type, spec.Type) {
+		return violation(0, "has type %s", spantype.FormatTypeNormal(gcv.Type))
+	}
+	if isNullGCV(gcv) {
+		if spec.NotNull {
+			return violation(0, "is NULL in a NOT NULL column")
+		}
+		return nil
+	}
+	if spec.Type.GetCode() != sppb.TypeCode_ARRAY {
+		if problem := lengthProblem(gcv, spec.Constraints.Length); problem != "" {
+			return violation(0, "%s", problem)
+		}
+		return nil
+	}
+
+	var errs []error
+	values := gcv.Value.GetListValue().GetValues()
+	if n := spec.Constraints.VectorLength; n > 0 && int64(len(values)) != n {
+		errs = append(errs, violation(0, "has %d elements", len(values)))
+	}
+	elemType := spec.Type.GetArrayElementType()
+	for i, v := range values {
+		elem := spanner.GenericColumnValue{Type: elemType, Value: normalizeValue(v)}
+		if isNullGCV(elem) {
+			if spec.ElementsNotNull {
+				errs = append(errs, violation(i+1, "is NULL"))
+			}
+			continue
+		}
+		if problem := lengthProblem(elem, spec.Constraints.Length); problem != "" {
+			errs = append(errs, violation(i+1, "%s", problem))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// lengthProblem describes how a non-NULL STRING or BYTES value exceeds
+// limit, or returns "" if it does not or limit is 0.
+func lengthProblem(gcv spanner.GenericColumnValue, limit int64) string {
+	if limit == 0 {
+		return ""
+	}
+	switch gcv.Type.GetCode() {
+	case sppb.TypeCode_STRING:
+		s, err := stringFromGCV(gcv)
+		if err != nil {
+			return err.Error()
+		}
+		if n := utf8.RuneCountInString(s); int64(n) > limit {
+			return fmt.Sprintf("has %d characters", n)
+		}
+	case sppb.TypeCode_BYTES:
+		b, err := bytesFromGCV(gcv)
+		if err != nil {
+			return "is not valid base64: " + err.Error()
+		}
+		if n := len(b); int64(n) > limit {
+			return fmt.Sprintf("has %d bytes", n)
+		}
+	}
+	return ""
+}
+
+// Validate checks a value for c with [ValidateColumnValue].
+func (c *Column) Validate(gcv spanner.GenericColumnValue) error {
+	if err := ValidateColumnValue(gcv, c.Spec()); err != nil {
+		return fmt.Errorf("column %s: %w", c.Name, err)
+	}
+	return nil
+}
+
+// ValidateColumns checks the values of a row, such as the columns of an
+// insert mutation, with [Column.Validate]. Column names are matched
+// case-insensitively, and a name that is not a column of t is an error.
+// Columns missing from columns are not checked. All failures are joined
+// with errors.Join, in column name order.
+func (t *Table) ValidateColumns(columns map[string]spanner.GenericColumnValue) error {
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(columns)) {
+		col, ok := t.Column(name)
+		if !ok {
+			errs = append(errs, fmt.Errorf("table %s has no column %s", t.Name, name))
+			continue
+		}
+		if err := col.Validate(columns[name]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
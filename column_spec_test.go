@@ -0,0 +1,94 @@
+package memebridge_test
+
+import (
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/spanner"
+	"github.com/apstndb/spantype/typector"
+	"github.com/apstndb/spanvalue/gcvctor"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/apstndb/memebridge"
+)
+
+func TestValidateColumnValue(t *testing.T) {
+	stringArray, constraints, err := memebridge.ParseSchemaType("ARRAY<STRING(10)>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := memebridge.ColumnSpec{Type: stringArray, Constraints: constraints, NotNull: true, ElementsNotNull: true}
+	for _, tt := range []struct {
+		expr string
+		want []string
+	}{
+		{`['short', 'ok']`, nil},
+		{`['a', NULL, 'fourteen chars']`, []string{
+			"element 2 of ARRAY<STRING(10)> is NULL",
+			"element 3 of ARRAY<STRING(10)> has 14 characters",
+		}},
+		{`CAST(NULL AS ARRAY<STRING>)`, []string{"ARRAY<STRING(10)> value is NULL in a NOT NULL column"}},
+		{`[1]`, []string{"ARRAY<STRING(10)> value has type ARRAY<INT64>"}},
+		// Characters, not bytes, count for STRING.
+		{`['ああああああああああ']`, nil},
+	} {
+		err := memebridge.ValidateColumnValue(must(memebridge.ParseExprToGCV(tt.expr)), spec)
+		var got []string
+		if err != nil {
+			for _, e := range unjoin(err) {
+				var v *memebridge.ConstraintViolation
+				if !errors.As(e, &v) {
+					t.Errorf("%s: %v is not a *ConstraintViolation", tt.expr, e)
+				}
+				got = append(got, e.Error())
+			}
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("%s: violations mismatch (-want +got):\n%s", tt.expr, diff)
+		}
+	}
+}
+
+func TestTableValidateColumns(t *testing.T) {
+	schema, err := memebridge.ParseSchema("schema.sql", `
+CREATE TABLE Docs (
+  Id INT64 NOT NULL,
+  Digest BYTES(4),
+  Embedding ARRAY<FLOAT32>(vector_length=>2),
+) PRIMARY KEY (Id)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs, _ := schema.Table("Docs")
+	err = docs.ValidateColumns(map[string]spanner.GenericColumnValue{
+		"Id":        gcvctor.NullOf(typector.Int64()),
+		"digest":    must(memebridge.ParseExprToGCV(`b'12345'`)),
+		"Embedding": must(memebridge.ParseExprToGCV(`[1.0, 2.0, 3.0]`, memebridge.WithExpectedType(docs.Columns[2].Type))),
+		"Extra":     gcvctor.Int64Value(1),
+	})
+	var got []string
+	for _, e := range unjoin(err) {
+		got = append(got, e.Error())
+	}
+	want := []string{
+		"column Embedding: ARRAY<FLOAT32>(vector_length=>2) value has 3 elements",
+		"table Docs has no column Extra",
+		"column Id: INT64 value is NULL in a NOT NULL column",
+		"column Digest: BYTES(4) value has 5 bytes",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("errors mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// unjoin returns the errors joined in err.
+func unjoin(err error) []error {
+	if err == nil {
+		return nil
+	}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	return joined.Unwrap()
+}
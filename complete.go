@@ -0,0 +1,292 @@
+package memebridge
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cloudspannerecosystem/memefish"
+	"github.com/cloudspannerecosystem/memefish/ast"
+	"github.com/cloudspannerecosystem/memefish/char"
+	"github.com/cloudspannerecosystem/memefish/token"
+)
+
+// CompletionKind classifies a completion candidate.
+type CompletionKind int
+
+const (
+	// CompletionKeyword is an expression keyword such as CAST or NULL.
+	CompletionKeyword CompletionKind = iota
+	// CompletionType is a type name.
+	CompletionType
+	// CompletionFunction is a GoogleSQL function name.
+	CompletionFunction
+	// CompletionDateTimePart is a date/time part such as DAY.
+	CompletionDateTimePart
+	// CompletionParam is a query parameter name, without the "@".
+	CompletionParam
+)
+
+var completionKindNames = []string{
+	CompletionKeyword:      "keyword",
+	CompletionType:         "type",
+	CompletionFunction:     "function",
+	CompletionDateTimePart: "datetime part",
+	CompletionParam:        "parameter",
+}
+
+func (k CompletionKind) String() string {
+	if k < 0 || int(k) >= len(completionKindNames) {
+		return fmt.Sprintf("CompletionKind(%d)", int(k))
+	}
+	return completionKindNames[k]
+}
+
+// Completion is one completion candidate.
+type Completion struct {
+	// Text replaces the word being completed.
+	Text string
+	Kind CompletionKind
+}
+
+var (
+	completionKeywords = []string{
+		"ARRAY", "CAST", "DATE", "FALSE", "INTERVAL", "JSON", "NULL", "NUMERIC",
+		"SAFE_CAST", "STRUCT", "TIMESTAMP", "TRUE",
+	}
+
+	completionTypes = []string{
+		"ARRAY", "BOOL", "BYTES", "DATE", "FLOAT32", "FLOAT64", "INT64",
+		"INTERVAL", "JSON", "NUMERIC", "STRING", "STRUCT", "TIMESTAMP", "UUID",
+	}
+
+	completionDateTimeParts = []string{
+		string(ast.DateTimePartYear), string(ast.DateTimePartISOYear),
+		string(ast.DateTimePartQuarter), string(ast.DateTimePartMonth),
+		string(ast.DateTimePartWeek), string(ast.DateTimePartISOWeek),
+		string(ast.DateTimePartDay), string(ast.DateTimePartDayOfWeek),
+		string(ast.DateTimePartDayOfYear), string(ast.DateTimePartDate),
+		string(ast.DateTimePartHour), string(ast.DateTimePartMinute),
+		string(ast.DateTimePartSecond), string(ast.DateTimePartMillisecond),
+		string(ast.DateTimePartMicrosecond), string(ast.DateTimePartNanosecond),
+	}
+
+	// completionFunctions lists the GoogleSQL functions of Cloud Spanner,
+	// whether or not memebridge evaluates them.
+	completionFunctions = []string{
+		// Aggregate and statistical
+		"ANY_VALUE", "ARRAY_AGG", "ARRAY_CONCAT_AGG", "AVG", "BIT_AND", "BIT_OR",
+		"BIT_XOR", "COUNT", "COUNTIF", "LOGICAL_AND", "LOGICAL_OR", "MAX", "MIN",
+		"STDDEV", "STDDEV_SAMP", "STRING_AGG", "SUM", "VAR_SAMP", "VARIANCE",
+		// Array
+		"ARRAY_CONCAT", "ARRAY_FILTER", "ARRAY_FIRST", "ARRAY_INCLUDES",
+		"ARRAY_INCLUDES_ALL", "ARRAY_INCLUDES_ANY", "ARRAY_IS_DISTINCT",
+		"ARRAY_LAST", "ARRAY_LENGTH", "ARRAY_MAX", "ARRAY_MIN", "ARRAY_REVERSE",
+		"ARRAY_SLICE", "ARRAY_TO_STRING", "ARRAY_TRANSFORM", "GENERATE_ARRAY",
+		"GENERATE_DATE_ARRAY",
+		// Conditional
+		"COALESCE", "IF", "IFNULL", "NULLIF",
+		// Date and time
+		"CURRENT_DATE", "CURRENT_TIMESTAMP", "DATE", "DATE_ADD", "DATE_DIFF",
+		"DATE_FROM_UNIX_DATE", "DATE_SUB", "DATE_TRUNC", "EXTRACT", "FORMAT_DATE",
+		"FORMAT_TIMESTAMP", "LAST_DAY", "PARSE_DATE", "PARSE_TIMESTAMP",
+		"PENDING_COMMIT_TIMESTAMP", "STRING", "TIMESTAMP", "TIMESTAMP_ADD",
+		"TIMESTAMP_DIFF", "TIMESTAMP_MICROS", "TIMESTAMP_MILLIS",
+		"TIMESTAMP_SECONDS", "TIMESTAMP_SUB", "TIMESTAMP_TRUNC", "UNIX_DATE",
+		"UNIX_MICROS", "UNIX_MILLIS", "UNIX_SECONDS",
+		// Interval
+		"JUSTIFY_DAYS", "JUSTIFY_HOURS", "JUSTIFY_INTERVAL",
+		"MAKE_INTERVAL",
+		// Hash and UUID
+		"FARM_FINGERPRINT", "GENERATE_UUID", "NEW_UUID", "SHA1", "SHA256",
+		"SHA512",
+		// JSON
+		"BOOL", "FLOAT32", "FLOAT64", "INT64", "JSON_ARRAY", "JSON_ARRAY_APPEND",
+		"JSON_ARRAY_INSERT", "JSON_CONTAINS", "JSON_KEYS", "JSON_OBJECT",
+		"JSON_QUERY", "JSON_QUERY_ARRAY", "JSON_REMOVE", "JSON_SET",
+		"JSON_STRIP_NULLS", "JSON_TYPE", "JSON_VALUE", "JSON_VALUE_ARRAY",
+		"LAX_BOOL", "LAX_FLOAT64", "LAX_INT64", "LAX_STRING", "PARSE_JSON",
+		"SAFE_TO_JSON", "TO_JSON", "TO_JSON_STRING",
+		// Mathematical
+		"ABS", "ACOS", "ACOSH", "ASIN", "ASINH", "ATAN", "ATAN2", "ATANH", "CEIL",
+		"CEILING", "COS", "COSH", "COSINE_DISTANCE", "DIV", "DOT_PRODUCT",
+		"EUCLIDEAN_DISTANCE", "EXP", "FLOOR", "GREATEST", "IEEE_DIVIDE", "IS_INF",
+		"IS_NAN", "LEAST", "LN", "LOG", "LOG10", "MOD", "POW", "POWER", "ROUND",
+		"SAFE_ADD", "SAFE_DIVIDE", "SAFE_MULTIPLY", "SAFE_NEGATE", "SAFE_SUBTRACT",
+		"SIGN", "SIN", "SINH", "SQRT", "TAN", "TANH", "TRUNC",
+		// String
+		"BYTE_LENGTH", "CHAR_LENGTH", "CHARACTER_LENGTH", "CODE_POINTS_TO_BYTES",
+		"CODE_POINTS_TO_STRING", "CONCAT", "ENDS_WITH", "FORMAT", "FROM_BASE32",
+		"FROM_BASE64", "FROM_HEX", "LCASE", "LENGTH", "LOWER", "LPAD", "LTRIM",
+		"NORMALIZE", "NORMALIZE_AND_CASEFOLD", "REGEXP_CONTAINS", "REGEXP_EXTRACT",
+		"REGEXP_EXTRACT_ALL", "REGEXP_REPLACE", "REPEAT", "REPLACE", "REVERSE",
+		"RPAD", "RTRIM", "SAFE_CONVERT_BYTES_TO_STRING", "SOUNDEX", "SPLIT",
+		"STARTS_WITH", "STRPOS", "SUBSTR", "SUBSTRING", "TO_BASE32", "TO_BASE64",
+		"TO_CODE_POINTS", "TO_HEX", "TRIM", "UCASE", "UPPER",
+		// Bit and net
+		"BIT_COUNT", "BIT_REVERSE", "NET.HOST", "NET.IP_FROM_STRING",
+		"NET.IP_NET_MASK", "NET.IP_TO_STRING", "NET.IP_TRUNC",
+		"NET.IPV4_FROM_INT64", "NET.IPV4_TO_INT64", "NET.PUBLIC_SUFFIX",
+		"NET.REG_DOMAIN", "NET.SAFE_IP_FROM_STRING",
+		// Full-text search
+		"SCORE", "SCORE_NGRAMS", "SEARCH", "SEARCH_NGRAMS", "SEARCH_SUBSTRING",
+		"SNIPPET", "TOKEN", "TOKENIZE_BOOL", "TOKENIZE_FULLTEXT",
+		"TOKENIZE_JSON", "TOKENIZE_NGRAMS", "TOKENIZE_NUMBER",
+		"TOKENIZE_SUBSTRING", "TOKENLIST_CONCAT",
+		// Utility
+		"ERROR", "GET_INTERNAL_SEQUENCE_STATE", "GET_NEXT_SEQUENCE_VALUE",
+	}
+
+	// dateTimePartFunctions take a date/time part after a comma.
+	dateTimePartFunctions = []string{
+		"DATE_DIFF", "DATE_TRUNC", "LAST_DAY", "TIMESTAMP_DIFF", "TIMESTAMP_TRUNC",
+	}
+)
+
+// Complete returns completion candidates for the word that ends at byte
+// offset pos of text, and the offset where that word starts; a caller
+// replaces text[start:pos] with a candidate's Text. Candidates match the
+// word case-insensitively and are sorted and free of duplicates.
+//
+// Candidates depend on the position: parameter names after "@" (from
+// [WithParams]), type names after CAST(... AS and inside ARRAY<...> or
+// STRUCT<...>, date/time parts after INTERVAL n, TO, EXTRACT( and the last
+// argument of DATE_TRUNC-style functions, and keywords and function names
+// elsewhere. Other options are ignored. Completion never fails; text that
+// does not lex yields no candidates.
+func Complete(text string, pos int, opts ...EvalOption) (start int, candidates []Completion) {
+	o := applyEvalOptions(opts)
+	pos = max(0, min(pos, len(text)))
+	start = pos
+	for start > 0 && (char.IsIdentPart(text[start-1]) || text[start-1] == '.') {
+		start--
+	}
+	prefix := text[start:pos]
+
+	if start > 0 && text[start-1] == '@' {
+		names := make([]string, 0, len(o.params))
+		for name := range o.params {
+			names = append(names, name)
+		}
+		return start, matchCompletions(prefix, CompletionParam, names)
+	}
+
+	toks, ok := completionTokens(text[:start])
+	if !ok {
+		return start, nil
+	}
+	switch completionContextOf(toks) {
+	case CompletionType:
+		return start, matchCompletions(prefix, CompletionType, completionTypes)
+	case CompletionDateTimePart:
+		return start, matchCompletions(prefix, CompletionDateTimePart, completionDateTimeParts)
+	default:
+		// A name that is both a keyword and a function, such as DATE, is
+		// offered once, as a keyword.
+		return start, sortCompletions(slices.Concat(
+			matchCompletions(prefix, CompletionKeyword, completionKeywords),
+			matchCompletions(prefix, CompletionFunction, completionFunctions),
+		))
+	}
+}
+
+func matchCompletions(prefix string, kind CompletionKind, names []string) []Completion {
+	var matched []Completion
+	for _, name := range names {
+		if len(name) >= len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) {
+			matched = append(matched, Completion{Text: name, Kind: kind})
+		}
+	}
+	return sortCompletions(matched)
+}
+
+// sortCompletions sorts cs by text and drops later candidates with the same
+// text.
+func sortCompletions(cs []Completion) []Completion {
+	slices.SortStableFunc(cs, func(a, b Completion) int { return strings.Compare(a.Text, b.Text) })
+	return slices.CompactFunc(cs, func(a, b Completion) bool { return a.Text == b.Text })
+}
+
+// completionTokens lexes text, reporting false if it does not lex (for
+// example, inside an unterminated string).
+func completionTokens(text string) ([]token.Token, bool) {
+	lex := &memefish.Lexer{File: &token.File{Buffer: text}}
+	var toks []token.Token
+	for {
+		if err := lex.NextToken(); err != nil {
+			return nil, false
+		}
+		if lex.Token.Kind == token.TokenEOF {
+			return toks, true
+		}
+		toks = append(toks, lex.Token)
+	}
+}
+
+// completionContextOf classifies the position after toks as expecting a
+// type, a date/time part, or an expression (CompletionKeyword).
+func completionContextOf(toks []token.Token) CompletionKind {
+	// Track the open parentheses and angle brackets, each with the token
+	// before it, which names the function or the ARRAY/STRUCT type.
+	type open struct {
+		kind  token.TokenKind
+		owner string
+		index int // of the opening token
+	}
+	var stack []open
+	owner := func(i int) string {
+		if i > 0 && toks[i-1].Kind == token.TokenIdent {
+			return strings.ToUpper(toks[i-1].AsString)
+		}
+		if i > 0 {
+			return strings.ToUpper(string(toks[i-1].Kind))
+		}
+		return ""
+	}
+	for i, tok := range toks {
+		switch tok.Kind {
+		case "(", "<":
+			stack = append(stack, open{tok.Kind, owner(i), i})
+		case ")":
+			if n := len(stack); n > 0 && stack[n-1].kind == "(" {
+				stack = stack[:n-1]
+			}
+		case ">", ">>":
+			for range len(tok.Kind) {
+				if n := len(stack); n > 0 && stack[n-1].kind == "<" {
+					stack = stack[:n-1]
+				}
+			}
+		}
+	}
+
+	var last, beforeLast token.Token
+	if n := len(toks); n > 0 {
+		last = toks[n-1]
+		if n > 1 {
+			beforeLast = toks[n-2]
+		}
+	}
+	if n := len(stack); n > 0 {
+		top := stack[n-1]
+		switch {
+		case top.kind == "<" && (top.owner == "ARRAY" || top.owner == "STRUCT"):
+			return CompletionType
+		case top.kind == "(" && (top.owner == "CAST" || top.owner == "SAFE_CAST") && last.Kind == "AS":
+			return CompletionType
+		case top.kind == "(" && top.owner == "EXTRACT" && top.index == len(toks)-1:
+			return CompletionDateTimePart
+		case top.kind == "(" && last.Kind == "," && slices.Contains(dateTimePartFunctions, top.owner):
+			return CompletionDateTimePart
+		}
+	}
+	switch {
+	case last.Kind == "TO":
+		return CompletionDateTimePart
+	case beforeLast.Kind == "INTERVAL" && last.Kind != "(" && last.Kind != "-":
+		return CompletionDateTimePart
+	case len(toks) > 2 && toks[len(toks)-3].Kind == "INTERVAL" && beforeLast.Kind == "-":
+		return CompletionDateTimePart
+	}
+	return CompletionKeyword
+}
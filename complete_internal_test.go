@@ -0,0 +1,20 @@
+package memebridge
+
+import "testing"
+
+func TestCompletionListsHaveNoDuplicates(t *testing.T) {
+	for name, list := range map[string][]string{
+		"completionKeywords":      completionKeywords,
+		"completionTypes":         completionTypes,
+		"completionDateTimeParts": completionDateTimeParts,
+		"completionFunctions":     completionFunctions,
+	} {
+		seen := make(map[string]bool, len(list))
+		for _, s := range list {
+			if seen[s] {
+				t.Errorf("%s lists %s more than once", name, s)
+			}
+			seen[s] = true
+		}
+	}
+}
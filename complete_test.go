@@ -0,0 +1,62 @@
+package memebridge_test
+
+import (
+	"testing"
+
+	"cloud.google.com/go/spanner"
+	"github.com/apstndb/spanvalue/gcvctor"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/apstndb/memebridge"
+)
+
+func TestComplete(t *testing.T) {
+	params := map[string]spanner.GenericColumnValue{
+		"limit": gcvctor.Int64Value(10),
+		"label": gcvctor.StringValue("x"),
+		"id":    gcvctor.Int64Value(1),
+	}
+	tests := []struct {
+		text      string
+		wantStart int
+		want      []memebridge.Completion
+	}{
+		{"@l", 1, []memebridge.Completion{{"label", memebridge.CompletionParam}, {"limit", memebridge.CompletionParam}}},
+		{"1 + @", 5, []memebridge.Completion{{"id", memebridge.CompletionParam}, {"label", memebridge.CompletionParam}, {"limit", memebridge.CompletionParam}}},
+		{"CAST(x AS ST", 10, []memebridge.Completion{{"STRING", memebridge.CompletionType}, {"STRUCT", memebridge.CompletionType}}},
+		{"safe_cast((1) as flo", 17, []memebridge.Completion{{"FLOAT32", memebridge.CompletionType}, {"FLOAT64", memebridge.CompletionType}}},
+		{"ARRAY<STRUCT<a IN", 15, []memebridge.Completion{{"INT64", memebridge.CompletionType}, {"INTERVAL", memebridge.CompletionType}}},
+		{"EXTRACT(MI", 8, []memebridge.Completion{{"MICROSECOND", memebridge.CompletionDateTimePart}, {"MILLISECOND", memebridge.CompletionDateTimePart}, {"MINUTE", memebridge.CompletionDateTimePart}}},
+		{"INTERVAL 1 H", 11, []memebridge.Completion{{"HOUR", memebridge.CompletionDateTimePart}}},
+		{"INTERVAL -1 H", 12, []memebridge.Completion{{"HOUR", memebridge.CompletionDateTimePart}}},
+		{`INTERVAL "1:2" HOUR TO MIN`, 23, []memebridge.Completion{{"MINUTE", memebridge.CompletionDateTimePart}}},
+		{`DATE_TRUNC(DATE "2024-01-01", Q`, 30, []memebridge.Completion{{"QUARTER", memebridge.CompletionDateTimePart}}},
+		{"1 + date_a", 4, []memebridge.Completion{{"DATE_ADD", memebridge.CompletionFunction}}},
+		{"NU", 0, []memebridge.Completion{{"NULL", memebridge.CompletionKeyword}, {"NULLIF", memebridge.CompletionFunction}, {"NUMERIC", memebridge.CompletionKeyword}}},
+		{"net.ip_to", 0, []memebridge.Completion{{"NET.IP_TO_STRING", memebridge.CompletionFunction}}},
+		{"CAST(1 AS INT64) + CAST", 19, []memebridge.Completion{{"CAST", memebridge.CompletionKeyword}}},
+		{`"unterminated AR`, 14, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			start, got := memebridge.Complete(tt.text, len(tt.text), memebridge.WithParams(params))
+			if start != tt.wantStart {
+				t.Errorf("start = %d, want %d", start, tt.wantStart)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("candidates mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCompleteMidText(t *testing.T) {
+	start, got := memebridge.Complete("CAST(1 AS DA) + 1", len("CAST(1 AS DA"))
+	want := []memebridge.Completion{{"DATE", memebridge.CompletionType}}
+	if start != len("CAST(1 AS ") {
+		t.Errorf("start = %d", start)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("candidates mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,181 @@
+package memebridge
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/spanner"
+	"github.com/apstndb/spanvalue/gcvctor"
+	"github.com/cloudspannerecosystem/memefish/ast"
+)
+
+// EvalDefault evaluates the DEFAULT expression of c with the column type as
+// expected type (see [WithExpectedType]), so DEFAULT ('2024-01-01') of a
+// DATE column is a DATE. A column without DEFAULT gives a NULL of its type,
+// which is what Spanner writes for an omitted nullable column. Only
+// constant expressions evaluate; DEFAULT (CURRENT_TIMESTAMP()) and other
+// functions return an error wrapping [ErrUnsupportedExpr], except
+// PENDING_COMMIT_TIMESTAMP().
+func (c *Column) EvalDefault(opts ...EvalOption) (spanner.GenericColumnValue, error) {
+	if c.Default == nil {
+		return gcvctor.NullOf(c.Type), nil
+	}
+	gcv, err := MemefishExprToGCV(c.Default, append(opts[:len(opts):len(opts)], WithExpectedType(c.Type))...)
+	if err != nil {
+		return zeroGCV, fmt.Errorf("column %s: DEFAULT: %w", c.Name, err)
+	}
+	return gcv, nil
+}
+
+// EvalGenerated evaluates the expression of a generated column over the
+// other columns of a row (see [WithColumns]) and coerces the result to the
+// column type. It is an error if c is not a generated column.
+func (c *Column) EvalGenerated(columns map[string]spanner.GenericColumnValue, opts ...EvalOption) (spanner.GenericColumnValue, error) {
+	if c.Generated == nil {
+		return zeroGCV, fmt.Errorf("column %s is not a generated column", c.Name)
+	}
+	gcv, err := MemefishExprToGCV(c.Generated, append(opts[:len(opts):len(opts)], WithColumns(columns), WithExpectedType(c.Type))...)
+	if err != nil {
+		return zeroGCV, fmt.Errorf("column %s: generated expression: %w", c.Name, err)
+	}
+	return gcv, nil
+}
+
+// DefaultValues returns the values of the columns of t that have a DEFAULT
+// and are missing from columns, evaluated with [Column.EvalDefault], for
+// filling the omitted columns of an insert mutation client-side. Column
+// names in columns are matched case-insensitively. Columns without DEFAULT,
+// including generated and IDENTITY columns, are left out, and so are
+// columns whose DEFAULT is not constant, such as CURRENT_TIMESTAMP(), so
+// that Spanner fills them when the column is omitted from the mutation.
+// Other evaluation errors are returned.
+func (t *Table) DefaultValues(columns map[string]spanner.GenericColumnValue, opts ...EvalOption) (map[string]spanner.GenericColumnValue, error) {
+	present := upperNames(columns)
+	result := make(map[string]spanner.GenericColumnValue)
+	for _, c := range t.Columns {
+		if c.Default == nil || present[strings.ToUpper(c.Name)] {
+			continue
+		}
+		gcv, err := c.EvalDefault(opts...)
+		if errors.Is(err, ErrUnsupportedExpr) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("table %s: %w", t.Name, err)
+		}
+		result[c.Name] = gcv
+	}
+	return result, nil
+}
+
+// GeneratedValues returns the values of the generated columns of t for the
+// row given by columns, as Spanner computes them on write (STORED) or on
+// read. Non-generated columns missing from columns are NULL; callers
+// inserting a row add [Table.DefaultValues] to columns first. Generated
+// columns may reference other generated columns in any order, but not in a
+// cycle.
+func (t *Table) GeneratedValues(columns map[string]spanner.GenericColumnValue, opts ...EvalOption) (map[string]spanner.GenericColumnValue, error) {
+	present := upperNames(columns)
+	env := make(map[string]spanner.GenericColumnValue, len(t.Columns))
+	var pending []*Column
+	for _, c := range t.Columns {
+		if c.Generated != nil {
+			pending = append(pending, c)
+			continue
+		}
+		if !present[strings.ToUpper(c.Name)] {
+			env[c.Name] = gcvctor.NullOf(c.Type)
+		}
+	}
+	// Values given for generated columns are recomputed, not trusted.
+	for name, gcv := range columns {
+		if c, ok := t.Column(name); !ok || c.Generated == nil {
+			env[name] = gcv
+		}
+	}
+
+	result := make(map[string]spanner.GenericColumnValue, len(pending))
+	// Each pass evaluates the columns whose references are all known, so a
+	// pass without progress means a reference to an unknown column or a
+	// cycle, and the first error of that pass is returned.
+	for len(pending) > 0 {
+		var (
+			next     []*Column
+			firstErr error
+		)
+		for _, c := range pending {
+			gcv, err := c.EvalGenerated(env, opts...)
+			if errors.Is(err, ErrUnrecognizedName) {
+				next = append(next, c)
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("table %s: %w", t.Name, err)
+			}
+			env[c.Name] = gcv
+			result[c.Name] = gcv
+		}
+		if len(next) == len(pending) {
+			if names, ok := t.referenceCycle(pending); ok {
+				return nil, fmt.Errorf("table %s: generated columns %s reference each other", t.Name, strings.Join(names, ", "))
+			}
+			return nil, fmt.Errorf("table %s: %w", t.Name, firstErr)
+		}
+		pending = next
+	}
+	return result, nil
+}
+
+// referenceCycle reports whether the generated columns in pending, none of
+// which could be evaluated, refer only to columns of t, which means that
+// they depend on each other. It returns their names.
+func (t *Table) referenceCycle(pending []*Column) ([]string, bool) {
+	names := make([]string, len(pending))
+	for i, c := range pending {
+		for name := range referencedNames(c.Generated) {
+			if _, ok := t.Column(name); !ok {
+				return nil, false
+			}
+		}
+		names[i] = c.Name
+	}
+	return names, true
+}
+
+// referencedNames returns the names that expr refers to as columns; the
+// names of functions and STRUCT fields are not included.
+func referencedNames(expr ast.Expr) map[string]bool {
+	names := make(map[string]bool)
+	var visit func(ast.Node) bool
+	visit = func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Ident:
+			names[n.Name] = true
+		case *ast.Path:
+			names[n.Idents[0].Name] = true
+			return false
+		case *ast.SelectorExpr:
+			ast.Inspect(n.Expr, visit)
+			return false
+		case *ast.CallExpr:
+			ast.InspectMany(n.Args, visit)
+			return false
+		}
+		return true
+	}
+	ast.Inspect(expr, visit)
+	return names
+}
+
+// upperNames returns the set of the upper-cased keys of columns.
+func upperNames(columns map[string]spanner.GenericColumnValue) map[string]bool {
+	names := make(map[string]bool, len(columns))
+	for name := range columns {
+		names[strings.ToUpper(name)] = true
+	}
+	return names
+}
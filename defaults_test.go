@@ -0,0 +1,118 @@
+package memebridge_test
+
+import (
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/spanner"
+	"github.com/apstndb/spanvalue/gcvctor"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/apstndb/memebridge"
+)
+
+const defaultsSchemaDDL = `
+CREATE TABLE Orders (
+  OrderId INT64 NOT NULL,
+  Price FLOAT64,
+  Quantity INT64 NOT NULL DEFAULT (1),
+  Status STRING(MAX) DEFAULT ('NEW'),
+  OrderedOn DATE DEFAULT ('2024-01-01'),
+  Total FLOAT64 AS (Subtotal + Shipping) STORED,
+  Subtotal FLOAT64 AS (Price * Quantity) STORED,
+  Shipping FLOAT64 AS (CAST(Quantity AS FLOAT64) * 0.5),
+  UpdatedAt TIMESTAMP,
+  CreatedAt TIMESTAMP DEFAULT (CURRENT_TIMESTAMP()),
+) PRIMARY KEY (OrderId);
+
+ALTER TABLE Orders ALTER COLUMN UpdatedAt SET DEFAULT (TIMESTAMP '2024-01-01T00:00:00Z');
+ALTER TABLE Orders ALTER COLUMN Status DROP DEFAULT;
+`
+
+func TestTableDefaultValues(t *testing.T) {
+	schema, err := memebridge.ParseSchema("schema.sql", defaultsSchemaDDL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orders, _ := schema.Table("Orders")
+
+	got, err := orders.DefaultValues(map[string]spanner.GenericColumnValue{
+		"orderid":  gcvctor.Int64Value(1),
+		"quantity": gcvctor.Int64Value(3),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]spanner.GenericColumnValue{
+		"OrderedOn": must(memebridge.ParseExprToGCV(`DATE '2024-01-01'`)),
+		"UpdatedAt": must(memebridge.ParseExprToGCV(`TIMESTAMP '2024-01-01T00:00:00Z'`)),
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	createdAt, _ := orders.Column("CreatedAt")
+	if _, err := createdAt.EvalDefault(); !errors.Is(err, memebridge.ErrUnsupportedExpr) {
+		t.Errorf("EvalDefault of CURRENT_TIMESTAMP() err = %v, want ErrUnsupportedExpr", err)
+	}
+
+	status, _ := orders.Column("Status")
+	if got, err := status.EvalDefault(); err != nil || !cmp.Equal(gcvctor.NullOf(status.Type), got, protocmp.Transform()) {
+		t.Errorf("EvalDefault without DEFAULT = %v, %v; want NULL", got, err)
+	}
+}
+
+func TestTableGeneratedValues(t *testing.T) {
+	schema, err := memebridge.ParseSchema("schema.sql", defaultsSchemaDDL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orders, _ := schema.Table("Orders")
+
+	got, err := orders.GeneratedValues(map[string]spanner.GenericColumnValue{
+		"OrderId":  gcvctor.Int64Value(1),
+		"Price":    gcvctor.Float64Value(2.5),
+		"Quantity": gcvctor.Int64Value(4),
+		"Total":    gcvctor.Float64Value(0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]spanner.GenericColumnValue{
+		"Subtotal": gcvctor.Float64Value(10),
+		"Shipping": gcvctor.Float64Value(2),
+		"Total":    gcvctor.Float64Value(12),
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	// A missing non-generated column is NULL.
+	got, err = orders.GeneratedValues(map[string]spanner.GenericColumnValue{"OrderId": gcvctor.Int64Value(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total := got["Total"]; !cmp.Equal(gcvctor.NullOf(total.Type), total, protocmp.Transform()) {
+		t.Errorf("Total = %v, want NULL", total)
+	}
+
+	bad, err := memebridge.ParseSchema("schema.sql", `CREATE TABLE T (Id INT64, X INT64 AS (Missing + 1)) PRIMARY KEY (Id)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, _ := bad.Table("T")
+	if _, err := table.GeneratedValues(nil); !errors.Is(err, memebridge.ErrUnrecognizedName) {
+		t.Errorf("unknown reference: err = %v, want ErrUnrecognizedName", err)
+	}
+
+	cyclic, err := memebridge.ParseSchema("schema.sql", `CREATE TABLE T (Id INT64, C1 INT64 AS (C2 + Id), C2 INT64 AS (CAST(c1 AS INT64))) PRIMARY KEY (Id)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, _ = cyclic.Table("T")
+	_, err = table.GeneratedValues(nil)
+	if want := "table T: generated columns C1, C2 reference each other"; err == nil || err.Error() != want {
+		t.Errorf("cycle: err = %v, want %q", err, want)
+	}
+}
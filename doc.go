@@ -16,16 +16,60 @@
 // ParseExprToGCV parses a SQL expression string and returns a GenericColumnValue.
 // ParseExprFile is the same with a filename for memefish error positions.
 // MemefishExprToGCV converts an already-parsed ast.Expr. MemefishTypeToSpannerpbType
-// maps ast.Type to spannerpb.Type.
+// maps ast.Type to spannerpb.Type. CastGCV applies CAST semantics to an
+// already-built value. ParseSchemaType and FormatSchemaType convert column
+// types in DDL and INFORMATION_SCHEMA spelling, such as STRING(MAX) and
+// ARRAY<FLOAT32>(vector_length=>128), to and from spannerpb.Type and the
+// TypeConstraints it does not carry.
+//
+// ParseSchema builds a Schema from DDL; Schema.InferParamTypes infers query
+// parameter types from the columns they are compared with or assigned to, for
+// use with WithExpectedType. WithParams binds @name references to values,
+// and WithColumns and WithRow bind column names, so that filters and CHECK
+// expressions such as Price * Quantity > 100 evaluate against a row.
+// Table.DefaultValues evaluates the DEFAULT expressions of columns omitted
+// from a row, and Table.GeneratedValues the expressions of generated
+// columns over a row.
+// ValidateColumnValue, Column.Validate and Table.ValidateColumns check values
+// against column types, NOT NULL and length limits before a mutation is
+// sent.
+// ValidateType checks a type against what Spanner accepts as a query
+// parameter, column, result column or key column, and WithParamContext
+// applies the query parameter rules to evaluated values.
+// ValidateGCV checks the wire values of a GenericColumnValue from elsewhere,
+// such as a decoded REST response, against its type, reporting the path to
+// the first invalid value.
+// ParamsToProto, ParamsFromRequest and their REST JSON counterparts convert
+// parameter maps to and from the params and param_types request fields,
+// with ToProtoValue and ArrayWireValues for the wire values.
+//
+// ParseDMLMutations and DMLToMutations convert INSERT, UPDATE and DELETE
+// statements with literal values to spanner.Mutation values for bulk loads,
+// using an optional Schema for column types and primary keys. ParseKey,
+// ParseKeyRange and ParseKeySet parse Read API keys such as (1, 'abc') and
+// [(1), (2)) to spanner.Key, KeyRange and KeySet.
+//
+// ParseQueryToResultSet and MemefishQueryToResultSet evaluate queries that
+// read no tables, such as SELECT over UNNEST and GENERATE_ARRAY, to a
+// spannerpb.ResultSet for test fixtures. ParseExprToRow and ParseExprToRows
+// build *spanner.Row fixtures from STRUCT and ARRAY<STRUCT> literals, and
+// RowIterator iterates them like a *spanner.RowIterator.
+//
+// Complete returns completion candidates (type, function, date/time part and
+// parameter names) for interactive shells.
 //
 // The cliparams subpackage parses CLI-style name:value parameter assignments.
+// The fakespanner subpackage serves literal-only queries over the Cloud
+// Spanner gRPC API for hermetic client tests.
+// The memebridge command (cmd/memebridge) exposes these entry points on the
+// command line.
 //
 // # Semantic source of truth
 //
 // Literal evaluation and CAST behavior aim to match Cloud Spanner (and
 // googlesql cast tables). Temporal casts without an explicit time zone use
-// America/Los_Angeles. Build with the memebridge_tzdata tag to embed IANA
-// tzdata on minimal runtimes.
+// America/Los_Angeles unless [WithTimeZone] sets another zone. Build with
+// the memebridge_tzdata tag to embed IANA tzdata on minimal runtimes.
 //
 // # Special contracts
 //
@@ -34,6 +78,10 @@
 // "spanner.commit_timestamp()". Downstream Spanner clients interpret this
 // sentinel; memebridge preserves it through TIMESTAMP→STRING casts.
 //
+// Errors quote the offending expression and value. [WithRedactedValues]
+// replaces them with [RedactedPlaceholder] for inputs that must not reach
+// logs; error kinds and types are kept.
+//
 // Array literals require elements to coerce to the declared or inferred element
 // type by default. Use [WithLegacyArrayWirePassthrough] on [MemefishExprToGCV],
 // [ParseExprToGCV], or [ParseExprFile] to restore pre-v0.7 behavior that
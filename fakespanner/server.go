@@ -0,0 +1,360 @@
+// Package fakespanner is an in-memory Cloud Spanner gRPC server for hermetic
+// tests of tools that send literal-only queries, such as spanner-mycli style
+// CLIs. It answers ExecuteSql and ExecuteStreamingSql by evaluating the query
+// with [memebridge.MemefishQueryToResultSet], so a real spanner.Client can
+// run SELECT 1, SELECT @p or SELECT over UNNEST without the emulator:
+//
+//	srv := fakespanner.NewServer()
+//	if err := srv.Start(); err != nil { ... }
+//	defer srv.Stop()
+//	client, err := spanner.NewClient(ctx, "projects/p/instances/i/databases/d", srv.ClientOptions()...)
+//
+// Sessions and transactions are accepted but hold no state beyond their
+// names; there are no tables, so Read, DML and mutations are not supported.
+// Bound params and param_types are decoded back into GenericColumnValues
+// with [memebridge.ParamsFromRequest].
+// In PLAN mode, parameters that the query references but the request does
+// not declare are returned as ResultSetMetadata.UndeclaredParameters, typed
+// from the [WithSchema] columns they are compared with, else INT64. This
+// works even for queries that read tables, whose plan has no row type.
+//
+// memebridge errors are mapped to gRPC status codes: syntax and evaluation
+// errors to InvalidArgument, and unsupported queries, expressions, types and
+// casts to Unimplemented.
+package fakespanner
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spantype/typector"
+	"github.com/apstndb/spanvalue/gcvctor"
+	"github.com/cloudspannerecosystem/memefish"
+	"github.com/cloudspannerecosystem/memefish/ast"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/apstndb/memebridge"
+	"github.com/apstndb/memebridge/cliparams"
+)
+
+// Option configures a [Server].
+type Option func(*Server)
+
+// WithEvalOptions sets options applied to every query evaluation, such as
+// [memebridge.WithTimeZone]. Request parameters are bound after them.
+func WithEvalOptions(opts ...memebridge.EvalOption) Option {
+	return func(s *Server) {
+		s.evalOpts = append(s.evalOpts, opts...)
+	}
+}
+
+// WithSchema sets the schema used to infer the types of undeclared
+// parameters in PLAN mode. Its tables are not readable.
+func WithSchema(schema *memebridge.Schema) Option {
+	return func(s *Server) {
+		s.schema = schema
+	}
+}
+
+// Server implements [sppb.SpannerServer]. The zero value is not usable; use
+// [NewServer].
+type Server struct {
+	sppb.UnimplementedSpannerServer
+
+	evalOpts []memebridge.EvalOption
+	schema   *memebridge.Schema
+
+	mu       sync.Mutex
+	sessions map[string]*sppb.Session
+	nextID   uint64
+	grpc     *grpc.Server
+	lis      net.Listener
+}
+
+// NewServer returns a server with no sessions.
+func NewServer(opts ...Option) *Server {
+	s := &Server{sessions: make(map[string]*sppb.Session)}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Register registers s on gs, for serving on a listener of the caller's
+// choice, such as an in-process bufconn listener.
+func (s *Server) Register(gs *grpc.Server) {
+	sppb.RegisterSpannerServer(gs, s)
+}
+
+// Start serves s on a loopback TCP port until [Server.Stop].
+func (s *Server) Start() error {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("fakespanner: %w", err)
+	}
+	gs := grpc.NewServer()
+	s.Register(gs)
+	s.mu.Lock()
+	s.grpc, s.lis = gs, lis
+	s.mu.Unlock()
+	go gs.Serve(lis)
+	return nil
+}
+
+// Addr returns the address [Server.Start] listens on.
+func (s *Server) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lis == nil {
+		return ""
+	}
+	return s.lis.Addr().String()
+}
+
+// ClientOptions returns options that connect a spanner.Client to the
+// address [Server.Start] listens on, without TLS or credentials.
+func (s *Server) ClientOptions() []option.ClientOption {
+	return []option.ClientOption{
+		option.WithEndpoint(s.Addr()),
+		option.WithoutAuthentication(),
+		option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	}
+}
+
+// Stop stops a server started by [Server.Start], closing open connections.
+func (s *Server) Stop() {
+	s.mu.Lock()
+	gs := s.grpc
+	s.grpc, s.lis = nil, nil
+	s.mu.Unlock()
+	if gs != nil {
+		gs.Stop()
+	}
+}
+
+func (s *Server) newSession(database string, multiplexed bool) *sppb.Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	session := &sppb.Session{
+		Name:        database + "/sessions/" + strconv.FormatUint(s.nextID, 10),
+		CreateTime:  timestamppb.Now(),
+		Multiplexed: multiplexed,
+	}
+	s.sessions[session.Name] = session
+	return session
+}
+
+func (s *Server) session(name string) (*sppb.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[name]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "Session not found: %s", name)
+	}
+	return session, nil
+}
+
+func (s *Server) newTransaction() *sppb.Transaction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	return &sppb.Transaction{Id: binary.BigEndian.AppendUint64(nil, s.nextID), ReadTimestamp: timestamppb.Now()}
+}
+
+// CreateSession implements [sppb.SpannerServer].
+func (s *Server) CreateSession(_ context.Context, req *sppb.CreateSessionRequest) (*sppb.Session, error) {
+	return s.newSession(req.GetDatabase(), req.GetSession().GetMultiplexed()), nil
+}
+
+// BatchCreateSessions implements [sppb.SpannerServer].
+func (s *Server) BatchCreateSessions(_ context.Context, req *sppb.BatchCreateSessionsRequest) (*sppb.BatchCreateSessionsResponse, error) {
+	resp := &sppb.BatchCreateSessionsResponse{}
+	for range req.GetSessionCount() {
+		resp.Session = append(resp.Session, s.newSession(req.GetDatabase(), false))
+	}
+	return resp, nil
+}
+
+// GetSession implements [sppb.SpannerServer].
+func (s *Server) GetSession(_ context.Context, req *sppb.GetSessionRequest) (*sppb.Session, error) {
+	return s.session(req.GetName())
+}
+
+// ListSessions implements [sppb.SpannerServer].
+func (s *Server) ListSessions(_ context.Context, req *sppb.ListSessionsRequest) (*sppb.ListSessionsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	resp := &sppb.ListSessionsResponse{}
+	prefix := req.GetDatabase() + "/sessions/"
+	for name, session := range s.sessions {
+		if strings.HasPrefix(name, prefix) {
+			resp.Sessions = append(resp.Sessions, session)
+		}
+	}
+	slices.SortFunc(resp.Sessions, func(a, b *sppb.Session) int { return strings.Compare(a.GetName(), b.GetName()) })
+	return resp, nil
+}
+
+// DeleteSession implements [sppb.SpannerServer].
+func (s *Server) DeleteSession(_ context.Context, req *sppb.DeleteSessionRequest) (*emptypb.Empty, error) {
+	if _, err := s.session(req.GetName()); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	delete(s.sessions, req.GetName())
+	s.mu.Unlock()
+	return &emptypb.Empty{}, nil
+}
+
+// BeginTransaction implements [sppb.SpannerServer].
+func (s *Server) BeginTransaction(_ context.Context, req *sppb.BeginTransactionRequest) (*sppb.Transaction, error) {
+	if _, err := s.session(req.GetSession()); err != nil {
+		return nil, err
+	}
+	return s.newTransaction(), nil
+}
+
+// Commit implements [sppb.SpannerServer]. There are no tables, so only
+// transactions without mutations can commit.
+func (s *Server) Commit(_ context.Context, req *sppb.CommitRequest) (*sppb.CommitResponse, error) {
+	if _, err := s.session(req.GetSession()); err != nil {
+		return nil, err
+	}
+	if len(req.GetMutations()) > 0 {
+		return nil, status.Error(codes.Unimplemented, "fakespanner: mutations are not supported")
+	}
+	return &sppb.CommitResponse{CommitTimestamp: timestamppb.Now()}, nil
+}
+
+// Rollback implements [sppb.SpannerServer].
+func (s *Server) Rollback(_ context.Context, req *sppb.RollbackRequest) (*emptypb.Empty, error) {
+	if _, err := s.session(req.GetSession()); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// ExecuteSql implements [sppb.SpannerServer].
+func (s *Server) ExecuteSql(_ context.Context, req *sppb.ExecuteSqlRequest) (*sppb.ResultSet, error) {
+	return s.executeSQL(req)
+}
+
+// ExecuteStreamingSql implements [sppb.SpannerServer]. The whole result is
+// sent as a single PartialResultSet.
+func (s *Server) ExecuteStreamingSql(req *sppb.ExecuteSqlRequest, stream sppb.Spanner_ExecuteStreamingSqlServer) error {
+	rs, err := s.executeSQL(req)
+	if err != nil {
+		return err
+	}
+	prs := &sppb.PartialResultSet{Metadata: rs.GetMetadata(), Stats: rs.GetStats(), Last: true}
+	for _, row := range rs.GetRows() {
+		prs.Values = append(prs.Values, row.GetValues()...)
+	}
+	return stream.Send(prs)
+}
+
+func (s *Server) executeSQL(req *sppb.ExecuteSqlRequest) (*sppb.ResultSet, error) {
+	if _, err := s.session(req.GetSession()); err != nil {
+		return nil, err
+	}
+	stmt, err := memefish.ParseStatement("", req.GetSql())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	q, ok := stmt.(*ast.QueryStatement)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "fakespanner: only queries are supported, got %T", stmt)
+	}
+	params, err := memebridge.ParamsFromRequest(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	plan := req.GetQueryMode() == sppb.ExecuteSqlRequest_PLAN
+	var undeclared []*sppb.StructType_Field
+	if plan {
+		if undeclared, err = s.undeclaredParams(q, params); err != nil {
+			return nil, statusError(err)
+		}
+		for _, f := range undeclared {
+			params[f.GetName()] = gcvctor.NullOf(f.GetType())
+		}
+	}
+
+	opts := append(slices.Clip(s.evalOpts), memebridge.WithParams(params))
+	rs, err := memebridge.MemefishQueryToResultSet(q, opts...)
+	switch {
+	case plan && errors.Is(err, memebridge.ErrUnsupportedQuery):
+		// Report the parameters of queries that read tables without a row type.
+		rs = &sppb.ResultSet{Metadata: &sppb.ResultSetMetadata{}}
+	case err != nil:
+		return nil, statusError(err)
+	}
+	if req.GetTransaction().GetBegin() != nil {
+		rs.Metadata.Transaction = s.newTransaction()
+	}
+	switch req.GetQueryMode() {
+	case sppb.ExecuteSqlRequest_PLAN:
+		rs.Rows = nil
+		rs.Metadata.UndeclaredParameters = &sppb.StructType{Fields: undeclared}
+		rs.Stats = &sppb.ResultSetStats{QueryPlan: &sppb.QueryPlan{}}
+	case sppb.ExecuteSqlRequest_PROFILE:
+		rs.Stats = &sppb.ResultSetStats{QueryPlan: &sppb.QueryPlan{}}
+	}
+	return rs, nil
+}
+
+// undeclaredParams returns the parameters q references that are not in
+// params, sorted by name.
+func (s *Server) undeclaredParams(q *ast.QueryStatement, params map[string]spanner.GenericColumnValue) ([]*sppb.StructType_Field, error) {
+	inferred, err := s.schema.InferParamTypes(q)
+	if err != nil {
+		return nil, err
+	}
+	var fields []*sppb.StructType_Field
+	for _, name := range cliparams.ReferencedParams(q) {
+		if _, ok := params[name]; ok {
+			continue
+		}
+		typ, ok := inferred[name]
+		if !ok {
+			typ = typector.Int64()
+		}
+		fields = append(fields, &sppb.StructType_Field{Name: name, Type: typ})
+	}
+	return fields, nil
+}
+
+// statusError maps a memefish or memebridge error to a gRPC status.
+func statusError(err error) error {
+	var (
+		multi  memefish.MultiError
+		single *memefish.Error
+	)
+	code := codes.InvalidArgument
+	switch {
+	case errors.As(err, &multi), errors.As(err, &single):
+	case errors.Is(err, memebridge.ErrUnsupportedQuery),
+		errors.Is(err, memebridge.ErrUnsupportedExpr),
+		errors.Is(err, memebridge.ErrUnsupportedType),
+		errors.Is(err, memebridge.ErrUnsupportedCast):
+		code = codes.Unimplemented
+	}
+	return status.Error(code, err.Error())
+}
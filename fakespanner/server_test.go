@@ -0,0 +1,152 @@
+package fakespanner_test
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spantype/typector"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/apstndb/memebridge"
+	"github.com/apstndb/memebridge/fakespanner"
+)
+
+const database = "projects/p/instances/i/databases/d"
+
+func startServer(t *testing.T, opts ...fakespanner.Option) *fakespanner.Server {
+	t.Helper()
+	srv := fakespanner.NewServer(opts...)
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Stop)
+	return srv
+}
+
+func TestClientQuery(t *testing.T) {
+	ctx := context.Background()
+	srv := startServer(t)
+	client, err := spanner.NewClientWithConfig(ctx, database, spanner.ClientConfig{DisableNativeMetrics: true}, srv.ClientOptions()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	stmt := spanner.Statement{
+		SQL:    `SELECT x, @s AS s FROM UNNEST(GENERATE_ARRAY(1, @n)) AS x ORDER BY x DESC`,
+		Params: map[string]any{"n": int64(3), "s": "a"},
+	}
+	type row struct {
+		X int64
+		S string
+	}
+	var got []row
+	err = client.Single().Query(ctx, stmt).Do(func(r *spanner.Row) error {
+		var v row
+		if err := r.Columns(&v.X, &v.S); err != nil {
+			return err
+		}
+		got = append(got, v)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]row{{3, "a"}, {2, "a"}, {1, "a"}}, got); diff != "" {
+		t.Errorf("rows mismatch (-want +got):\n%s", diff)
+	}
+
+	// A read-only transaction begins inline on its first query.
+	txn := client.ReadOnlyTransaction()
+	defer txn.Close()
+	for range 2 {
+		if err := txn.Query(ctx, spanner.NewStatement("SELECT 1")).Do(func(*spanner.Row) error { return nil }); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err = client.Single().Query(ctx, spanner.NewStatement("SELECT * FROM Singers")).Next()
+	if spanner.ErrCode(err) != codes.Unimplemented {
+		t.Errorf("table scan: err = %v, want Unimplemented", err)
+	}
+	_, err = client.Single().Query(ctx, spanner.NewStatement("SELECT 1 +")).Next()
+	if spanner.ErrCode(err) != codes.InvalidArgument {
+		t.Errorf("syntax error: err = %v, want InvalidArgument", err)
+	}
+}
+
+func TestExecuteSqlPlan(t *testing.T) {
+	ctx := context.Background()
+	schema, err := memebridge.ParseSchema("schema.sql", "CREATE TABLE T (Id INT64, D DATE) PRIMARY KEY (Id)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := startServer(t, fakespanner.WithSchema(schema))
+	conn, err := grpc.NewClient(srv.Addr(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := sppb.NewSpannerClient(conn)
+	session, err := client.CreateSession(ctx, &sppb.CreateSessionRequest{Database: database})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rs, err := client.ExecuteSql(ctx, &sppb.ExecuteSqlRequest{
+		Session:   session.GetName(),
+		Sql:       `SELECT * FROM T WHERE D = @d`,
+		QueryMode: sppb.ExecuteSqlRequest_PLAN,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &sppb.ResultSetMetadata{
+		UndeclaredParameters: &sppb.StructType{Fields: []*sppb.StructType_Field{{Name: "d", Type: typector.Date()}}},
+	}
+	if diff := cmp.Diff(want, rs.GetMetadata(), protocmp.Transform()); diff != "" {
+		t.Errorf("table query metadata mismatch (-want +got):\n%s", diff)
+	}
+
+	rs, err = client.ExecuteSql(ctx, &sppb.ExecuteSqlRequest{
+		Session:    session.GetName(),
+		Sql:        `SELECT @a AS a, @b AS b FROM UNNEST([1]) AS x WHERE x = @n`,
+		ParamTypes: map[string]*sppb.Type{"a": typector.String()},
+		QueryMode:  sppb.ExecuteSqlRequest_PLAN,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = &sppb.ResultSetMetadata{
+		RowType: &sppb.StructType{Fields: []*sppb.StructType_Field{
+			{Name: "a", Type: typector.String()},
+			{Name: "b", Type: typector.Int64()},
+		}},
+		UndeclaredParameters: &sppb.StructType{Fields: []*sppb.StructType_Field{
+			{Name: "b", Type: typector.Int64()},
+			{Name: "n", Type: typector.Int64()},
+		}},
+	}
+	if diff := cmp.Diff(want, rs.GetMetadata(), protocmp.Transform()); diff != "" {
+		t.Errorf("metadata mismatch (-want +got):\n%s", diff)
+	}
+	if len(rs.GetRows()) != 0 {
+		t.Errorf("PLAN returned %d rows", len(rs.GetRows()))
+	}
+
+	_, err = client.ExecuteSql(ctx, &sppb.ExecuteSqlRequest{Session: session.GetName(), Sql: "SELECT @x"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("undefined parameter: err = %v, want InvalidArgument", err)
+	}
+	_, err = client.ExecuteSql(ctx, &sppb.ExecuteSqlRequest{Session: database + "/sessions/none", Sql: "SELECT 1"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("unknown session: err = %v, want NotFound", err)
+	}
+}
@@ -23,24 +23,22 @@ func normalizeValue(v *structpb.Value) *structpb.Value {
 	return v
 }
 
-// gcvToProtoValue is an unexported prototype for a requested root spanvalue API
-// (not gcvctor — result is *structpb.Value, not GenericColumnValue):
-// spanvalue.ToProtoValue(gcv spanner.GenericColumnValue) *structpb.Value.
-// It materializes protobuf NULL for SQL NULL cells before ARRAY/STRUCT assembly.
-func gcvToProtoValue(gcv spanner.GenericColumnValue) *structpb.Value {
+// ToProtoValue returns the wire value of gcv, with an explicit protobuf NULL
+// for SQL NULL, as required inside ARRAY and STRUCT values and in
+// ExecuteSqlRequest.params.
+func ToProtoValue(gcv spanner.GenericColumnValue) *structpb.Value {
 	if isNullGCV(gcv) {
 		return structpb.NewNullValue()
 	}
 	return normalizeValue(gcv.Value)
 }
 
-// gcvArrayWireValues is an unexported prototype for a requested root spanvalue API
-// (not gcvctor — result is []*structpb.Value):
-// spanvalue.ArrayWireValues(elems []spanner.GenericColumnValue) []*structpb.Value.
-func gcvArrayWireValues(elems []spanner.GenericColumnValue) []*structpb.Value {
+// ArrayWireValues returns the wire values of elems with [ToProtoValue], for
+// the ListValue of an ARRAY or STRUCT value.
+func ArrayWireValues(elems []spanner.GenericColumnValue) []*structpb.Value {
 	out := make([]*structpb.Value, len(elems))
 	for i, gcv := range elems {
-		out[i] = gcvToProtoValue(gcv)
+		out[i] = ToProtoValue(gcv)
 	}
 	return out
 }
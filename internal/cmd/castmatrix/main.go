@@ -0,0 +1,39 @@
+// Command castmatrix prints memebridge's CAST capability matrix as Markdown.
+//
+// Usage:
+//
+//	go run ./internal/cmd/castmatrix [-o FILE]
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/apstndb/memebridge"
+)
+
+func main() {
+	out := flag.String("o", "", "write the matrix to `FILE` instead of stdout")
+	flag.Parse()
+
+	if err := run(*out); err != nil {
+		fmt.Fprintln(os.Stderr, "castmatrix:", err)
+		os.Exit(1)
+	}
+}
+
+func run(out string) error {
+	var buf bytes.Buffer
+	buf.WriteString("# CAST capability matrix\n\n")
+	buf.WriteString("<!-- Code generated by go run ./internal/cmd/castmatrix; DO NOT EDIT. -->\n\n")
+	if err := memebridge.WriteCastMatrixMarkdown(&buf); err != nil {
+		return err
+	}
+	if out == "" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	return os.WriteFile(out, buf.Bytes(), 0o644)
+}
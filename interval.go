@@ -128,8 +128,8 @@ var (
 	}
 )
 
-func astIntervalLiteralsToGCV(expr ast.Expr) (spanner.GenericColumnValue, error) {
-	interval, err := astIntervalLiteralsToInterval(expr)
+func astIntervalLiteralsToGCV(expr ast.Expr, c castContext) (spanner.GenericColumnValue, error) {
+	interval, err := astIntervalLiteralsToInterval(expr, c)
 	if err != nil {
 		return zeroGCV, err
 	}
@@ -137,19 +137,21 @@ func astIntervalLiteralsToGCV(expr ast.Expr) (spanner.GenericColumnValue, error)
 	return gcvctor.IntervalValue(interval), nil
 }
 
-func astIntervalLiteralsToInterval(expr ast.Expr) (spanner.Interval, error) {
+// astIntervalLiteralsToInterval evaluates an INTERVAL literal. Errors quote
+// the literal value through c, so they respect redaction.
+func astIntervalLiteralsToInterval(expr ast.Expr, c castContext) (spanner.Interval, error) {
 	var zero spanner.Interval
 
 	switch e := expr.(type) {
 	case *ast.IntervalLiteralSingle:
 		intLiteral, ok := e.Value.(*ast.IntLiteral)
 		if !ok {
-			return zero, fmt.Errorf("expect int literal, but %v", e.Value)
+			return zero, fmt.Errorf("expect int literal, but %v", c.expr())
 		}
 
 		i, err := strconv.ParseInt(intLiteral.Value, intLiteral.Base, 64)
 		if err != nil {
-			return zero, err
+			return zero, c.cause(err)
 		}
 
 		durationString, err := toISO8601Duration(i, e.DateTimePart)
@@ -157,7 +159,11 @@ func astIntervalLiteralsToInterval(expr ast.Expr) (spanner.Interval, error) {
 			return zero, err
 		}
 
-		return spanner.ParseInterval(durationString)
+		interval, err := spanner.ParseInterval(durationString)
+		if err != nil {
+			return zero, c.cause(err)
+		}
+		return interval, nil
 	case *ast.IntervalLiteralRange:
 		start := e.StartingDateTimePart
 		mapForStart, ok := dateTimeRangeRegexpMap[start]
@@ -172,7 +178,7 @@ func astIntervalLiteralsToInterval(expr ast.Expr) (spanner.Interval, error) {
 		}
 
 		if !re.MatchString(e.Value.Value) {
-			return zero, fmt.Errorf("interval literal with a datetime part range is not valid: sql: %v, regexp: %v", e.Value.Value, re.String())
+			return zero, fmt.Errorf("interval literal with a datetime part range is not valid: sql: %v, regexp: %v", c.value(e.Value.Value), re.String())
 		}
 
 		matches := re.FindStringSubmatch(e.Value.Value)
@@ -206,12 +212,12 @@ func astIntervalLiteralsToInterval(expr ast.Expr) (spanner.Interval, error) {
 			case "second":
 				second, ok = second.SetString(s)
 				if !ok {
-					return zero, fmt.Errorf("invalid second: %v", s)
+					return zero, fmt.Errorf("invalid second: %v", c.value(s))
 				}
 			}
 
 			if err != nil {
-				return zero, err
+				return zero, c.cause(err)
 			}
 		}
 
@@ -219,7 +225,7 @@ func astIntervalLiteralsToInterval(expr ast.Expr) (spanner.Interval, error) {
 			big.NewRat(timeSign*1_000_000_000, 1),
 			new(big.Rat).Add(big.NewRat(hour*3600+minute*60, 1), second))
 		if !nanosRat.IsInt() {
-			return zero, fmt.Errorf("invalid non-integer nanoseconds: %v", nanosRat)
+			return zero, fmt.Errorf("invalid non-integer nanoseconds: %v", c.number(nanosRat))
 		}
 
 		return spanner.Interval{
@@ -228,6 +234,6 @@ func astIntervalLiteralsToInterval(expr ast.Expr) (spanner.Interval, error) {
 			Nanos:  nanosRat.Num(),
 		}, nil
 	default:
-		return zero, fmt.Errorf("expr is not interval literal: %v", e)
+		return zero, fmt.Errorf("expr is not interval literal: %v", c.expr())
 	}
 }
@@ -0,0 +1,266 @@
+package memebridge
+
+import (
+	"errors"
+	"fmt"
+
+	"cloud.google.com/go/spanner"
+	"github.com/cloudspannerecosystem/memefish"
+	"github.com/cloudspannerecosystem/memefish/token"
+)
+
+// ParseKey parses a primary key for the Read API: a parenthesized tuple of
+// key parts such as (1, 'abc'), a single part such as 1, or () for the
+// empty key. Parts are evaluated with [MemefishExprToGCV] and converted to
+// the Go types the spanner client expects in a spanner.Key: int64, string,
+// civil.Date, time.Time, big.Rat and so on, or the spanner.Null* type for
+// NULL.
+//
+// table may be nil. Otherwise the key must have one part per primary key
+// column, and each part is evaluated with the column type as expected
+// type (see [WithExpectedType]), so '2024-01-01' is a DATE part for a DATE
+// key column.
+func ParseKey(sql string, table *Table, opts ...EvalOption) (spanner.Key, error) {
+	p, err := newKeyParser(sql, table, opts)
+	if err != nil {
+		return nil, err
+	}
+	return p.key(p.toks, true)
+}
+
+// ParseKeyRange parses a key range written with a bracket for a closed
+// bound and a parenthesis for an open one, with each bound a parenthesized
+// key as in [ParseKey]:
+//
+//	[(1, 'a'), (2, 'b'))   spanner.ClosedOpen
+//	((1), (2)]             spanner.OpenClosed
+//	[(1), (3)]             spanner.ClosedClosed
+//
+// Bounds may be prefixes of the primary key of table. As in Spanner, a
+// closed prefix bound includes every key that starts with it, so [(1), ()]
+// extends to the end of the table. ((1), (2)) is an open range, not a key.
+func ParseKeyRange(sql string, table *Table, opts ...EvalOption) (spanner.KeyRange, error) {
+	p, err := newKeyParser(sql, table, opts)
+	if err != nil {
+		return spanner.KeyRange{}, err
+	}
+	r, ok, err := p.keyRange(p.toks)
+	if err != nil {
+		return spanner.KeyRange{}, err
+	}
+	if !ok {
+		return spanner.KeyRange{}, fmt.Errorf("key range must be [start, end], (start, end) or a mix, with parenthesized keys: %s", sql)
+	}
+	return r, nil
+}
+
+// ParseKeySet parses a comma-separated list of keys as in [ParseKey], key
+// ranges as in [ParseKeyRange], and prefix keys ending in *, which select
+// every row whose key starts with the given parts:
+//
+//	(1, 'a'), (2, 'b'), [(3), (5)), (6, *)
+//
+// ALL selects every row.
+func ParseKeySet(sql string, table *Table, opts ...EvalOption) (spanner.KeySet, error) {
+	p, err := newKeyParser(sql, table, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.toks) == 1 && p.toks[0].Kind == "ALL" {
+		return spanner.AllKeys(), nil
+	}
+	var sets []spanner.KeySet
+	for i, item := range splitKeyTokens(p.toks) {
+		set, err := p.keySetItem(item)
+		if err != nil {
+			return nil, fmt.Errorf("key set item %d: %w", i+1, err)
+		}
+		sets = append(sets, set)
+	}
+	return spanner.KeySets(sets...), nil
+}
+
+// keyParser parses keys from the tokens of text.
+type keyParser struct {
+	text  string
+	toks  []token.Token
+	table *Table
+	opts  []EvalOption
+}
+
+func newKeyParser(text string, table *Table, opts []EvalOption) (*keyParser, error) {
+	lex := &memefish.Lexer{File: &token.File{Buffer: text}}
+	p := &keyParser{text: text, table: table, opts: opts}
+	for {
+		if err := lex.NextToken(); err != nil {
+			return nil, err
+		}
+		if lex.Token.Kind == token.TokenEOF {
+			break
+		}
+		p.toks = append(p.toks, lex.Token)
+	}
+	if len(p.toks) == 0 {
+		return nil, errors.New("empty key")
+	}
+	return p, nil
+}
+
+func (p *keyParser) source(toks []token.Token) string {
+	return p.text[toks[0].Pos:toks[len(toks)-1].End]
+}
+
+func (p *keyParser) keySetItem(toks []token.Token) (spanner.KeySet, error) {
+	if len(toks) == 0 {
+		return nil, errors.New("empty item")
+	}
+	if r, ok, err := p.keyRange(toks); ok || err != nil {
+		return r, err
+	}
+	if inner, ok := parenthesized(toks); ok {
+		parts := splitKeyTokens(inner)
+		if last := parts[len(parts)-1]; len(last) == 1 && last[0].Kind == "*" {
+			key, err := p.keyParts(parts[:len(parts)-1], false)
+			if err != nil {
+				return nil, err
+			}
+			return key.AsPrefix(), nil
+		}
+	}
+	return p.key(toks, true)
+}
+
+// key parses a key from a parenthesized tuple or a single part. A full key
+// must cover the primary key of p.table; other keys may be prefixes.
+func (p *keyParser) key(toks []token.Token, full bool) (spanner.Key, error) {
+	if inner, ok := parenthesized(toks); ok {
+		if len(inner) == 0 {
+			return p.keyParts(nil, full)
+		}
+		return p.keyParts(splitKeyTokens(inner), full)
+	}
+	return p.keyParts([][]token.Token{toks}, full)
+}
+
+func (p *keyParser) keyParts(parts [][]token.Token, full bool) (spanner.Key, error) {
+	if p.table != nil {
+		pk := p.table.PrimaryKey
+		if len(parts) > len(pk) || full && len(parts) != len(pk) {
+			return nil, fmt.Errorf("key has %d parts, but the primary key of %s has %d", len(parts), p.table.Name, len(pk))
+		}
+	}
+	key := make(spanner.Key, len(parts))
+	for i, part := range parts {
+		if len(part) == 0 {
+			return nil, fmt.Errorf("key part %d is empty", i+1)
+		}
+		expr, err := memefish.ParseExpr("", p.source(part))
+		if err != nil {
+			return nil, err
+		}
+		opts := p.opts[:len(p.opts):len(p.opts)]
+		name := fmt.Sprintf("key part %d", i+1)
+		if p.table != nil {
+			name = p.table.PrimaryKey[i]
+			if col, ok := p.table.Column(name); ok {
+				opts = append(opts, WithExpectedType(col.Type))
+			}
+		}
+		gcv, err := MemefishExprToGCV(expr, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if key[i], err = keyPart(gcv); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return key, nil
+}
+
+// keyRange parses toks as a key range, reporting false if toks are not
+// one: a range is delimited by brackets or parentheses and holds two
+// parenthesized keys.
+func (p *keyParser) keyRange(toks []token.Token) (spanner.KeyRange, bool, error) {
+	n := len(toks)
+	if n < 2 || (toks[0].Kind != "[" && toks[0].Kind != "(") || (toks[n-1].Kind != "]" && toks[n-1].Kind != ")") {
+		return spanner.KeyRange{}, false, nil
+	}
+	if closing := matchingClose(toks, 0); closing != n-1 {
+		return spanner.KeyRange{}, false, nil
+	}
+	bounds := splitKeyTokens(toks[1 : n-1])
+	if len(bounds) != 2 {
+		return spanner.KeyRange{}, false, nil
+	}
+	for _, b := range bounds {
+		if _, ok := parenthesized(b); !ok {
+			return spanner.KeyRange{}, false, nil
+		}
+	}
+	start, err := p.key(bounds[0], false)
+	if err != nil {
+		return spanner.KeyRange{}, true, fmt.Errorf("range start: %w", err)
+	}
+	end, err := p.key(bounds[1], false)
+	if err != nil {
+		return spanner.KeyRange{}, true, fmt.Errorf("range end: %w", err)
+	}
+	kind := map[[2]token.TokenKind]spanner.KeyRangeKind{
+		{"[", "]"}: spanner.ClosedClosed,
+		{"[", ")"}: spanner.ClosedOpen,
+		{"(", "]"}: spanner.OpenClosed,
+		{"(", ")"}: spanner.OpenOpen,
+	}[[2]token.TokenKind{toks[0].Kind, toks[n-1].Kind}]
+	return spanner.KeyRange{Start: start, End: end, Kind: kind}, true, nil
+}
+
+// parenthesized returns the tokens inside toks if toks is a single
+// parenthesized group.
+func parenthesized(toks []token.Token) ([]token.Token, bool) {
+	n := len(toks)
+	if n < 2 || toks[0].Kind != "(" || toks[n-1].Kind != ")" || matchingClose(toks, 0) != n-1 {
+		return nil, false
+	}
+	return toks[1 : n-1], true
+}
+
+// matchingClose returns the index of the bracket or parenthesis closing
+// toks[open], or -1. Brackets and parentheses close each other, so that
+// the mixed delimiters of ranges balance.
+func matchingClose(toks []token.Token, open int) int {
+	depth := 0
+	for i := open; i < len(toks); i++ {
+		switch toks[i].Kind {
+		case "(", "[":
+			depth++
+		case ")", "]":
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitKeyTokens splits toks at commas outside brackets and parentheses.
+func splitKeyTokens(toks []token.Token) [][]token.Token {
+	var (
+		parts [][]token.Token
+		depth int
+		start int
+	)
+	for i, tok := range toks {
+		switch tok.Kind {
+		case "(", "[":
+			depth++
+		case ")", "]":
+			depth--
+		case ",":
+			if depth == 0 {
+				parts = append(parts, toks[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, toks[start:])
+}
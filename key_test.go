@@ -0,0 +1,129 @@
+package memebridge_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"cloud.google.com/go/spanner"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/apstndb/memebridge"
+)
+
+const keySchemaDDL = `
+CREATE TABLE Events (
+  Day DATE NOT NULL,
+  Id INT64 NOT NULL,
+  CreatedAt TIMESTAMP,
+  Amount NUMERIC,
+) PRIMARY KEY (Day, Id);
+`
+
+// keyCmpOpts compares big.Rat key parts by value.
+var keyCmpOpts = cmp.Comparer(func(a, b big.Rat) bool { return a.Cmp(&b) == 0 })
+
+func TestParseKey(t *testing.T) {
+	events := mustOk(must(memebridge.ParseSchema("schema.sql", keySchemaDDL)).Table("Events"))
+	day := civil.Date{Year: 2024, Month: 1, Day: 2}
+	tests := []struct {
+		sql   string
+		table *memebridge.Table
+		want  spanner.Key
+	}{
+		{`(1, 'abc')`, nil, spanner.Key{int64(1), "abc"}},
+		{`42`, nil, spanner.Key{int64(42)}},
+		{`()`, nil, spanner.Key{}},
+		{`(DATE '2024-01-02', NUMERIC '1.5', TIMESTAMP '2024-01-02T03:04:05Z', b'x', CAST(NULL AS STRING), TRUE, 1.5)`, nil,
+			spanner.Key{day, *big.NewRat(3, 2), time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), []byte("x"), spanner.NullString{}, true, 1.5}},
+		{`('2024-01-02', 7)`, events, spanner.Key{day, int64(7)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			got, err := memebridge.ParseKey(tt.sql, tt.table)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got, keyCmpOpts); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseKeyRange(t *testing.T) {
+	events := mustOk(must(memebridge.ParseSchema("schema.sql", keySchemaDDL)).Table("Events"))
+	got, err := memebridge.ParseKeyRange(`[('2024-01-01'), ('2024-01-02', 10))`, events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := spanner.KeyRange{
+		Start: spanner.Key{civil.Date{Year: 2024, Month: 1, Day: 1}},
+		End:   spanner.Key{civil.Date{Year: 2024, Month: 1, Day: 2}, int64(10)},
+		Kind:  spanner.ClosedOpen,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	for sql, kind := range map[string]spanner.KeyRangeKind{
+		`[(1), (2)]`: spanner.ClosedClosed,
+		`((1), (2)]`: spanner.OpenClosed,
+		`((1), (2))`: spanner.OpenOpen,
+	} {
+		got, err := memebridge.ParseKeyRange(sql, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Kind != kind {
+			t.Errorf("ParseKeyRange(%s).Kind = %v, want %v", sql, got.Kind, kind)
+		}
+	}
+}
+
+func TestParseKeySet(t *testing.T) {
+	got, err := memebridge.ParseKeySet(`(1, 'a'), [(3), (5)), (6, *)`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := spanner.KeySets(
+		spanner.Key{int64(1), "a"},
+		spanner.KeyRange{Start: spanner.Key{int64(3)}, End: spanner.Key{int64(5)}, Kind: spanner.ClosedOpen},
+		spanner.Key{int64(6)}.AsPrefix(),
+	)
+	if diff := cmp.Diff(want, got, mutationCmpOpts); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	all, err := memebridge.ParseKeySet(`all`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(spanner.AllKeys(), all, mutationCmpOpts); diff != "" {
+		t.Errorf("ALL mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseKeyErrors(t *testing.T) {
+	events := mustOk(must(memebridge.ParseSchema("schema.sql", keySchemaDDL)).Table("Events"))
+	for _, sql := range []string{
+		``,
+		`('2024-01-02')`,
+		`('2024-01-02', 1, 2)`,
+		`('x', 1)`,
+		`([1], 1)`,
+		`(1,)`,
+		`'unterminated`,
+	} {
+		if _, err := memebridge.ParseKey(sql, events); err == nil {
+			t.Errorf("ParseKey(%s): expected error", sql)
+		}
+	}
+	if _, err := memebridge.ParseKeyRange(`(1, 2)`, nil); err == nil {
+		t.Error("ParseKeyRange((1, 2)): expected error")
+	}
+	if _, err := memebridge.ParseKeySet(`(1), [(2), ('x'))`, events); err == nil {
+		t.Error("ParseKeySet: expected error")
+	}
+}
@@ -0,0 +1,42 @@
+package memebridge
+
+import (
+	"fmt"
+	"strings"
+
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spantype/typector"
+	"github.com/cloudspannerecosystem/memefish/ast"
+)
+
+// MemefishSchemaTypeToSpannerpbType maps a memefish DDL column type
+// (ast.SchemaType) to spannerpb.Type. Length limits such as STRING(MAX) or
+// BYTES(16) are dropped because spannerpb.Type does not carry them. Named
+// types other than UUID are PROTO or ENUM columns, which cannot be told
+// apart without descriptors, and return an error.
+func MemefishSchemaTypeToSpannerpbType(typ ast.SchemaType) (*sppb.Type, error) {
+	switch t := typ.(type) {
+	case *ast.ScalarSchemaType:
+		return memefishScalarTypeToSpannerpbType(t.Name)
+	case *ast.SizedSchemaType:
+		return memefishScalarTypeToSpannerpbType(t.Name)
+	case *ast.ArraySchemaType:
+		if t.Item == nil {
+			return nil, fmt.Errorf("invalid array schema type: %s", t.SQL())
+		}
+		elem, err := MemefishSchemaTypeToSpannerpbType(t.Item)
+		if err != nil {
+			return nil, err
+		}
+		return typector.ElemTypeToArrayType(elem), nil
+	case *ast.StructType:
+		return MemefishTypeToSpannerpbType(t)
+	case *ast.NamedType:
+		if len(t.Path) == 1 && strings.EqualFold(t.Path[0].Name, "UUID") {
+			return typector.UUID(), nil
+		}
+		return nil, fmt.Errorf("not known whether the named type is PROTO or ENUM: %s", t.SQL())
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, typ.SQL())
+	}
+}
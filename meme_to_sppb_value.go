@@ -34,7 +34,14 @@ var (
 	// ErrUnsupportedType is returned when MemefishTypeToSpannerpbType encounters a
 	// type kind it does not support.
 	ErrUnsupportedType = errors.New("unsupported type")
-	zeroGCV            spanner.GenericColumnValue
+	// ErrUndefinedParameter is returned when an expression references a query
+	// parameter that is not in the map given to [WithParams].
+	ErrUndefinedParameter = errors.New("undefined query parameter")
+	// ErrUnrecognizedName is returned when an expression references a column
+	// that is not bound with [WithColumns] or [WithRow], or not in the FROM
+	// clause of a query.
+	ErrUnrecognizedName = errors.New("unrecognized name")
+	zeroGCV             spanner.GenericColumnValue
 )
 
 func typelessStructLiteralArgToNameWithGCV(arg ast.TypelessStructLiteralArg, o evalOptions) (string, spanner.GenericColumnValue, error) {
@@ -111,7 +118,7 @@ func typedStructLiteralToGCV(expr *ast.TypedStructLiteral, o evalOptions) (spann
 		}
 		coerced, err := memefishExprToGCVWithExpectedType(fieldType, expr.Values[i], o)
 		if err != nil {
-			return zeroGCV, fmt.Errorf("cannot coerce typed struct field %d (%s): %w", i, expr.Values[i].SQL(), err)
+			return zeroGCV, fmt.Errorf("cannot coerce typed struct field %d (%s): %w", i, o.sql(expr.Values[i]), err)
 		}
 		names[i] = fieldNameOrEmpty(field)
 		gcvs[i] = coerced
@@ -134,7 +141,7 @@ func memefishExprToGCVWithExpectedType(expectedType *sppb.Type, expr ast.Expr, o
 				if err != nil {
 					return zeroGCV, err
 				}
-				return castGCV(gcv, expectedType, expr.SQL())
+				return castGCV(gcv, expectedType, o.castContext(expr.SQL()))
 			}
 			return arrayLiteralToGCVStrict(array, expectedType.GetArrayElementType(), o)
 		}
@@ -145,7 +152,7 @@ func memefishExprToGCVWithExpectedType(expectedType *sppb.Type, expr ast.Expr, o
 			if err != nil {
 				return zeroGCV, err
 			}
-			return castGCV(gcv, expectedType, expr.SQL())
+			return castGCV(gcv, expectedType, o.castContext(expr.SQL()))
 		case *ast.TypelessStructLiteral, *ast.TupleStructLiteral:
 			return structLiteralToGCVWithExpectedType(expectedType, unwrapped, o)
 		}
@@ -155,7 +162,7 @@ func memefishExprToGCVWithExpectedType(expectedType *sppb.Type, expr ast.Expr, o
 	if err != nil {
 		return zeroGCV, err
 	}
-	return coerceToExpectedType(expectedType, gcv, expr)
+	return coerceToExpectedType(expectedType, gcv, expr, o)
 }
 
 func structLiteralToGCVWithExpectedType(expectedType *sppb.Type, expr ast.Expr, o evalOptions) (spanner.GenericColumnValue, error) {
@@ -180,7 +187,7 @@ func structLiteralToGCVWithExpectedType(expectedType *sppb.Type, expr ast.Expr,
 		}
 		gcv, err := memefishExprToGCVWithExpectedType(field.Type, values[i], o)
 		if err != nil {
-			return zeroGCV, fmt.Errorf("cannot coerce struct field %d (%s): %w", i, values[i].SQL(), err)
+			return zeroGCV, fmt.Errorf("cannot coerce struct field %d (%s): %w", i, o.sql(values[i]), err)
 		}
 		names[i] = field.Name
 		gcvs[i] = gcv
@@ -216,6 +223,7 @@ func coerceToExpectedType(
 	expectedType *sppb.Type,
 	gcv spanner.GenericColumnValue,
 	expr ast.Expr,
+	o evalOptions,
 ) (spanner.GenericColumnValue, error) {
 	if retyped, err := gcvctor.WithEquivalentType(expectedType, gcv); err == nil {
 		return retyped, nil
@@ -231,7 +239,7 @@ func coerceToExpectedType(
 			"cannot coerce expression from %v to %v: %s",
 			gcv.Type.GetCode(),
 			expectedType.GetCode(),
-			expr.SQL(),
+			o.sql(expr),
 		)
 	}
 	if !canCoerceToExpectedType(expectedType, gcv.Type, expr) {
@@ -239,13 +247,13 @@ func coerceToExpectedType(
 			"cannot coerce expression from %v to %v: %s",
 			gcv.Type.GetCode(),
 			expectedType.GetCode(),
-			expr.SQL(),
+			o.sql(expr),
 		)
 	}
 	if isStringLiteralCoercion(expectedType, gcv.Type, expr) {
-		return coerceStringLiteralToExpectedType(expectedType, expr)
+		return coerceStringLiteralToExpectedType(expectedType, expr, o)
 	}
-	return castGCV(gcv, expectedType, expr.SQL())
+	return castGCV(gcv, expectedType, o.castContext(expr.SQL()))
 }
 
 func canCoerceToExpectedType(expectedType, valueType *sppb.Type, expr ast.Expr) bool {
@@ -319,26 +327,30 @@ func isStringLiteralCoercion(fieldType, valueType *sppb.Type, expr ast.Expr) boo
 func coerceStringLiteralToExpectedType(
 	expectedType *sppb.Type,
 	expr ast.Expr,
+	o evalOptions,
 ) (spanner.GenericColumnValue, error) {
 	lit, ok := unwrapParenExpr(expr).(*ast.StringLiteral)
 	if !ok {
-		return zeroGCV, fmt.Errorf("expected string literal for coercion: %s", expr.SQL())
+		return zeroGCV, fmt.Errorf("expected string literal for coercion: %s", o.sql(expr))
 	}
 	// GoogleSQL literal coercion is stricter than CAST parsing here. Do not
 	// trim whitespace; only canonical literal text should satisfy an expected
 	// DATE, TIMESTAMP, or UUID field type.
 	switch expectedType.GetCode() {
 	case sppb.TypeCode_DATE:
-		return gcvctor.DateStringValue(lit.Value)
+		gcv, err := gcvctor.DateStringValue(lit.Value)
+		return gcv, o.cause(err)
 	case sppb.TypeCode_TIMESTAMP:
-		return gcvctor.TimestampStringValue(lit.Value)
+		gcv, err := gcvctor.TimestampStringValue(lit.Value)
+		return gcv, o.cause(err)
 	case sppb.TypeCode_UUID:
+		c := o.castContext("")
 		u, err := uuid.Parse(lit.Value)
 		if err != nil {
-			return zeroGCV, fmt.Errorf("invalid UUID literal %q for expected type %v: %w", lit.Value, expectedType.GetCode(), err)
+			return zeroGCV, fmt.Errorf("invalid UUID literal %s for expected type %v: %w", c.value(lit.Value), expectedType.GetCode(), c.cause(err))
 		}
 		if !strings.EqualFold(u.String(), lit.Value) {
-			return zeroGCV, fmt.Errorf("invalid UUID literal %q for expected type %v", lit.Value, expectedType.GetCode())
+			return zeroGCV, fmt.Errorf("invalid UUID literal %s for expected type %v", c.value(lit.Value), expectedType.GetCode())
 		}
 		return gcvctor.UUIDValue(u), nil
 	default:
@@ -359,13 +371,35 @@ func extractValues(expr ast.Expr) ([]ast.Expr, error) {
 
 // MemefishExprToGCV evaluates a memefish expression AST node to a
 // GenericColumnValue. It handles literals, STRUCT and ARRAY literals, CAST and
-// SAFE_CAST, INTERVAL literals, and PENDING_COMMIT_TIMESTAMP().
+// SAFE_CAST, INTERVAL literals, PENDING_COMMIT_TIMESTAMP(), and query
+// parameters bound with [WithParams].
 //
 // Unsupported expression kinds return an error. By default, ARRAY<T> literals
 // require elements to coerce to T; use [WithLegacyArrayWirePassthrough] to
-// restore pre-v0.7 permissive wire preservation on coercion failure.
+// restore pre-v0.7 permissive wire preservation on coercion failure. Use
+// [WithExpectedType] to coerce the result to a known type, and
+// [WithParamContext] to reject types Spanner does not accept as query
+// parameters.
 func MemefishExprToGCV(expr ast.Expr, opts ...EvalOption) (spanner.GenericColumnValue, error) {
-	return memefishExprToGCV(expr, applyEvalOptions(opts))
+	o := applyEvalOptions(opts)
+	var (
+		gcv spanner.GenericColumnValue
+		err error
+	)
+	if o.expectedType != nil {
+		gcv, err = memefishExprToGCVWithExpectedType(o.expectedType, expr, o)
+	} else {
+		gcv, err = memefishExprToGCV(expr, o)
+	}
+	if err != nil {
+		return zeroGCV, err
+	}
+	if o.paramContext {
+		if err := ValidateType(gcv.Type, ContextQueryParameter); err != nil {
+			return zeroGCV, err
+		}
+	}
+	return gcv, nil
 }
 
 func memefishExprToGCV(expr ast.Expr, o evalOptions) (spanner.GenericColumnValue, error) {
@@ -378,13 +412,13 @@ func memefishExprToGCV(expr ast.Expr, o evalOptions) (spanner.GenericColumnValue
 	case *ast.IntLiteral:
 		i, err := strconv.ParseInt(e.Value, e.Base, 64)
 		if err != nil {
-			return zeroGCV, err
+			return zeroGCV, o.cause(err)
 		}
 		return gcvctor.Int64Value(i), nil
 	case *ast.FloatLiteral:
 		f, err := strconv.ParseFloat(e.Value, 64)
 		if err != nil {
-			return zeroGCV, err
+			return zeroGCV, o.cause(err)
 		}
 		return gcvctor.Float64Value(f), nil
 	case *ast.StringLiteral:
@@ -406,20 +440,36 @@ func memefishExprToGCV(expr ast.Expr, o evalOptions) (spanner.GenericColumnValue
 		*ast.TypedStructLiteral:
 		return astStructLiteralsToGCV(e, o)
 	case *ast.IntervalLiteralSingle, *ast.IntervalLiteralRange:
-		return astIntervalLiteralsToGCV(e)
+		return astIntervalLiteralsToGCV(e, o.castContext(e.SQL()))
 	case *ast.ParenExpr:
 		return memefishExprToGCV(e.Expr, o)
 	case *ast.CastExpr:
 		return memefishCastExprToGCV(e, o)
+	case *ast.Param:
+		if o.params != nil {
+			gcv, ok := o.params[e.Name]
+			if !ok {
+				return zeroGCV, fmt.Errorf("%w: @%s", ErrUndefinedParameter, e.Name)
+			}
+			return gcv, nil
+		}
+	case *ast.Ident, *ast.Path, *ast.SelectorExpr, *ast.UnaryExpr, *ast.BinaryExpr,
+		*ast.IsNullExpr, *ast.IsBoolExpr, *ast.BetweenExpr, *ast.InExpr:
+		if o.columns != nil {
+			return (&queryEvaluator{o: o}).eval(e, o.columns)
+		}
 	case *ast.CallExpr:
 		if len(e.Func.Idents) == 1 && char.EqualFold(e.Func.Idents[0].Name, "PENDING_COMMIT_TIMESTAMP") {
 			return gcvctor.StringBasedValueFromCode(sppb.TypeCode_TIMESTAMP, commitTimestampPlaceholderString), nil
 		}
+		if o.columns != nil && len(e.Func.Idents) == 1 && char.EqualFold(e.Func.Idents[0].Name, "GENERATE_ARRAY") {
+			return (&queryEvaluator{o: o}).eval(e, o.columns)
+		}
 		// break
 	default:
 		// break
 	}
-	return zeroGCV, fmt.Errorf("%w: %s", ErrUnsupportedExpr, expr.SQL())
+	return zeroGCV, fmt.Errorf("%w: %s", ErrUnsupportedExpr, o.sql(expr))
 }
 
 func arrayLiteralToGCVStrict(
@@ -457,7 +507,7 @@ func arrayLiteralToGCVWithFallback(
 		return zeroGCV, ErrCannotInferArrayElementType
 	}
 
-	return arrayLiteralValueOf(elemType, expr.Values, gcvs, allowFallback)
+	return arrayLiteralValueOf(elemType, expr.Values, gcvs, allowFallback, o)
 }
 
 func arrayLiteralElementsToGCVs(
@@ -604,9 +654,10 @@ func arrayLiteralValueOf(
 	exprs []ast.Expr,
 	gcvs []spanner.GenericColumnValue,
 	allowFallback bool,
+	o evalOptions,
 ) (spanner.GenericColumnValue, error) {
 	if !allowFallback {
-		coerced, err := coerceArrayElementsStrict(elemType, exprs, gcvs)
+		coerced, err := coerceArrayElementsStrict(elemType, exprs, gcvs, o)
 		if err != nil {
 			return zeroGCV, err
 		}
@@ -619,7 +670,7 @@ func arrayLiteralValueOf(
 			return zeroGCV, err
 		}
 
-		coerced, coerceErr := coerceArrayElements(elemType, gcvs)
+		coerced, coerceErr := coerceArrayElements(elemType, exprs, gcvs, o)
 		if coerceErr == nil {
 			return gcvctor.ArrayValueOf(elemType, coerced...)
 		}
@@ -633,7 +684,7 @@ func arrayLiteralValueOf(
 		return spanner.GenericColumnValue{
 			Type: typector.ElemTypeToArrayType(elemType),
 			Value: structpb.NewListValue(&structpb.ListValue{
-				Values: gcvArrayWireValues(gcvs),
+				Values: ArrayWireValues(gcvs),
 			}),
 		}, nil
 	}
@@ -645,19 +696,25 @@ func coerceArrayElementsStrict(
 	elemType *sppb.Type,
 	exprs []ast.Expr,
 	gcvs []spanner.GenericColumnValue,
+	o evalOptions,
 ) ([]spanner.GenericColumnValue, error) {
 	coerced := make([]spanner.GenericColumnValue, len(gcvs))
 	for i, gcv := range gcvs {
-		elem, err := coerceToExpectedType(elemType, gcv, exprs[i])
+		elem, err := coerceToExpectedType(elemType, gcv, exprs[i], o)
 		if err != nil {
-			return nil, fmt.Errorf("cannot coerce array element %d (%s): %w", i, exprs[i].SQL(), err)
+			return nil, fmt.Errorf("cannot coerce array element %d (%s): %w", i, o.sql(exprs[i]), err)
 		}
 		coerced[i] = elem
 	}
 	return coerced, nil
 }
 
-func coerceArrayElements(elemType *sppb.Type, gcvs []spanner.GenericColumnValue) ([]spanner.GenericColumnValue, error) {
+func coerceArrayElements(
+	elemType *sppb.Type,
+	exprs []ast.Expr,
+	gcvs []spanner.GenericColumnValue,
+	o evalOptions,
+) ([]spanner.GenericColumnValue, error) {
 	normalized, err := gcvctor.NormalizeArrayElements(elemType, gcvs...)
 	if err == nil {
 		return normalized, nil
@@ -676,7 +733,7 @@ func coerceArrayElements(elemType *sppb.Type, gcvs []spanner.GenericColumnValue)
 			coerced[i] = retyped
 			continue
 		}
-		elem, err := coerceArrayElement(elemType, gcv)
+		elem, err := coerceArrayElement(elemType, gcv, o.castContext(exprs[i].SQL()))
 		if err != nil {
 			return nil, err
 		}
@@ -685,14 +742,14 @@ func coerceArrayElements(elemType *sppb.Type, gcvs []spanner.GenericColumnValue)
 	return coerced, nil
 }
 
-func coerceArrayElement(elemType *sppb.Type, gcv spanner.GenericColumnValue) (spanner.GenericColumnValue, error) {
+func coerceArrayElement(elemType *sppb.Type, gcv spanner.GenericColumnValue, c castContext) (spanner.GenericColumnValue, error) {
 	// This is not the full CAST matrix. It only models array literal coercions
 	// that are safe locally; CAST-only conversions such as FLOAT64 to NUMERIC
 	// and NUMERIC to FLOAT32 intentionally fall back to preserving wire values.
 
 	// Allow STRING values to coerce to any type that CAST supports.
 	if gcv.Type.GetCode() == sppb.TypeCode_STRING {
-		return castGCV(gcv, elemType, "")
+		return castGCV(gcv, elemType, c)
 	}
 
 	switch elemType.GetCode() {
@@ -705,7 +762,7 @@ func coerceArrayElement(elemType *sppb.Type, gcv spanner.GenericColumnValue) (sp
 			return gcvctor.NumericValueChecked(big.NewRat(v, 1))
 		}
 	case sppb.TypeCode_FLOAT32:
-		return coerceArrayElementToFloat32(gcv)
+		return coerceArrayElementToFloat32(gcv, c)
 	case sppb.TypeCode_FLOAT64:
 		return coerceArrayElementToFloat64(gcv)
 	}
@@ -713,7 +770,7 @@ func coerceArrayElement(elemType *sppb.Type, gcv spanner.GenericColumnValue) (sp
 	return zeroGCV, fmt.Errorf("cannot coerce array element from %v to %v", gcv.Type.GetCode(), elemType.GetCode())
 }
 
-func coerceArrayElementToFloat32(gcv spanner.GenericColumnValue) (spanner.GenericColumnValue, error) {
+func coerceArrayElementToFloat32(gcv spanner.GenericColumnValue, c castContext) (spanner.GenericColumnValue, error) {
 	// Reuse scalar CAST helpers from cast.go so float narrowing and wire-value
 	// extraction stay consistent between CAST emulation and array coercion.
 	switch gcv.Type.GetCode() {
@@ -722,13 +779,13 @@ func coerceArrayElementToFloat32(gcv spanner.GenericColumnValue) (spanner.Generi
 		if err != nil {
 			return zeroGCV, err
 		}
-		return float32ValueFromFloat64(float64(v))
+		return float32ValueFromFloat64(float64(v), c)
 	case sppb.TypeCode_FLOAT64:
 		v, err := float64FromGCV(gcv, 64)
 		if err != nil {
 			return zeroGCV, err
 		}
-		return float32ValueFromFloat64(v)
+		return float32ValueFromFloat64(v, c)
 	default:
 		return zeroGCV, fmt.Errorf("cannot coerce array element from %v to FLOAT32", gcv.Type.GetCode())
 	}
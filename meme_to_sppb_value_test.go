@@ -3,11 +3,15 @@ package memebridge_test
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"cloud.google.com/go/spanner"
 	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
 	"github.com/apstndb/spantype/typector"
+	"github.com/apstndb/spanvalue/gcvctor"
+	"github.com/cloudspannerecosystem/memefish"
 	"github.com/cloudspannerecosystem/memefish/ast"
 	"github.com/google/go-cmp/cmp"
 	"google.golang.org/protobuf/testing/protocmp"
@@ -217,3 +221,150 @@ func TestMemefishExprToGCV_AllNullArrayWithoutTypeInfersInt64(t *testing.T) {
 		t.Errorf("mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestMemefishExprToGCV_WithRedactedValues(t *testing.T) {
+	const secret = "s3cr3t-9999999999999999999999"
+	tests := []struct {
+		desc    string
+		expr    string
+		opts    []memebridge.EvalOption
+		wantErr error
+	}{
+		{"cast parse failure", `CAST("` + secret + `" AS INT64)`, nil, nil},
+		{"numeric range", `CAST("1e` + secret[len(secret)-20:] + `" AS NUMERIC)`, nil, nil},
+		{"int literal range", secret[len(secret)-22:], nil, nil},
+		{"expected DATE", `"` + secret + `"`, []memebridge.EvalOption{memebridge.WithExpectedType(typector.Date())}, nil},
+		{"expected UUID", `"` + secret + `"`, []memebridge.EvalOption{memebridge.WithExpectedType(typector.UUID())}, nil},
+		{"array element", `ARRAY<DATE>["` + secret + `"]`, nil, nil},
+		{"unsupported cast", `CAST(STRUCT("` + secret + `") AS INT64)`, nil, memebridge.ErrUnsupportedCast},
+		{"unsupported expr", `FN("` + secret + `")`, nil, memebridge.ErrUnsupportedExpr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			expr, err := memefish.ParseExpr("", tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, plainErr := memebridge.MemefishExprToGCV(expr, tt.opts...)
+			if plainErr == nil || !strings.Contains(plainErr.Error(), secret[len(secret)-10:]) {
+				t.Fatalf("unredacted error = %v, want one containing the value", plainErr)
+			}
+			_, err = memebridge.MemefishExprToGCV(expr, append(tt.opts, memebridge.WithRedactedValues())...)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if strings.Contains(err.Error(), secret[len(secret)-10:]) {
+				t.Errorf("error %q leaks the value", err)
+			}
+			if !strings.Contains(err.Error(), memebridge.RedactedPlaceholder) {
+				t.Errorf("error %q does not contain %s", err, memebridge.RedactedPlaceholder)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error %v does not wrap %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithTimeZone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip(err)
+	}
+	tests := []struct {
+		expr string
+		opts []memebridge.EvalOption
+		want string
+	}{
+		{`CAST(TIMESTAMP "2024-01-01T00:00:00Z" AS DATE)`, nil, `DATE "2023-12-31"`},
+		{`CAST(TIMESTAMP "2024-01-01T00:00:00Z" AS DATE)`, []memebridge.EvalOption{memebridge.WithTimeZone(time.UTC)}, `DATE "2024-01-01"`},
+		{`CAST(TIMESTAMP "2024-01-01T00:00:00Z" AS STRING)`, []memebridge.EvalOption{memebridge.WithTimeZone(tokyo)}, `"2024-01-01 09:00:00+09"`},
+		{`CAST("2024-01-01 09:00:00" AS TIMESTAMP)`, []memebridge.EvalOption{memebridge.WithTimeZone(tokyo)}, `TIMESTAMP "2024-01-01T00:00:00Z"`},
+		{`CAST(DATE "2024-01-01" AS TIMESTAMP)`, []memebridge.EvalOption{memebridge.WithTimeZone(time.UTC)}, `TIMESTAMP "2024-01-01T00:00:00Z"`},
+		{`CAST("2024-01-01 00:00:00 UTC" AS TIMESTAMP)`, []memebridge.EvalOption{memebridge.WithTimeZone(tokyo)}, `TIMESTAMP "2024-01-01T00:00:00Z"`},
+		{`ARRAY<TIMESTAMP>["2024-01-01 09:00:00"]`, []memebridge.EvalOption{memebridge.WithTimeZone(tokyo), memebridge.WithLegacyArrayWirePassthrough()}, `[TIMESTAMP "2024-01-01T00:00:00Z"]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := memebridge.ParseExprToGCV(tt.expr, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := must(memebridge.ParseExprToGCV(tt.want))
+			if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWithParams(t *testing.T) {
+	params := map[string]spanner.GenericColumnValue{"n": gcvctor.Int64Value(7)}
+	got, err := memebridge.ParseExprToGCV(`[@n, 8]`, memebridge.WithParams(params))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := must(gcvctor.ArrayValue(gcvctor.Int64Value(7), gcvctor.Int64Value(8)))
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := memebridge.ParseExprToGCV(`@missing`, memebridge.WithParams(params)); !errors.Is(err, memebridge.ErrUndefinedParameter) {
+		t.Errorf("undefined parameter: err = %v, want ErrUndefinedParameter", err)
+	}
+	if _, err := memebridge.ParseExprToGCV(`@n`); !errors.Is(err, memebridge.ErrUnsupportedExpr) {
+		t.Errorf("without WithParams: err = %v, want ErrUnsupportedExpr", err)
+	}
+}
+
+func TestWithColumns(t *testing.T) {
+	columns := map[string]spanner.GenericColumnValue{
+		"Price":    gcvctor.Float64Value(12.5),
+		"Quantity": gcvctor.Int64Value(10),
+		"Status":   gcvctor.StringValue("B"),
+		"Shipping": must(gcvctor.StructValueOf([]string{"City"}, []spanner.GenericColumnValue{gcvctor.StringValue("Tokyo")})),
+	}
+	for _, tt := range []struct {
+		expr string
+		want spanner.GenericColumnValue
+	}{
+		{`Price * Quantity > 100 AND Status IN ('A', 'B')`, gcvctor.BoolValue(true)},
+		{`quantity`, gcvctor.Int64Value(10)},
+		{`Shipping.City`, gcvctor.StringValue("Tokyo")},
+		{`CAST(Quantity AS STRING)`, gcvctor.StringValue("10")},
+		{`[Quantity, 1]`, must(gcvctor.ArrayValue(gcvctor.Int64Value(10), gcvctor.Int64Value(1)))},
+		{`Quantity > 100 OR NULL`, gcvctor.NullFromCode(sppb.TypeCode_BOOL)},
+		{`NOT NULL AND Quantity > 100`, gcvctor.BoolValue(false)},
+		{`Status || NULL`, gcvctor.NullFromCode(sppb.TypeCode_STRING)},
+	} {
+		got, err := memebridge.ParseExprToGCV(tt.expr, memebridge.WithColumns(columns))
+		if err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		if diff := cmp.Diff(tt.want, got, protocmp.Transform()); diff != "" {
+			t.Errorf("%s: mismatch (-want +got):\n%s", tt.expr, diff)
+		}
+	}
+
+	if _, err := memebridge.ParseExprToGCV(`Price > Discount`, memebridge.WithColumns(columns)); !errors.Is(err, memebridge.ErrUnrecognizedName) {
+		t.Errorf("unknown column: err = %v, want ErrUnrecognizedName", err)
+	}
+	if _, err := memebridge.ParseExprToGCV(`Price`); !errors.Is(err, memebridge.ErrUnsupportedExpr) {
+		t.Errorf("without WithColumns: err = %v, want ErrUnsupportedExpr", err)
+	}
+}
+
+func TestWithRow(t *testing.T) {
+	row, err := memebridge.ParseExprToRow(`STRUCT(1 AS Id, CAST(NULL AS STRING) AS Note)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := memebridge.ParseExprToGCV(`Id = 1 AND Note IS NULL`, memebridge.WithRow(row))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(gcvctor.BoolValue(true), got, protocmp.Transform()); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
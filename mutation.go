@@ -0,0 +1,519 @@
+package memebridge
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/cloudspannerecosystem/memefish"
+	"github.com/cloudspannerecosystem/memefish/ast"
+	"github.com/cloudspannerecosystem/memefish/token"
+)
+
+// ErrUnsupportedDML is returned when a DML statement has no mutation
+// equivalent, for example an INSERT ... SELECT or an UPDATE whose WHERE
+// clause does not fix the primary key.
+var ErrUnsupportedDML = errors.New("unsupported DML for mutations")
+
+// InsertMode selects the mutation that a plain INSERT statement becomes.
+type InsertMode int
+
+const (
+	// InsertModeInsert converts INSERT to spanner.Insert, which fails if
+	// the row exists, like the statement.
+	InsertModeInsert InsertMode = iota
+	// InsertModeInsertOrUpdate converts INSERT to spanner.InsertOrUpdate.
+	InsertModeInsertOrUpdate
+	// InsertModeReplace converts INSERT to spanner.Replace, which resets
+	// the columns that the row does not set.
+	InsertModeReplace
+)
+
+// MutationOption configures [DMLToMutations] and [ParseDMLMutations].
+type MutationOption func(*mutationOptions)
+
+type mutationOptions struct {
+	insertMode InsertMode
+	evalOpts   []EvalOption
+}
+
+// WithInsertMode sets the mutation that a plain INSERT becomes, for bulk
+// loads that must overwrite existing rows. INSERT OR UPDATE always becomes
+// spanner.InsertOrUpdate.
+func WithInsertMode(mode InsertMode) MutationOption {
+	return func(o *mutationOptions) {
+		o.insertMode = mode
+	}
+}
+
+// WithMutationEvalOptions passes options such as [WithParams] or
+// [WithTimeZone] to the evaluation of values and keys.
+func WithMutationEvalOptions(opts ...EvalOption) MutationOption {
+	return func(o *mutationOptions) {
+		o.evalOpts = append(o.evalOpts, opts...)
+	}
+}
+
+// ParseDMLMutations parses semicolon-separated DML statements with memefish
+// and converts them with [DMLToMutations]. All statements are converted;
+// the error joins one error per statement that cannot be, prefixed with its
+// position, and the mutations of the other statements are still returned.
+// filename is used only in error positions.
+func ParseDMLMutations(filename, sql string, schema *Schema, opts ...MutationOption) ([]*spanner.Mutation, error) {
+	dmls, err := memefish.ParseDMLs(filename, sql)
+	if err != nil {
+		return nil, err
+	}
+	file := &token.File{FilePath: filename, Buffer: sql}
+	var (
+		mutations []*spanner.Mutation
+		errs      []error
+	)
+	for _, dml := range dmls {
+		ms, err := DMLToMutations(dml, schema, opts...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", file.Position(dml.Pos(), dml.End()), err))
+			continue
+		}
+		mutations = append(mutations, ms...)
+	}
+	return mutations, errors.Join(errs...)
+}
+
+// DMLToMutations converts a DML statement whose values are literals to the
+// equivalent mutations:
+//
+//   - INSERT INTO t (cols) VALUES (...), ... becomes one mutation per row,
+//     spanner.Insert unless [WithInsertMode] says otherwise; INSERT OR
+//     UPDATE becomes spanner.InsertOrUpdate. A DEFAULT value omits the
+//     column from its row.
+//   - UPDATE t SET col = v, ... WHERE key = k AND ... becomes spanner.Update.
+//   - DELETE FROM t WHERE key = k AND ... becomes spanner.Delete; one key
+//     column may use IN (...) to delete several rows, and WHERE TRUE
+//     deletes all rows.
+//
+// The WHERE clause of UPDATE and DELETE must fix the primary key with
+// equalities. With a schema, table and column names are resolved
+// case-insensitively, the WHERE clause must cover exactly the primary key,
+// and values are evaluated with the column type as expected type (see
+// [WithExpectedType]); schema may be nil, in which case the WHERE columns
+// are taken as the key in the order written. PENDING_COMMIT_TIMESTAMP()
+// becomes spanner.CommitTimestamp.
+//
+// Statements with no mutation equivalent, such as INSERT ... SELECT, INSERT
+// OR IGNORE, ON CONFLICT and THEN RETURN, return an error wrapping
+// [ErrUnsupportedDML].
+func DMLToMutations(dml ast.DML, schema *Schema, opts ...MutationOption) ([]*spanner.Mutation, error) {
+	var o mutationOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	switch dml := dml.(type) {
+	case *ast.Insert:
+		return o.insertMutations(dml, schema)
+	case *ast.Update:
+		return o.updateMutations(dml, schema)
+	case *ast.Delete:
+		return o.deleteMutations(dml, schema)
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedDML, dml)
+	}
+}
+
+func (o *mutationOptions) insertMutations(ins *ast.Insert, schema *Schema) ([]*spanner.Mutation, error) {
+	op := spanner.Insert
+	switch {
+	case ins.InsertOrType == ast.InsertOrTypeUpdate:
+		op = spanner.InsertOrUpdate
+	case ins.InsertOrType != "":
+		return nil, fmt.Errorf("%w: INSERT OR %s", ErrUnsupportedDML, ins.InsertOrType)
+	case o.insertMode == InsertModeInsertOrUpdate:
+		op = spanner.InsertOrUpdate
+	case o.insertMode == InsertModeReplace:
+		op = spanner.Replace
+	}
+	switch {
+	case ins.OnConflict != nil:
+		return nil, fmt.Errorf("%w: ON CONFLICT", ErrUnsupportedDML)
+	case ins.ThenReturn != nil:
+		return nil, fmt.Errorf("%w: THEN RETURN", ErrUnsupportedDML)
+	case ins.AssertRowsModified != nil:
+		return nil, fmt.Errorf("%w: ASSERT_ROWS_MODIFIED", ErrUnsupportedDML)
+	}
+	values, ok := ins.Input.(*ast.ValuesInput)
+	if !ok {
+		return nil, fmt.Errorf("%w: INSERT without VALUES", ErrUnsupportedDML)
+	}
+	if len(ins.Columns) == 0 {
+		return nil, fmt.Errorf("%w: INSERT without a column list", ErrUnsupportedDML)
+	}
+
+	target, err := newMutationTarget(schema, ins.TableName, ins.As)
+	if err != nil {
+		return nil, err
+	}
+	cols := make([]*mutationColumn, len(ins.Columns))
+	for i, ident := range ins.Columns {
+		if cols[i], err = target.column(ident.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	mutations := make([]*spanner.Mutation, 0, len(values.Rows))
+	for i, row := range values.Rows {
+		if len(row.Exprs) != len(cols) {
+			return nil, fmt.Errorf("row %d has %d values for %d columns", i+1, len(row.Exprs), len(cols))
+		}
+		var (
+			names []string
+			vals  []any
+		)
+		for j, e := range row.Exprs {
+			if e.Default {
+				continue
+			}
+			v, err := o.columnValue(cols[j], e.Expr)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", i+1, err)
+			}
+			names = append(names, cols[j].name)
+			vals = append(vals, v)
+		}
+		mutations = append(mutations, op(target.name, names, vals))
+	}
+	return mutations, nil
+}
+
+func (o *mutationOptions) updateMutations(upd *ast.Update, schema *Schema) ([]*spanner.Mutation, error) {
+	if upd.ThenReturn != nil {
+		return nil, fmt.Errorf("%w: THEN RETURN", ErrUnsupportedDML)
+	}
+	target, err := newMutationTarget(schema, upd.TableName, upd.As)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := o.whereKeys(target, upd.Where)
+	if err != nil {
+		return nil, err
+	}
+	if keys.all || len(keys.rows) != 1 {
+		return nil, fmt.Errorf("%w: UPDATE must fix a single primary key", ErrUnsupportedDML)
+	}
+
+	names := append([]string(nil), keys.columns...)
+	vals := append([]any(nil), keys.rows[0]...)
+	for _, item := range upd.Updates {
+		if len(item.Path) != 1 && !(len(item.Path) == 2 && target.hasQualifier(item.Path[0].Name)) {
+			return nil, fmt.Errorf("%w: SET of a nested field: %s", ErrUnsupportedDML, item.SQL())
+		}
+		col, err := target.column(item.Path[len(item.Path)-1].Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys.columns {
+			if strings.EqualFold(key, col.name) {
+				return nil, fmt.Errorf("%w: SET of primary key column %s", ErrUnsupportedDML, col.name)
+			}
+		}
+		if item.DefaultExpr.Default {
+			return nil, fmt.Errorf("%w: SET %s = DEFAULT", ErrUnsupportedDML, col.name)
+		}
+		v, err := o.columnValue(col, item.DefaultExpr.Expr)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, col.name)
+		vals = append(vals, v)
+	}
+	return []*spanner.Mutation{spanner.Update(target.name, names, vals)}, nil
+}
+
+func (o *mutationOptions) deleteMutations(del *ast.Delete, schema *Schema) ([]*spanner.Mutation, error) {
+	if del.ThenReturn != nil {
+		return nil, fmt.Errorf("%w: THEN RETURN", ErrUnsupportedDML)
+	}
+	target, err := newMutationTarget(schema, del.TableName, del.As)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := o.whereKeys(target, del.Where)
+	if err != nil {
+		return nil, err
+	}
+	if keys.all {
+		return []*spanner.Mutation{spanner.Delete(target.name, spanner.AllKeys())}, nil
+	}
+	keySets := make([]spanner.KeySet, len(keys.rows))
+	for i, row := range keys.rows {
+		keySets[i] = spanner.Key(row)
+	}
+	return []*spanner.Mutation{spanner.Delete(target.name, spanner.KeySets(keySets...))}, nil
+}
+
+// mutationTarget is the table that a DML statement writes.
+type mutationTarget struct {
+	name  string
+	alias string
+	table *Table // nil without a schema
+}
+
+// mutationColumn is a column written by a mutation.
+type mutationColumn struct {
+	name string
+	typ  *sppb.Type // nil without a schema
+}
+
+func newMutationTarget(schema *Schema, path *ast.Path, as *ast.AsAlias) (*mutationTarget, error) {
+	target := &mutationTarget{name: pathName(path)}
+	if as != nil && as.Alias != nil {
+		target.alias = as.Alias.Name
+	}
+	if schema == nil {
+		return target, nil
+	}
+	t, ok := schema.Table(target.name)
+	if !ok {
+		return nil, fmt.Errorf("unknown table %s", target.name)
+	}
+	target.name, target.table = t.Name, t
+	return target, nil
+}
+
+func (t *mutationTarget) column(name string) (*mutationColumn, error) {
+	if t.table == nil {
+		return &mutationColumn{name: name}, nil
+	}
+	c, ok := t.table.Column(name)
+	if !ok {
+		return nil, fmt.Errorf("table %s: unknown column %s", t.name, name)
+	}
+	if _, generated := c.Def.DefaultSemantics.(*ast.GeneratedColumnExpr); generated {
+		return nil, fmt.Errorf("table %s: column %s is generated and cannot be written", t.name, c.Name)
+	}
+	return &mutationColumn{name: c.Name, typ: c.Type}, nil
+}
+
+// hasQualifier reports whether name qualifies a column of t.
+func (t *mutationTarget) hasQualifier(name string) bool {
+	return strings.EqualFold(name, t.name) || t.alias != "" && strings.EqualFold(name, t.alias)
+}
+
+// columnName returns the column that expr refers to, or "".
+func (t *mutationTarget) columnName(expr ast.Expr) string {
+	switch e := unwrapParenExpr(expr).(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.Path:
+		if len(e.Idents) == 2 && t.hasQualifier(e.Idents[0].Name) {
+			return e.Idents[1].Name
+		}
+	}
+	return ""
+}
+
+// columnValue evaluates expr as a value of col, with
+// PENDING_COMMIT_TIMESTAMP() as spanner.CommitTimestamp.
+func (o *mutationOptions) columnValue(col *mutationColumn, expr ast.Expr) (any, error) {
+	gcv, err := o.eval(col, expr)
+	if err != nil {
+		return nil, err
+	}
+	if isCommitTimestamp(gcv) {
+		return spanner.CommitTimestamp, nil
+	}
+	return gcv, nil
+}
+
+func (o *mutationOptions) eval(col *mutationColumn, expr ast.Expr) (spanner.GenericColumnValue, error) {
+	opts := o.evalOpts[:len(o.evalOpts):len(o.evalOpts)]
+	if col.typ != nil {
+		opts = append(opts, WithExpectedType(col.typ))
+	}
+	gcv, err := MemefishExprToGCV(expr, opts...)
+	if err != nil {
+		return spanner.GenericColumnValue{}, fmt.Errorf("column %s: %w", col.name, err)
+	}
+	return gcv, nil
+}
+
+func isCommitTimestamp(gcv spanner.GenericColumnValue) bool {
+	return gcv.Type.GetCode() == sppb.TypeCode_TIMESTAMP &&
+		gcv.Value.GetStringValue() == commitTimestampPlaceholderString
+}
+
+// mutationKeys are the keys selected by a WHERE clause.
+type mutationKeys struct {
+	all     bool
+	columns []string
+	rows    [][]any // key parts in columns order
+}
+
+// whereKeys converts a WHERE clause of key equalities, with at most one
+// IN list, to keys. With a schema the key columns are the primary key in
+// key order; without one they are the WHERE columns in the order written.
+func (o *mutationOptions) whereKeys(target *mutationTarget, where *ast.Where) (*mutationKeys, error) {
+	if where == nil {
+		return nil, fmt.Errorf("%w: missing WHERE clause", ErrUnsupportedDML)
+	}
+	if lit, ok := unwrapParenExpr(where.Expr).(*ast.BoolLiteral); ok && lit.Value {
+		return &mutationKeys{all: true}, nil
+	}
+
+	// Collect the conjuncts as column = value or column IN (values).
+	type condition struct {
+		col   *mutationColumn
+		exprs []ast.Expr
+	}
+	var (
+		conds   []condition
+		collect func(ast.Expr) error
+	)
+	collect = func(expr ast.Expr) error {
+		var (
+			name  string
+			exprs []ast.Expr
+		)
+		switch e := unwrapParenExpr(expr).(type) {
+		case *ast.BinaryExpr:
+			switch e.Op {
+			case ast.OpAnd:
+				if err := collect(e.Left); err != nil {
+					return err
+				}
+				return collect(e.Right)
+			case ast.OpEqual:
+				if name = target.columnName(e.Left); name != "" {
+					exprs = []ast.Expr{e.Right}
+				} else if name = target.columnName(e.Right); name != "" {
+					exprs = []ast.Expr{e.Left}
+				}
+			}
+		case *ast.InExpr:
+			values, ok := e.Right.(*ast.ValuesInCondition)
+			if !e.Not && ok {
+				name, exprs = target.columnName(e.Left), values.Exprs
+			}
+		}
+		if name == "" {
+			return fmt.Errorf("%w: WHERE condition %s is not a primary key equality", ErrUnsupportedDML, expr.SQL())
+		}
+		col, err := target.column(name)
+		if err != nil {
+			return err
+		}
+		for _, c := range conds {
+			if strings.EqualFold(c.col.name, col.name) {
+				return fmt.Errorf("%w: column %s is constrained more than once", ErrUnsupportedDML, col.name)
+			}
+		}
+		conds = append(conds, condition{col, exprs})
+		return nil
+	}
+	if err := collect(where.Expr); err != nil {
+		return nil, err
+	}
+
+	if table := target.table; table != nil {
+		if len(conds) != len(table.PrimaryKey) {
+			return nil, fmt.Errorf("%w: WHERE must fix the primary key (%s) of %s",
+				ErrUnsupportedDML, strings.Join(table.PrimaryKey, ", "), table.Name)
+		}
+		ordered := make([]condition, len(conds))
+		for i, key := range table.PrimaryKey {
+			j := -1
+			for k, c := range conds {
+				if strings.EqualFold(c.col.name, key) {
+					j = k
+				}
+			}
+			if j < 0 {
+				return nil, fmt.Errorf("%w: WHERE must fix the primary key (%s) of %s",
+					ErrUnsupportedDML, strings.Join(table.PrimaryKey, ", "), table.Name)
+			}
+			ordered[i] = conds[j]
+		}
+		conds = ordered
+	}
+
+	keys := &mutationKeys{rows: [][]any{nil}}
+	multi := false
+	for _, c := range conds {
+		if len(c.exprs) > 1 {
+			if multi {
+				return nil, fmt.Errorf("%w: more than one key column with IN", ErrUnsupportedDML)
+			}
+			multi = true
+		}
+		keys.columns = append(keys.columns, c.col.name)
+		parts := make([]any, len(c.exprs))
+		for i, e := range c.exprs {
+			gcv, err := o.eval(c.col, e)
+			if err != nil {
+				return nil, err
+			}
+			if parts[i], err = keyPart(gcv); err != nil {
+				return nil, fmt.Errorf("column %s: %w", c.col.name, err)
+			}
+		}
+		var rows [][]any
+		for _, row := range keys.rows {
+			for _, part := range parts {
+				rows = append(rows, append(row[:len(row):len(row)], part))
+			}
+		}
+		keys.rows = rows
+	}
+	return keys, nil
+}
+
+// keyPart converts gcv to a Go value accepted in a spanner.Key, which does
+// not accept GenericColumnValue. NULL becomes the typed Null value.
+func keyPart(gcv spanner.GenericColumnValue) (any, error) {
+	if isCommitTimestamp(gcv) {
+		return spanner.CommitTimestamp, nil
+	}
+	var (
+		v   any
+		err error
+	)
+	switch gcv.Type.GetCode() {
+	case sppb.TypeCode_BOOL:
+		v, err = decodeKeyPart(gcv, func(n spanner.NullBool) (any, bool) { return n.Bool, n.Valid })
+	case sppb.TypeCode_INT64:
+		v, err = decodeKeyPart(gcv, func(n spanner.NullInt64) (any, bool) { return n.Int64, n.Valid })
+	case sppb.TypeCode_FLOAT32:
+		v, err = decodeKeyPart(gcv, func(n spanner.NullFloat32) (any, bool) { return n.Float32, n.Valid })
+	case sppb.TypeCode_FLOAT64:
+		v, err = decodeKeyPart(gcv, func(n spanner.NullFloat64) (any, bool) { return n.Float64, n.Valid })
+	case sppb.TypeCode_NUMERIC:
+		v, err = decodeKeyPart(gcv, func(n spanner.NullNumeric) (any, bool) { return n.Numeric, n.Valid })
+	case sppb.TypeCode_STRING:
+		v, err = decodeKeyPart(gcv, func(n spanner.NullString) (any, bool) { return n.StringVal, n.Valid })
+	case sppb.TypeCode_BYTES:
+		v, err = decodeKeyPart(gcv, func(b []byte) (any, bool) { return b, true })
+	case sppb.TypeCode_DATE:
+		v, err = decodeKeyPart(gcv, func(n spanner.NullDate) (any, bool) { return n.Date, n.Valid })
+	case sppb.TypeCode_TIMESTAMP:
+		v, err = decodeKeyPart(gcv, func(n spanner.NullTime) (any, bool) { return n.Time, n.Valid })
+	case sppb.TypeCode_UUID:
+		v, err = decodeKeyPart(gcv, func(n spanner.NullUUID) (any, bool) { return n.UUID, n.Valid })
+	default:
+		return nil, fmt.Errorf("%w: %s is not a key type", ErrUnsupportedType, gcv.Type.GetCode())
+	}
+	return v, err
+}
+
+// decodeKeyPart decodes gcv as T and returns the unwrapped value, or the
+// null T for NULL.
+func decodeKeyPart[T any](gcv spanner.GenericColumnValue, unwrap func(T) (any, bool)) (any, error) {
+	var n T
+	if err := gcv.Decode(&n); err != nil {
+		return nil, err
+	}
+	if v, valid := unwrap(n); valid {
+		return v, nil
+	}
+	return n, nil
+}
@@ -0,0 +1,153 @@
+package memebridge_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/civil"
+	"cloud.google.com/go/spanner"
+	"github.com/apstndb/spanvalue/gcvctor"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/apstndb/memebridge"
+)
+
+// mutationCmpOpts compares spanner.Mutation, whose fields are unexported.
+var mutationCmpOpts = cmp.Options{
+	cmp.Exporter(func(t reflect.Type) bool { return t.PkgPath() == "cloud.google.com/go/spanner" }),
+	protocmp.Transform(),
+}
+
+func TestParseDMLMutations(t *testing.T) {
+	schema := must(memebridge.ParseSchema("schema.sql", testSchemaDDL))
+	tests := []struct {
+		desc   string
+		sql    string
+		schema *memebridge.Schema
+		opts   []memebridge.MutationOption
+		want   []*spanner.Mutation
+	}{
+		{
+			"insert rows", `INSERT INTO Singers (SingerId, Name) VALUES (1, 'a'), (2, 'b')`, nil, nil,
+			[]*spanner.Mutation{
+				spanner.Insert("Singers", []string{"SingerId", "Name"}, []any{gcvctor.Int64Value(1), gcvctor.StringValue("a")}),
+				spanner.Insert("Singers", []string{"SingerId", "Name"}, []any{gcvctor.Int64Value(2), gcvctor.StringValue("b")}),
+			},
+		},
+		{
+			"schema types and names", `INSERT INTO singers (singerid, birthday, name) VALUES (1, '2024-01-01', DEFAULT)`, schema, nil,
+			[]*spanner.Mutation{
+				spanner.Insert("Singers", []string{"SingerId", "Birthday"}, []any{gcvctor.Int64Value(1), gcvctor.DateValue(civil.Date{Year: 2024, Month: 1, Day: 1})}),
+			},
+		},
+		{
+			"insert or update", `INSERT OR UPDATE INTO Albums (SingerId, AlbumId, ReleasedAt) VALUES (1, 2, PENDING_COMMIT_TIMESTAMP())`, schema, nil,
+			[]*spanner.Mutation{
+				spanner.InsertOrUpdate("Albums", []string{"SingerId", "AlbumId", "ReleasedAt"}, []any{gcvctor.Int64Value(1), gcvctor.Int64Value(2), spanner.CommitTimestamp}),
+			},
+		},
+		{
+			"insert as replace", `INSERT Singers (SingerId) VALUES (@id)`, schema,
+			[]memebridge.MutationOption{
+				memebridge.WithInsertMode(memebridge.InsertModeReplace),
+				memebridge.WithMutationEvalOptions(memebridge.WithParams(map[string]spanner.GenericColumnValue{"id": gcvctor.Int64Value(9)})),
+			},
+			[]*spanner.Mutation{
+				spanner.Replace("Singers", []string{"SingerId"}, []any{gcvctor.Int64Value(9)}),
+			},
+		},
+		{
+			"update", `UPDATE Albums a SET Rating = 4, a.ReleasedAt = PENDING_COMMIT_TIMESTAMP() WHERE a.AlbumId = 2 AND (SingerId = 1)`, schema, nil,
+			[]*spanner.Mutation{
+				spanner.Update("Albums", []string{"SingerId", "AlbumId", "Rating", "ReleasedAt"}, []any{int64(1), int64(2), gcvctor.Float64Value(4), spanner.CommitTimestamp}),
+			},
+		},
+		{
+			"update without schema", `UPDATE Singers SET Name = 'x' WHERE 'k' = Id`, nil, nil,
+			[]*spanner.Mutation{
+				spanner.Update("Singers", []string{"Id", "Name"}, []any{"k", gcvctor.StringValue("x")}),
+			},
+		},
+		{
+			"delete keys", `DELETE FROM Albums WHERE AlbumId IN (1, 2) AND SingerId = 7; DELETE Singers WHERE TRUE`, schema, nil,
+			[]*spanner.Mutation{
+				spanner.Delete("Albums", spanner.KeySets(spanner.Key{int64(7), int64(1)}, spanner.Key{int64(7), int64(2)})),
+				spanner.Delete("Singers", spanner.AllKeys()),
+			},
+		},
+		{
+			"delete null and typed keys", `DELETE FROM T WHERE a = CAST(NULL AS STRING) AND b = DATE '2024-01-01'`, nil, nil,
+			[]*spanner.Mutation{
+				spanner.Delete("T", spanner.KeySets(spanner.Key{spanner.NullString{}, civil.Date{Year: 2024, Month: 1, Day: 1}})),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := memebridge.ParseDMLMutations("dml.sql", tt.sql, tt.schema, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got, mutationCmpOpts); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseDMLMutationsErrors(t *testing.T) {
+	schema := must(memebridge.ParseSchema("schema.sql", testSchemaDDL))
+	tests := []struct {
+		sql     string
+		wantErr error
+		wantMsg string
+	}{
+		{`INSERT INTO Singers (SingerId) SELECT 1`, memebridge.ErrUnsupportedDML, "INSERT without VALUES"},
+		{`INSERT OR IGNORE INTO Singers (SingerId) VALUES (1)`, memebridge.ErrUnsupportedDML, "INSERT OR IGNORE"},
+		{`INSERT INTO Singers (SingerId) VALUES (1) THEN RETURN SingerId`, memebridge.ErrUnsupportedDML, "THEN RETURN"},
+		{`UPDATE Albums SET Rating = 1 WHERE SingerId = 1`, memebridge.ErrUnsupportedDML, "primary key (SingerId, AlbumId)"},
+		{`UPDATE Singers SET Name = 'a' WHERE SingerId > 1`, memebridge.ErrUnsupportedDML, "SingerId > 1"},
+		{`UPDATE Singers SET SingerId = 2 WHERE SingerId = 1`, memebridge.ErrUnsupportedDML, "primary key column SingerId"},
+		{`UPDATE Singers SET Name = 'a' WHERE SingerId IN (1, 2)`, memebridge.ErrUnsupportedDML, "single primary key"},
+		{`DELETE FROM Singers WHERE SingerId = 1 AND SingerId = 2`, memebridge.ErrUnsupportedDML, "more than once"},
+		{`INSERT INTO Singers (SingerId, Birthday) VALUES (1, 'x')`, nil, "row 1: column Birthday"},
+		{`INSERT INTO Nope (a) VALUES (1)`, nil, "unknown table Nope"},
+		{`DELETE FROM Singers WHERE Nope = 1`, nil, "unknown column Nope"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			_, err := memebridge.ParseDMLMutations("dml.sql", tt.sql, schema)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) || !strings.HasPrefix(err.Error(), "dml.sql:1:1: ") {
+				t.Errorf("err = %v, want one at dml.sql:1:1 containing %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestParseDMLMutationsReportsEachStatement(t *testing.T) {
+	sql := "INSERT INTO T (a) VALUES (1);\nDELETE FROM T WHERE a > 1;\nINSERT INTO T (a) VALUES (2);\nUPDATE T SET b = 1 WHERE TRUE"
+	got, err := memebridge.ParseDMLMutations("dml.sql", sql, nil)
+	want := []*spanner.Mutation{
+		spanner.Insert("T", []string{"a"}, []any{gcvctor.Int64Value(1)}),
+		spanner.Insert("T", []string{"a"}, []any{gcvctor.Int64Value(2)}),
+	}
+	if diff := cmp.Diff(want, got, mutationCmpOpts); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	if !errors.Is(err, memebridge.ErrUnsupportedDML) {
+		t.Fatalf("err = %v, want ErrUnsupportedDML", err)
+	}
+	lines := strings.Split(err.Error(), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "dml.sql:2:1: ") || !strings.HasPrefix(lines[1], "dml.sql:4:1: ") {
+		t.Errorf("err = %q, want errors at lines 2 and 4", err)
+	}
+}
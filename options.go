@@ -1,12 +1,34 @@
 package memebridge
 
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/cloudspannerecosystem/memefish/ast"
+)
+
 // EvalOption configures expression evaluation.
 type EvalOption func(*evalOptions)
 
 type evalOptions struct {
 	legacyArrayWirePassthrough bool
+	expectedType               *sppb.Type
+	redact                     bool
+	loc                        *time.Location
+	params                     map[string]spanner.GenericColumnValue
+	columns                    *rowScope
+	paramContext               bool
 }
 
+// RedactedPlaceholder replaces values and expressions in error messages
+// produced with [WithRedactedValues].
+const RedactedPlaceholder = "<redacted>"
+
 // WithLegacyArrayWirePassthrough restores pre-v0.7 behavior where ARRAY<T>
 // literals preserve original element wire values when coercion to T fails.
 // The default is strict coercion, which returns an error on incompatible elements.
@@ -16,6 +38,146 @@ func WithLegacyArrayWirePassthrough() EvalOption {
 	}
 }
 
+// WithExpectedType evaluates the top-level expression against an expected
+// type, applying the same literal coercion Spanner applies when a literal is
+// compared with or assigned to a column of type t: a string literal becomes a
+// DATE, TIMESTAMP, NUMERIC, etc., untyped NULL becomes a NULL of t, and
+// INT64 widens to NUMERIC or FLOAT64. Values that cannot be coerced are an
+// error. A nil t disables coercion.
+//
+// The expected type is typically taken from [Schema.InferParamTypes].
+func WithExpectedType(t *sppb.Type) EvalOption {
+	return func(o *evalOptions) {
+		o.expectedType = t
+	}
+}
+
+// WithTimeZone sets the time zone that temporal casts without an explicit
+// time zone use: CAST of a zoneless STRING to TIMESTAMP, of TIMESTAMP to
+// DATE or STRING, and of DATE to TIMESTAMP. The default is Cloud Spanner's
+// America/Los_Angeles; a nil loc keeps it. Emulating a query that sets a
+// different default time zone is the main use.
+func WithTimeZone(loc *time.Location) EvalOption {
+	return func(o *evalOptions) {
+		o.loc = loc
+	}
+}
+
+// WithParams binds query parameters, so that @name in an expression
+// evaluates to params[name]. Referencing a name missing from params is an
+// error wrapping [ErrUndefinedParameter]. Without WithParams, parameter
+// references are unsupported expressions.
+func WithParams(params map[string]spanner.GenericColumnValue) EvalOption {
+	return func(o *evalOptions) {
+		o.params = params
+		if o.params == nil {
+			o.params = map[string]spanner.GenericColumnValue{}
+		}
+	}
+}
+
+// WithColumns binds column names to values, so that expressions over the
+// columns of a row, such as a CHECK constraint or a client-side filter,
+// can be evaluated:
+//
+//	ParseExprToGCV(`Price * Quantity > 100 AND Status IN ('A', 'B')`, WithColumns(cols))
+//
+// An identifier evaluates to the column with that name, matched
+// case-insensitively, and a path such as s.f to a field of a STRUCT
+// column. Column references may be combined with comparisons, AND, OR,
+// NOT, IS NULL, IN, BETWEEN, arithmetic on INT64 and FLOAT64 and string
+// concatenation, as in [MemefishQueryToResultSet]. Referencing an unbound
+// name is an error wrapping [ErrUnrecognizedName]. Without WithColumns or
+// [WithRow], identifiers and operators are unsupported expressions.
+func WithColumns(columns map[string]spanner.GenericColumnValue) EvalOption {
+	return func(o *evalOptions) {
+		o.columns = &rowScope{}
+		for _, name := range slices.Sorted(maps.Keys(columns)) {
+			o.columns.add(name, columns[name], false)
+		}
+	}
+}
+
+// WithRow is like [WithColumns] for the columns of row, such as a row read
+// from Spanner or built with [ParseExprToRow]. Columns with an empty name
+// cannot be referenced. A nil row binds no columns.
+func WithRow(row *spanner.Row) EvalOption {
+	return func(o *evalOptions) {
+		o.columns = &rowScope{}
+		if row == nil {
+			return
+		}
+		for i, name := range row.ColumnNames() {
+			var gcv spanner.GenericColumnValue
+			if err := row.Column(i, &gcv); err == nil {
+				o.columns.add(name, gcv, false)
+			}
+		}
+	}
+}
+
+// WithParamContext makes [MemefishExprToGCV] check the type of its result
+// with [ValidateType] in [ContextQueryParameter], so that a value Spanner
+// would reject as a query parameter, such as an ARRAY<ARRAY<INT64>>, fails
+// locally with an error wrapping [ErrTypeNotAllowed].
+func WithParamContext() EvalOption {
+	return func(o *evalOptions) {
+		o.paramContext = true
+	}
+}
+
+// RedactError returns an error whose message is [RedactedPlaceholder] and
+// which wraps err, for underlying errors whose messages quote their input.
+// errors.Is and errors.As still see err. It returns nil for a nil err.
+func RedactError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &redactedError{err: err}
+}
+
+type redactedError struct{ err error }
+
+// Error keeps the parts of well-known parse errors that do not quote the
+// input, so that the kind of failure stays visible.
+func (e *redactedError) Error() string {
+	var numErr *strconv.NumError
+	if errors.As(e.err, &numErr) {
+		return "strconv." + numErr.Func + ": parsing " + RedactedPlaceholder + ": " + numErr.Err.Error()
+	}
+	var timeErr *time.ParseError
+	if errors.As(e.err, &timeErr) {
+		return "parsing time " + RedactedPlaceholder + " as " + strconv.Quote(timeErr.Layout)
+	}
+	return RedactedPlaceholder
+}
+
+func (e *redactedError) Unwrap() error { return e.err }
+
+// WithRedactedValues keeps the evaluated expression and its values out of
+// error messages, replacing them with [RedactedPlaceholder], for inputs such
+// as credentials that must not reach logs. Types, error kinds and wrapped
+// sentinel errors are unchanged, so errors.Is still works.
+func WithRedactedValues() EvalOption {
+	return func(o *evalOptions) {
+		o.redact = true
+	}
+}
+
+func (o evalOptions) castContext(exprSQL string) castContext {
+	return castContext{exprSQL: exprSQL, redact: o.redact, loc: o.loc}
+}
+
+// sql returns the SQL of expr for error messages, or RedactedPlaceholder.
+func (o evalOptions) sql(expr ast.Expr) string {
+	return o.castContext(expr.SQL()).expr()
+}
+
+// cause redacts the message of an underlying error; see castContext.cause.
+func (o evalOptions) cause(err error) error {
+	return o.castContext("").cause(err)
+}
+
 func applyEvalOptions(opts []EvalOption) evalOptions {
 	var o evalOptions
 	for _, opt := range opts {
@@ -0,0 +1,195 @@
+package memebridge
+
+import (
+	"fmt"
+	"strings"
+
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spantype"
+	"github.com/apstndb/spantype/typector"
+	"github.com/cloudspannerecosystem/memefish/ast"
+)
+
+// InferParamTypes infers expected types for query parameters of stmt from
+// the columns they are compared to, assigned to, or inserted into. The
+// supported shapes are:
+//
+//   - comparisons and LIKE: col = @p, @p < t.col
+//   - IN and BETWEEN: col IN (@a, @b), col IN UNNEST(@arr), col BETWEEN @lo AND @hi
+//   - INSERT INTO t (col, ...) VALUES (@p, ...)
+//   - UPDATE t SET col = @p
+//
+// Columns are resolved against the tables that stmt references, by bare
+// column name or by table name/alias qualification. Parameters that do not
+// appear in these shapes are absent from the result. Conflicting inferences
+// for the same parameter are an error.
+//
+// Pass the inferred types to [WithExpectedType] to evaluate parameter values
+// with Spanner's literal coercion, so that '2024-01-01' compared with a DATE
+// column becomes a DATE parameter.
+func (s *Schema) InferParamTypes(stmt ast.Statement) (map[string]*sppb.Type, error) {
+	inf := &paramTypeInferrer{schema: s, scope: newTableScope(s, stmt), types: make(map[string]*sppb.Type)}
+	ast.Inspect(stmt, func(n ast.Node) bool {
+		inf.visit(n)
+		return inf.err == nil
+	})
+	if inf.err != nil {
+		return nil, inf.err
+	}
+	return inf.types, nil
+}
+
+// tableScope resolves column references against the tables a statement
+// references.
+type tableScope struct {
+	tables  []*Table
+	aliases map[string]*Table
+}
+
+func newTableScope(s *Schema, stmt ast.Statement) *tableScope {
+	scope := &tableScope{aliases: make(map[string]*Table)}
+	add := func(name string, as *ast.AsAlias) {
+		t, ok := s.Table(name)
+		if !ok {
+			return
+		}
+		scope.tables = append(scope.tables, t)
+		scope.aliases[strings.ToUpper(name)] = t
+		if as != nil && as.Alias != nil {
+			scope.aliases[strings.ToUpper(as.Alias.Name)] = t
+		}
+	}
+	ast.Inspect(stmt, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.TableName:
+			add(n.Table.Name, n.As)
+		case *ast.Insert:
+			add(pathName(n.TableName), n.As)
+		case *ast.Update:
+			add(pathName(n.TableName), n.As)
+		case *ast.Delete:
+			add(pathName(n.TableName), n.As)
+		}
+		return true
+	})
+	return scope
+}
+
+// columnType returns the type of the column expr refers to, or nil.
+func (sc *tableScope) columnType(expr ast.Expr) *sppb.Type {
+	switch e := unwrapParenExpr(expr).(type) {
+	case *ast.Ident:
+		var found *Column
+		for _, t := range sc.tables {
+			if c, ok := t.Column(e.Name); ok {
+				if found != nil && found != c {
+					// Ambiguous across tables; Spanner would reject it too.
+					return nil
+				}
+				found = c
+			}
+		}
+		if found == nil {
+			return nil
+		}
+		return found.Type
+	case *ast.Path:
+		if len(e.Idents) != 2 {
+			return nil
+		}
+		t, ok := sc.aliases[strings.ToUpper(e.Idents[0].Name)]
+		if !ok {
+			return nil
+		}
+		if c, ok := t.Column(e.Idents[1].Name); ok {
+			return c.Type
+		}
+	}
+	return nil
+}
+
+type paramTypeInferrer struct {
+	schema *Schema
+	scope  *tableScope
+	types  map[string]*sppb.Type
+	err    error
+}
+
+func (inf *paramTypeInferrer) visit(n ast.Node) {
+	switch n := n.(type) {
+	case *ast.BinaryExpr:
+		switch n.Op {
+		case ast.OpEqual, ast.OpNotEqual, ast.OpLess, ast.OpGreater,
+			ast.OpLessEqual, ast.OpGreaterEqual, ast.OpLike, ast.OpNotLike:
+			inf.bindPair(n.Left, n.Right)
+			inf.bindPair(n.Right, n.Left)
+		}
+	case *ast.InExpr:
+		colType := inf.scope.columnType(n.Left)
+		if colType == nil {
+			return
+		}
+		switch cond := n.Right.(type) {
+		case *ast.ValuesInCondition:
+			for _, e := range cond.Exprs {
+				inf.bind(e, colType)
+			}
+		case *ast.UnnestInCondition:
+			inf.bind(cond.Expr, typector.ElemTypeToArrayType(colType))
+		}
+	case *ast.BetweenExpr:
+		if colType := inf.scope.columnType(n.Left); colType != nil {
+			inf.bind(n.RightStart, colType)
+			inf.bind(n.RightEnd, colType)
+		}
+	case *ast.Insert:
+		t, ok := inf.schema.Table(pathName(n.TableName))
+		values, isValues := n.Input.(*ast.ValuesInput)
+		if !ok || !isValues {
+			return
+		}
+		for _, row := range values.Rows {
+			for i, e := range row.Exprs {
+				if i >= len(n.Columns) || e.Default {
+					continue
+				}
+				if c, ok := t.Column(n.Columns[i].Name); ok {
+					inf.bind(e.Expr, c.Type)
+				}
+			}
+		}
+	case *ast.Update:
+		t, ok := inf.schema.Table(pathName(n.TableName))
+		if !ok {
+			return
+		}
+		for _, item := range n.Updates {
+			if item.DefaultExpr == nil || item.DefaultExpr.Default {
+				continue
+			}
+			if c, ok := t.Column(item.Path[len(item.Path)-1].Name); ok {
+				inf.bind(item.DefaultExpr.Expr, c.Type)
+			}
+		}
+	}
+}
+
+// bindPair binds param to the type of the column referenced by other.
+func (inf *paramTypeInferrer) bindPair(param, other ast.Expr) {
+	if colType := inf.scope.columnType(other); colType != nil {
+		inf.bind(param, colType)
+	}
+}
+
+func (inf *paramTypeInferrer) bind(expr ast.Expr, typ *sppb.Type) {
+	p, ok := unwrapParenExpr(expr).(*ast.Param)
+	if !ok || inf.err != nil {
+		return
+	}
+	if prev, ok := inf.types[p.Name]; ok && !spantype.EquivalentTypes(prev, typ) {
+		inf.err = fmt.Errorf("conflicting types inferred for parameter @%s: %s and %s",
+			p.Name, spantype.FormatTypeNormal(prev), spantype.FormatTypeNormal(typ))
+		return
+	}
+	inf.types[p.Name] = typ
+}
@@ -0,0 +1,513 @@
+package memebridge
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spantype"
+	"github.com/apstndb/spanvalue/gcvctor"
+	"github.com/cloudspannerecosystem/memefish"
+	"github.com/cloudspannerecosystem/memefish/ast"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ErrUnsupportedQuery is returned for query shapes that
+// [MemefishQueryToResultSet] cannot evaluate, such as table scans, joins
+// and aggregation.
+var ErrUnsupportedQuery = errors.New("unsupported query")
+
+// ParseQueryToResultSet parses a query with memefish and evaluates it with
+// [MemefishQueryToResultSet]. filename is used only in error positions.
+func ParseQueryToResultSet(filename, sql string, opts ...EvalOption) (*sppb.ResultSet, error) {
+	q, err := memefish.ParseQuery(filename, sql)
+	if err != nil {
+		return nil, err
+	}
+	return MemefishQueryToResultSet(q, opts...)
+}
+
+// MemefishQueryToResultSet evaluates a query that reads no tables and
+// returns its result as Cloud Spanner would, with the column names and
+// types in ResultSetMetadata.RowType. The supported subset is:
+//
+//   - SELECT without FROM: SELECT 1 AS a, 'x' AS b
+//   - FROM UNNEST(array) [AS alias] [WITH OFFSET [AS alias]], where
+//     unnesting an array of STRUCT gives a column per field
+//   - SELECT *, SELECT s.*, WHERE, ORDER BY, LIMIT and OFFSET, and UNION ALL
+//
+// Select items and conditions are expressions that [MemefishExprToGCV]
+// evaluates, combined with column references, field access, comparisons,
+// AND, OR, NOT, IS NULL, IN, BETWEEN, arithmetic on INT64 and FLOAT64,
+// string concatenation and GENERATE_ARRAY. Anything else returns an error
+// wrapping [ErrUnsupportedQuery] or [ErrUnsupportedExpr]. [WithParams]
+// binds parameters; [WithExpectedType], [WithColumns] and [WithRow] are
+// ignored.
+func MemefishQueryToResultSet(q *ast.QueryStatement, opts ...EvalOption) (*sppb.ResultSet, error) {
+	o := applyEvalOptions(opts)
+	o.expectedType, o.columns = nil, nil
+	rel, err := (&queryEvaluator{o: o}).query(q.Query)
+	if err != nil {
+		return nil, err
+	}
+	rs := &sppb.ResultSet{
+		Metadata: &sppb.ResultSetMetadata{RowType: &sppb.StructType{Fields: rel.fields}},
+		Rows:     make([]*structpb.ListValue, len(rel.rows)),
+	}
+	for i, row := range rel.rows {
+		rs.Rows[i] = &structpb.ListValue{Values: row.values}
+	}
+	return rs, nil
+}
+
+// queryEvaluator evaluates queries and row expressions.
+type queryEvaluator struct {
+	o evalOptions
+}
+
+// relation is the result of a query expression.
+type relation struct {
+	fields []*sppb.StructType_Field
+	rows   []*resultRow
+}
+
+// resultRow is an output row with the input row it was computed from, which
+// ORDER BY can still reference.
+type resultRow struct {
+	values []*structpb.Value
+	input  *rowScope
+}
+
+// column returns the GCV of column i of row.
+func (r *relation) column(row *resultRow, i int) spanner.GenericColumnValue {
+	return spanner.GenericColumnValue{Type: r.fields[i].GetType(), Value: row.values[i]}
+}
+
+func (qe *queryEvaluator) query(q ast.QueryExpr) (*relation, error) {
+	switch q := q.(type) {
+	case *ast.Select:
+		return qe.selectQuery(q)
+	case *ast.SubQuery:
+		return qe.query(q.Query)
+	case *ast.Query:
+		switch {
+		case q.With != nil:
+			return nil, fmt.Errorf("%w: WITH", ErrUnsupportedQuery)
+		case q.ForUpdate != nil:
+			return nil, fmt.Errorf("%w: FOR UPDATE", ErrUnsupportedQuery)
+		case len(q.PipeOperators) > 0:
+			return nil, fmt.Errorf("%w: pipe syntax", ErrUnsupportedQuery)
+		}
+		rel, err := qe.query(q.Query)
+		if err != nil {
+			return nil, err
+		}
+		if q.OrderBy != nil {
+			if err := qe.orderBy(rel, q.OrderBy); err != nil {
+				return nil, err
+			}
+		}
+		if q.Limit != nil {
+			if err := qe.limit(rel, q.Limit); err != nil {
+				return nil, err
+			}
+		}
+		return rel, nil
+	case *ast.CompoundQuery:
+		if q.Op != ast.SetOpUnion || q.AllOrDistinct != ast.AllOrDistinctAll {
+			return nil, fmt.Errorf("%w: %s %s", ErrUnsupportedQuery, q.Op, q.AllOrDistinct)
+		}
+		var result *relation
+		for _, sub := range q.Queries {
+			rel, err := qe.query(sub)
+			if err != nil {
+				return nil, err
+			}
+			if result == nil {
+				result = rel
+				continue
+			}
+			if len(rel.fields) != len(result.fields) {
+				return nil, fmt.Errorf("queries in UNION ALL have %d and %d columns", len(result.fields), len(rel.fields))
+			}
+			for i, f := range rel.fields {
+				if !spantype.EquivalentTypes(f.GetType(), result.fields[i].GetType()) {
+					return nil, fmt.Errorf("%w: column %d of UNION ALL has types %s and %s", ErrUnsupportedQuery, i+1,
+						spantype.FormatTypeNormal(result.fields[i].GetType()), spantype.FormatTypeNormal(f.GetType()))
+				}
+			}
+			for _, row := range rel.rows {
+				// Input rows of branches are not visible to an outer ORDER BY.
+				result.rows = append(result.rows, &resultRow{values: row.values})
+			}
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedQuery, q)
+	}
+}
+
+func (qe *queryEvaluator) selectQuery(sel *ast.Select) (*relation, error) {
+	switch {
+	case sel.AllOrDistinct == ast.AllOrDistinctDistinct:
+		return nil, fmt.Errorf("%w: SELECT DISTINCT", ErrUnsupportedQuery)
+	case sel.As != nil:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedQuery, sel.As.SQL())
+	case sel.GroupBy != nil || sel.Having != nil:
+		return nil, fmt.Errorf("%w: GROUP BY and HAVING", ErrUnsupportedQuery)
+	}
+
+	// typeRow holds typed NULLs for the source columns, so that the output
+	// column types are known even when no row remains.
+	typeRow, inputs := &rowScope{}, []*rowScope{{}}
+	if sel.From != nil {
+		var err error
+		if typeRow, inputs, err = qe.from(sel.From.Source); err != nil {
+			return nil, err
+		}
+	}
+
+	items, err := qe.selectItems(sel.Results, typeRow)
+	if err != nil {
+		return nil, err
+	}
+	rel := &relation{}
+	for _, item := range items {
+		typ, err := item.eval(typeRow)
+		if err != nil {
+			return nil, err
+		}
+		rel.fields = append(rel.fields, &sppb.StructType_Field{Name: item.name, Type: typ.Type})
+	}
+
+	for _, input := range inputs {
+		if sel.Where != nil {
+			cond, err := qe.eval(sel.Where.Expr, input)
+			if err != nil {
+				return nil, err
+			}
+			if ok, err := isTrue(cond); err != nil {
+				return nil, fmt.Errorf("WHERE: %w", err)
+			} else if !ok {
+				continue
+			}
+		}
+		row := &resultRow{input: input}
+		for i, item := range items {
+			gcv, err := item.eval(input)
+			if err != nil {
+				return nil, err
+			}
+			if !spantype.EquivalentTypes(gcv.Type, rel.fields[i].GetType()) {
+				return nil, fmt.Errorf("%w: column %d has type %s in one row and %s in another", ErrUnsupportedQuery, i+1,
+					spantype.FormatTypeNormal(rel.fields[i].GetType()), spantype.FormatTypeNormal(gcv.Type))
+			}
+			row.values = append(row.values, ToProtoValue(gcv))
+		}
+		rel.rows = append(rel.rows, row)
+	}
+	return rel, nil
+}
+
+// selectItem is one output column.
+type selectItem struct {
+	name string
+	eval func(*rowScope) (spanner.GenericColumnValue, error)
+}
+
+// selectItems expands stars against the columns of typeRow.
+func (qe *queryEvaluator) selectItems(results []ast.SelectItem, typeRow *rowScope) ([]selectItem, error) {
+	var items []selectItem
+	for _, result := range results {
+		switch r := result.(type) {
+		case *ast.Star:
+			if r.Except != nil || r.Replace != nil {
+				return nil, fmt.Errorf("%w: %s", ErrUnsupportedQuery, r.SQL())
+			}
+			if len(typeRow.star) == 0 {
+				return nil, errors.New("SELECT * must have a FROM clause")
+			}
+			for i, name := range typeRow.star {
+				items = append(items, selectItem{name, func(row *rowScope) (spanner.GenericColumnValue, error) {
+					return row.cols[i].value, nil
+				}})
+			}
+		case *ast.DotStar:
+			if r.Except != nil || r.Replace != nil {
+				return nil, fmt.Errorf("%w: %s", ErrUnsupportedQuery, r.SQL())
+			}
+			typ, err := qe.eval(r.Expr, typeRow)
+			if err != nil {
+				return nil, err
+			}
+			if typ.Type.GetCode() != sppb.TypeCode_STRUCT {
+				return nil, fmt.Errorf("%s.* requires a STRUCT, got %s", r.Expr.SQL(), spantype.FormatTypeNormal(typ.Type))
+			}
+			for i, f := range typ.Type.GetStructType().GetFields() {
+				items = append(items, selectItem{f.GetName(), func(row *rowScope) (spanner.GenericColumnValue, error) {
+					s, err := qe.eval(r.Expr, row)
+					if err != nil {
+						return zeroGCV, err
+					}
+					return structField(s, i), nil
+				}})
+			}
+		case *ast.Alias:
+			items = append(items, qe.exprItem(r.As.Alias.Name, r.Expr))
+		case *ast.ExprSelectItem:
+			items = append(items, qe.exprItem(implicitColumnName(r.Expr), r.Expr))
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedQuery, result.SQL())
+		}
+	}
+	return items, nil
+}
+
+func (qe *queryEvaluator) exprItem(name string, expr ast.Expr) selectItem {
+	return selectItem{name, func(row *rowScope) (spanner.GenericColumnValue, error) {
+		return qe.eval(expr, row)
+	}}
+}
+
+// implicitColumnName is the output name of an unaliased select item: the
+// last name of a column reference or field access, or "".
+func implicitColumnName(expr ast.Expr) string {
+	switch e := unwrapParenExpr(expr).(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.Path:
+		return e.Idents[len(e.Idents)-1].Name
+	case *ast.SelectorExpr:
+		return e.Ident.Name
+	}
+	return ""
+}
+
+// from evaluates a FROM clause to its input rows and a row of typed NULLs.
+func (qe *queryEvaluator) from(source ast.TableExpr) (typeRow *rowScope, rows []*rowScope, err error) {
+	unnest, ok := source.(*ast.Unnest)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: FROM %s; only UNNEST is supported", ErrUnsupportedQuery, source.SQL())
+	}
+	if unnest.Sample != nil {
+		return nil, nil, fmt.Errorf("%w: TABLESAMPLE", ErrUnsupportedQuery)
+	}
+	arr, err := qe.eval(unnest.Expr, &rowScope{})
+	if err != nil {
+		return nil, nil, err
+	}
+	if arr.Type.GetCode() != sppb.TypeCode_ARRAY {
+		return nil, nil, fmt.Errorf("UNNEST requires an ARRAY, got %s", spantype.FormatTypeNormal(arr.Type))
+	}
+
+	elemType := arr.Type.GetArrayElementType()
+	var alias, offsetAlias string
+	if unnest.As != nil {
+		alias = unnest.As.Alias.Name
+	}
+	if unnest.WithOffset != nil {
+		offsetAlias = "offset"
+		if unnest.WithOffset.As != nil {
+			offsetAlias = unnest.WithOffset.As.Alias.Name
+		}
+	}
+	newRow := func(elem, offset spanner.GenericColumnValue) *rowScope {
+		row := &rowScope{}
+		if elemType.GetCode() == sppb.TypeCode_STRUCT {
+			if alias != "" {
+				row.add(alias, elem, false)
+			}
+			for i, f := range elemType.GetStructType().GetFields() {
+				row.add(f.GetName(), structField(elem, i), true)
+			}
+		} else {
+			row.add(alias, elem, true)
+		}
+		if offsetAlias != "" {
+			row.add(offsetAlias, offset, true)
+		}
+		return row
+	}
+
+	typeRow = newRow(gcvctor.NullOf(elemType), gcvctor.NullFromCode(sppb.TypeCode_INT64))
+	if isNullGCV(arr) {
+		return typeRow, nil, nil
+	}
+	for i, v := range arr.Value.GetListValue().GetValues() {
+		elem := spanner.GenericColumnValue{Type: elemType, Value: normalizeValue(v)}
+		rows = append(rows, newRow(elem, gcvctor.Int64Value(int64(i))))
+	}
+	return typeRow, rows, nil
+}
+
+// rowScope is the columns visible to expressions of one row.
+type rowScope struct {
+	cols []scopeColumn
+	// star lists the names of the columns SELECT * expands to; the i-th
+	// name is cols[i].
+	star []string
+	// parent is searched for names not in cols.
+	parent *rowScope
+}
+
+type scopeColumn struct {
+	name  string
+	value spanner.GenericColumnValue
+}
+
+// add adds a column. Columns in SELECT * must be added first.
+func (r *rowScope) add(name string, value spanner.GenericColumnValue, star bool) {
+	r.cols = append(r.cols, scopeColumn{name, value})
+	if star {
+		r.star = append(r.star, name)
+	}
+}
+
+// lookup finds a column by case-insensitive name.
+func (r *rowScope) lookup(name string) (spanner.GenericColumnValue, bool) {
+	for ; r != nil; r = r.parent {
+		for _, c := range r.cols {
+			if c.name != "" && strings.EqualFold(c.name, name) {
+				return c.value, true
+			}
+		}
+	}
+	return zeroGCV, false
+}
+
+// orderBy sorts rel by items that may name output columns, give their
+// 1-based position, or be expressions over the input row. NULLs sort
+// first in ascending order, as in Spanner.
+func (qe *queryEvaluator) orderBy(rel *relation, orderBy *ast.OrderBy) error {
+	type sortKey struct {
+		desc   bool
+		values []spanner.GenericColumnValue
+	}
+	keys := make([]sortKey, len(orderBy.Items))
+	for k, item := range orderBy.Items {
+		if item.Collate != nil {
+			return fmt.Errorf("%w: COLLATE", ErrUnsupportedQuery)
+		}
+		keys[k].desc = item.Dir == ast.DirectionDesc
+		for _, row := range rel.rows {
+			v, err := qe.orderByValue(rel, row, item.Expr)
+			if err != nil {
+				return err
+			}
+			keys[k].values = append(keys[k].values, v)
+		}
+	}
+
+	perm := make([]int, len(rel.rows))
+	for i := range perm {
+		perm[i] = i
+	}
+	var sortErr error
+	slices.SortStableFunc(perm, func(a, b int) int {
+		for _, key := range keys {
+			c, err := qe.compareForOrder(key.values[a], key.values[b])
+			if err != nil {
+				sortErr = err
+				return 0
+			}
+			if key.desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	if sortErr != nil {
+		return fmt.Errorf("ORDER BY: %w", sortErr)
+	}
+	sorted := make([]*resultRow, len(perm))
+	for i, p := range perm {
+		sorted[i] = rel.rows[p]
+	}
+	rel.rows = sorted
+	return nil
+}
+
+func (qe *queryEvaluator) orderByValue(rel *relation, row *resultRow, expr ast.Expr) (spanner.GenericColumnValue, error) {
+	if lit, ok := unwrapParenExpr(expr).(*ast.IntLiteral); ok {
+		n, err := strconv.ParseInt(lit.Value, lit.Base, 64)
+		if err != nil || n < 1 || int(n) > len(rel.fields) {
+			return zeroGCV, fmt.Errorf("ORDER BY column number %s is out of range", lit.Value)
+		}
+		return rel.column(row, int(n)-1), nil
+	}
+	scope := &rowScope{parent: row.input}
+	for i, f := range rel.fields {
+		scope.add(f.GetName(), rel.column(row, i), false)
+	}
+	return qe.eval(expr, scope)
+}
+
+func (qe *queryEvaluator) limit(rel *relation, limit *ast.Limit) error {
+	count, err := qe.intValue(limit.Count)
+	if err != nil {
+		return fmt.Errorf("LIMIT: %w", err)
+	}
+	var offset int64
+	if limit.Offset != nil {
+		if offset, err = qe.intValue(limit.Offset.Value); err != nil {
+			return fmt.Errorf("OFFSET: %w", err)
+		}
+	}
+	start := min(offset, int64(len(rel.rows)))
+	// Clamp count before adding, as LIMIT and OFFSET may be near MaxInt64.
+	end := start + min(count, int64(len(rel.rows))-start)
+	rel.rows = rel.rows[start:end]
+	return nil
+}
+
+func (qe *queryEvaluator) intValue(v ast.IntValue) (int64, error) {
+	if cast, ok := v.(*ast.CastIntValue); ok {
+		v = cast.Expr
+	}
+	expr, ok := v.(ast.Expr)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedQuery, v.SQL())
+	}
+	gcv, err := memefishExprToGCV(expr, qe.o)
+	if err != nil {
+		return 0, err
+	}
+	if gcv.Type.GetCode() != sppb.TypeCode_INT64 || isNullGCV(gcv) {
+		return 0, fmt.Errorf("expected a non-NULL INT64, got %s", spantype.FormatTypeNormal(gcv.Type))
+	}
+	n, err := int64FromGCV(gcv)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("negative value %d", n)
+	}
+	return n, nil
+}
+
+// structField returns field i of a STRUCT value, NULL if s is NULL.
+func structField(s spanner.GenericColumnValue, i int) spanner.GenericColumnValue {
+	typ := s.Type.GetStructType().GetFields()[i].GetType()
+	if isNullGCV(s) {
+		return gcvctor.NullOf(typ)
+	}
+	return spanner.GenericColumnValue{Type: typ, Value: normalizeValue(s.Value.GetListValue().GetValues()[i])}
+}
+
+// isTrue reports whether a condition is TRUE; NULL is not.
+func isTrue(cond spanner.GenericColumnValue) (bool, error) {
+	if cond.Type.GetCode() != sppb.TypeCode_BOOL {
+		return false, fmt.Errorf("expected BOOL, got %s", spantype.FormatTypeNormal(cond.Type))
+	}
+	if isNullGCV(cond) {
+		return false, nil
+	}
+	return boolFromGCV(cond)
+}
@@ -0,0 +1,740 @@
+package memebridge
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spantype"
+	"github.com/apstndb/spantype/typector"
+	"github.com/apstndb/spanvalue/gcvctor"
+	"github.com/cloudspannerecosystem/memefish/ast"
+	"github.com/cloudspannerecosystem/memefish/char"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// maxGenerateArrayElements bounds the arrays GENERATE_ARRAY builds.
+const maxGenerateArrayElements = 1_000_000
+
+// eval evaluates an expression over the columns of row. Other expressions
+// are delegated to memefishExprToGCV with row bound as its columns, so that
+// column references nested in them, as in CAST(x AS STRING), resolve too.
+func (qe *queryEvaluator) eval(expr ast.Expr, row *rowScope) (spanner.GenericColumnValue, error) {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return qe.eval(e.Expr, row)
+	case *ast.Ident:
+		gcv, ok := row.lookup(e.Name)
+		if !ok {
+			return zeroGCV, fmt.Errorf("%w: %s", ErrUnrecognizedName, e.Name)
+		}
+		return gcv, nil
+	case *ast.Path:
+		gcv, ok := row.lookup(e.Idents[0].Name)
+		if !ok {
+			return zeroGCV, fmt.Errorf("%w: %s", ErrUnrecognizedName, e.Idents[0].Name)
+		}
+		for _, ident := range e.Idents[1:] {
+			var err error
+			if gcv, err = fieldByName(gcv, ident.Name); err != nil {
+				return zeroGCV, err
+			}
+		}
+		return gcv, nil
+	case *ast.SelectorExpr:
+		gcv, err := qe.eval(e.Expr, row)
+		if err != nil {
+			return zeroGCV, err
+		}
+		return fieldByName(gcv, e.Ident.Name)
+	case *ast.UnaryExpr:
+		return qe.evalUnary(e, row)
+	case *ast.BinaryExpr:
+		return qe.evalBinary(e, row)
+	case *ast.IsNullExpr:
+		v, err := qe.eval(e.Left, row)
+		if err != nil {
+			return zeroGCV, err
+		}
+		return gcvctor.BoolValue(isNullGCV(v) != e.Not), nil
+	case *ast.IsBoolExpr:
+		v, err := qe.eval(e.Left, row)
+		if err != nil {
+			return zeroGCV, err
+		}
+		if v.Type.GetCode() != sppb.TypeCode_BOOL {
+			return zeroGCV, fmt.Errorf("IS %t requires BOOL, got %s", e.Right, spantype.FormatTypeNormal(v.Type))
+		}
+		is := false
+		if !isNullGCV(v) {
+			b, err := boolFromGCV(v)
+			if err != nil {
+				return zeroGCV, err
+			}
+			is = b == e.Right
+		}
+		return gcvctor.BoolValue(is != e.Not), nil
+	case *ast.BetweenExpr:
+		return qe.evalBetween(e, row)
+	case *ast.InExpr:
+		return qe.evalIn(e, row)
+	case *ast.CallExpr:
+		if len(e.Func.Idents) == 1 && char.EqualFold(e.Func.Idents[0].Name, "GENERATE_ARRAY") {
+			return qe.generateArray(e, row)
+		}
+	}
+	o := qe.o
+	o.columns = row
+	return memefishExprToGCV(expr, o)
+}
+
+// evalOperands evaluates the operands of a comparison. A string literal
+// compared with a non-STRING value is coerced to its type, as in
+// d = '2024-01-01' for a DATE d.
+func (qe *queryEvaluator) evalOperands(left, right ast.Expr, row *rowScope) (l, r spanner.GenericColumnValue, err error) {
+	if l, err = qe.eval(left, row); err != nil {
+		return zeroGCV, zeroGCV, err
+	}
+	if r, err = qe.eval(right, row); err != nil {
+		return zeroGCV, zeroGCV, err
+	}
+	if isStringLiteral(right) && l.Type.GetCode() != sppb.TypeCode_STRING {
+		r, err = memefishExprToGCVWithExpectedType(l.Type, right, qe.o)
+	} else if isStringLiteral(left) && r.Type.GetCode() != sppb.TypeCode_STRING {
+		l, err = memefishExprToGCVWithExpectedType(r.Type, left, qe.o)
+	}
+	return l, r, err
+}
+
+func (qe *queryEvaluator) evalUnary(e *ast.UnaryExpr, row *rowScope) (spanner.GenericColumnValue, error) {
+	v, err := qe.eval(e.Expr, row)
+	if err != nil {
+		return zeroGCV, err
+	}
+	if e.Op == ast.OpNot && isUntypedNullLiteral(e.Expr) {
+		v = gcvctor.NullFromCode(sppb.TypeCode_BOOL)
+	}
+	code := v.Type.GetCode()
+	switch {
+	case e.Op == ast.OpNot && code == sppb.TypeCode_BOOL:
+		if isNullGCV(v) {
+			return v, nil
+		}
+		b, err := boolFromGCV(v)
+		return gcvctor.BoolValue(!b), err
+	case e.Op == ast.OpPlus && (code == sppb.TypeCode_INT64 || code == sppb.TypeCode_FLOAT64):
+		return v, nil
+	case e.Op == ast.OpMinus && code == sppb.TypeCode_INT64:
+		if isNullGCV(v) {
+			return v, nil
+		}
+		n, err := int64FromGCV(v)
+		if err != nil {
+			return zeroGCV, err
+		}
+		if n == math.MinInt64 {
+			return zeroGCV, fmt.Errorf("int64 overflow: %s", qe.o.sql(e))
+		}
+		return gcvctor.Int64Value(-n), nil
+	case e.Op == ast.OpMinus && code == sppb.TypeCode_FLOAT64:
+		if isNullGCV(v) {
+			return v, nil
+		}
+		f, err := float64FromGCV(v, 64)
+		return gcvctor.Float64Value(-f), err
+	}
+	return zeroGCV, fmt.Errorf("%w: %s on %s", ErrUnsupportedExpr, e.Op, spantype.FormatTypeNormal(v.Type))
+}
+
+func (qe *queryEvaluator) evalBinary(e *ast.BinaryExpr, row *rowScope) (spanner.GenericColumnValue, error) {
+	switch e.Op {
+	case ast.OpAnd, ast.OpOr:
+		return qe.evalLogical(e, row)
+	case ast.OpEqual, ast.OpNotEqual, ast.OpLess, ast.OpGreater, ast.OpLessEqual, ast.OpGreaterEqual:
+		l, r, err := qe.evalOperands(e.Left, e.Right, row)
+		if err != nil {
+			return zeroGCV, err
+		}
+		return qe.compareOp(e.Op, l, r)
+	case ast.OpAdd, ast.OpSub, ast.OpMul, ast.OpDiv:
+		l, err := qe.eval(e.Left, row)
+		if err != nil {
+			return zeroGCV, err
+		}
+		r, err := qe.eval(e.Right, row)
+		if err != nil {
+			return zeroGCV, err
+		}
+		return qe.arithmetic(e, l, r)
+	case ast.OpConcat:
+		l, err := qe.eval(e.Left, row)
+		if err != nil {
+			return zeroGCV, err
+		}
+		r, err := qe.eval(e.Right, row)
+		if err != nil {
+			return zeroGCV, err
+		}
+		// An untyped NULL takes the type of the other operand, or STRING.
+		switch {
+		case isUntypedNullLiteral(e.Left) && isUntypedNullLiteral(e.Right):
+			l, r = gcvctor.NullFromCode(sppb.TypeCode_STRING), gcvctor.NullFromCode(sppb.TypeCode_STRING)
+		case isUntypedNullLiteral(e.Left):
+			l = gcvctor.NullOf(r.Type)
+		case isUntypedNullLiteral(e.Right):
+			r = gcvctor.NullOf(l.Type)
+		}
+		return concat(l, r)
+	}
+	return zeroGCV, fmt.Errorf("%w: %s", ErrUnsupportedExpr, qe.o.sql(e))
+}
+
+// evalLogical evaluates AND and OR with three-valued logic.
+func (qe *queryEvaluator) evalLogical(e *ast.BinaryExpr, row *rowScope) (spanner.GenericColumnValue, error) {
+	// dominant is the operand value that decides the result on its own.
+	dominant := e.Op == ast.OpOr
+	sawNull := false
+	for _, operand := range []ast.Expr{e.Left, e.Right} {
+		v, err := qe.eval(operand, row)
+		if err != nil {
+			return zeroGCV, err
+		}
+		if isUntypedNullLiteral(operand) {
+			v = gcvctor.NullFromCode(sppb.TypeCode_BOOL)
+		}
+		if v.Type.GetCode() != sppb.TypeCode_BOOL {
+			return zeroGCV, fmt.Errorf("%s requires BOOL operands, got %s", e.Op, spantype.FormatTypeNormal(v.Type))
+		}
+		if isNullGCV(v) {
+			sawNull = true
+			continue
+		}
+		b, err := boolFromGCV(v)
+		if err != nil {
+			return zeroGCV, err
+		}
+		if b == dominant {
+			return gcvctor.BoolValue(dominant), nil
+		}
+	}
+	if sawNull {
+		return gcvctor.NullOf(typector.Bool()), nil
+	}
+	return gcvctor.BoolValue(!dominant), nil
+}
+
+// compareOp applies a comparison operator, returning NULL if either operand
+// is NULL. NaN compares unequal to every value, including NaN.
+func (qe *queryEvaluator) compareOp(op ast.BinaryOp, l, r spanner.GenericColumnValue) (spanner.GenericColumnValue, error) {
+	if err := checkComparable(l, r); err != nil {
+		return zeroGCV, err
+	}
+	if isNullGCV(l) || isNullGCV(r) {
+		return gcvctor.NullOf(typector.Bool()), nil
+	}
+	if isNaNGCV(l) || isNaNGCV(r) {
+		return gcvctor.BoolValue(op == ast.OpNotEqual), nil
+	}
+	c, err := qe.compare(l, r)
+	if err != nil {
+		return zeroGCV, err
+	}
+	var result bool
+	switch op {
+	case ast.OpEqual:
+		result = c == 0
+	case ast.OpNotEqual:
+		result = c != 0
+	case ast.OpLess:
+		result = c < 0
+	case ast.OpGreater:
+		result = c > 0
+	case ast.OpLessEqual:
+		result = c <= 0
+	case ast.OpGreaterEqual:
+		result = c >= 0
+	}
+	return gcvctor.BoolValue(result), nil
+}
+
+func (qe *queryEvaluator) evalBetween(e *ast.BetweenExpr, row *rowScope) (spanner.GenericColumnValue, error) {
+	v, lo, err := qe.evalOperands(e.Left, e.RightStart, row)
+	if err != nil {
+		return zeroGCV, err
+	}
+	_, hi, err := qe.evalOperands(e.Left, e.RightEnd, row)
+	if err != nil {
+		return zeroGCV, err
+	}
+	geLo, err := qe.compareOp(ast.OpGreaterEqual, v, lo)
+	if err != nil {
+		return zeroGCV, err
+	}
+	leHi, err := qe.compareOp(ast.OpLessEqual, v, hi)
+	if err != nil {
+		return zeroGCV, err
+	}
+	result := and(geLo, leHi)
+	if e.Not {
+		return not(result), nil
+	}
+	return result, nil
+}
+
+// evalIn evaluates IN with three-valued logic: TRUE if an element equals the
+// value, otherwise NULL if the value or an element is NULL.
+func (qe *queryEvaluator) evalIn(e *ast.InExpr, row *rowScope) (spanner.GenericColumnValue, error) {
+	var elems []spanner.GenericColumnValue
+	var v spanner.GenericColumnValue
+	switch cond := e.Right.(type) {
+	case *ast.ValuesInCondition:
+		for _, expr := range cond.Exprs {
+			l, r, err := qe.evalOperands(e.Left, expr, row)
+			if err != nil {
+				return zeroGCV, err
+			}
+			v, elems = l, append(elems, r)
+		}
+	case *ast.UnnestInCondition:
+		var err error
+		if v, err = qe.eval(e.Left, row); err != nil {
+			return zeroGCV, err
+		}
+		arr, err := qe.eval(cond.Expr, row)
+		if err != nil {
+			return zeroGCV, err
+		}
+		if arr.Type.GetCode() != sppb.TypeCode_ARRAY {
+			return zeroGCV, fmt.Errorf("IN UNNEST requires an ARRAY, got %s", spantype.FormatTypeNormal(arr.Type))
+		}
+		if !isNullGCV(arr) {
+			for _, elem := range arr.Value.GetListValue().GetValues() {
+				elems = append(elems, spanner.GenericColumnValue{Type: arr.Type.GetArrayElementType(), Value: normalizeValue(elem)})
+			}
+		}
+	default:
+		return zeroGCV, fmt.Errorf("%w: %s", ErrUnsupportedQuery, qe.o.sql(e))
+	}
+
+	result := gcvctor.BoolValue(false)
+	for _, elem := range elems {
+		eq, err := qe.compareOp(ast.OpEqual, v, elem)
+		if err != nil {
+			return zeroGCV, err
+		}
+		if result = or(result, eq); !isNullGCV(result) && result.Value.GetBoolValue() {
+			break
+		}
+	}
+	if isNullGCV(v) {
+		result = gcvctor.NullOf(typector.Bool())
+	}
+	if e.Not {
+		return not(result), nil
+	}
+	return result, nil
+}
+
+// and, or and not combine non-error BOOL values with three-valued logic.
+func and(a, b spanner.GenericColumnValue) spanner.GenericColumnValue {
+	return not(or(not(a), not(b)))
+}
+
+func or(a, b spanner.GenericColumnValue) spanner.GenericColumnValue {
+	switch {
+	case !isNullGCV(a) && a.Value.GetBoolValue(), !isNullGCV(b) && b.Value.GetBoolValue():
+		return gcvctor.BoolValue(true)
+	case isNullGCV(a) || isNullGCV(b):
+		return gcvctor.NullOf(typector.Bool())
+	}
+	return gcvctor.BoolValue(false)
+}
+
+func not(a spanner.GenericColumnValue) spanner.GenericColumnValue {
+	if isNullGCV(a) {
+		return a
+	}
+	return gcvctor.BoolValue(!a.Value.GetBoolValue())
+}
+
+// arithmetic evaluates + - * / on INT64 and FLOAT64. INT64 operands give
+// INT64 except for /, and a FLOAT64 operand gives FLOAT64.
+func (qe *queryEvaluator) arithmetic(e *ast.BinaryExpr, l, r spanner.GenericColumnValue) (spanner.GenericColumnValue, error) {
+	lc, rc := l.Type.GetCode(), r.Type.GetCode()
+	isNumber := func(c sppb.TypeCode) bool { return c == sppb.TypeCode_INT64 || c == sppb.TypeCode_FLOAT64 }
+	if !isNumber(lc) || !isNumber(rc) {
+		return zeroGCV, fmt.Errorf("%w: %s %s %s", ErrUnsupportedExpr,
+			spantype.FormatTypeNormal(l.Type), e.Op, spantype.FormatTypeNormal(r.Type))
+	}
+	resultCode := sppb.TypeCode_FLOAT64
+	if lc == sppb.TypeCode_INT64 && rc == sppb.TypeCode_INT64 && e.Op != ast.OpDiv {
+		resultCode = sppb.TypeCode_INT64
+	}
+	if isNullGCV(l) || isNullGCV(r) {
+		return gcvctor.NullFromCode(resultCode), nil
+	}
+
+	if resultCode == sppb.TypeCode_INT64 {
+		a, err := int64FromGCV(l)
+		if err != nil {
+			return zeroGCV, err
+		}
+		b, err := int64FromGCV(r)
+		if err != nil {
+			return zeroGCV, err
+		}
+		var n int64
+		ok := true
+		switch e.Op {
+		case ast.OpAdd:
+			n, ok = safeAddInt64(a, b)
+		case ast.OpSub:
+			n, ok = safeSubInt64(a, b)
+		case ast.OpMul:
+			n, ok = safeMulInt64(a, b)
+		}
+		if !ok {
+			return zeroGCV, fmt.Errorf("int64 overflow: %s", qe.o.sql(e))
+		}
+		return gcvctor.Int64Value(n), nil
+	}
+
+	a, err := numberFromGCV(l)
+	if err != nil {
+		return zeroGCV, err
+	}
+	b, err := numberFromGCV(r)
+	if err != nil {
+		return zeroGCV, err
+	}
+	var f float64
+	switch e.Op {
+	case ast.OpAdd:
+		f = a + b
+	case ast.OpSub:
+		f = a - b
+	case ast.OpMul:
+		f = a * b
+	case ast.OpDiv:
+		if b == 0 {
+			return zeroGCV, fmt.Errorf("division by zero: %s", qe.o.sql(e))
+		}
+		f = a / b
+	}
+	if math.IsInf(f, 0) && !math.IsInf(a, 0) && !math.IsInf(b, 0) {
+		return zeroGCV, fmt.Errorf("float64 overflow: %s", qe.o.sql(e))
+	}
+	return gcvctor.Float64Value(f), nil
+}
+
+func safeMulInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	n := a * b
+	if n/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+	return n, true
+}
+
+// numberFromGCV returns a non-NULL INT64 or FLOAT64 as float64.
+func numberFromGCV(gcv spanner.GenericColumnValue) (float64, error) {
+	if gcv.Type.GetCode() == sppb.TypeCode_INT64 {
+		n, err := int64FromGCV(gcv)
+		return float64(n), err
+	}
+	return float64FromGCV(gcv, 64)
+}
+
+// concat evaluates || on STRING or BYTES.
+func concat(l, r spanner.GenericColumnValue) (spanner.GenericColumnValue, error) {
+	code := l.Type.GetCode()
+	if code != r.Type.GetCode() || (code != sppb.TypeCode_STRING && code != sppb.TypeCode_BYTES) {
+		return zeroGCV, fmt.Errorf("%w: %s || %s", ErrUnsupportedExpr,
+			spantype.FormatTypeNormal(l.Type), spantype.FormatTypeNormal(r.Type))
+	}
+	if isNullGCV(l) || isNullGCV(r) {
+		return gcvctor.NullFromCode(code), nil
+	}
+	if code == sppb.TypeCode_BYTES {
+		a, err := bytesFromGCV(l)
+		if err != nil {
+			return zeroGCV, err
+		}
+		b, err := bytesFromGCV(r)
+		if err != nil {
+			return zeroGCV, err
+		}
+		return gcvctor.BytesValue(append(a, b...)), nil
+	}
+	a, err := stringFromGCV(l)
+	if err != nil {
+		return zeroGCV, err
+	}
+	b, err := stringFromGCV(r)
+	if err != nil {
+		return zeroGCV, err
+	}
+	return gcvctor.StringValue(a + b), nil
+}
+
+// generateArray evaluates GENERATE_ARRAY(start, end[, step]) on INT64 or
+// FLOAT64 arguments. It returns NULL if any argument is NULL.
+func (qe *queryEvaluator) generateArray(e *ast.CallExpr, row *rowScope) (spanner.GenericColumnValue, error) {
+	if len(e.Args) < 2 || len(e.Args) > 3 || len(e.NamedArgs) > 0 || e.Distinct {
+		return zeroGCV, fmt.Errorf("GENERATE_ARRAY takes 2 or 3 arguments: %s", qe.o.sql(e))
+	}
+	args := []spanner.GenericColumnValue{zeroGCV, zeroGCV, gcvctor.Int64Value(1)}
+	elemCode, anyNull := sppb.TypeCode_INT64, false
+	for i, arg := range e.Args {
+		exprArg, ok := arg.(*ast.ExprArg)
+		if !ok {
+			return zeroGCV, fmt.Errorf("%w: %s", ErrUnsupportedExpr, qe.o.sql(e))
+		}
+		v, err := qe.eval(exprArg.Expr, row)
+		if err != nil {
+			return zeroGCV, err
+		}
+		switch v.Type.GetCode() {
+		case sppb.TypeCode_INT64:
+		case sppb.TypeCode_FLOAT64:
+			elemCode = sppb.TypeCode_FLOAT64
+		default:
+			return zeroGCV, fmt.Errorf("GENERATE_ARRAY requires INT64 or FLOAT64 arguments, got %s", spantype.FormatTypeNormal(v.Type))
+		}
+		anyNull = anyNull || isNullGCV(v)
+		args[i] = v
+	}
+	arrayType := typector.ElemCodeToArrayType(elemCode)
+	if anyNull {
+		return gcvctor.NullOf(arrayType), nil
+	}
+
+	var values []*structpb.Value
+	if elemCode == sppb.TypeCode_INT64 {
+		start, end, step, err := int64Args(args)
+		if err != nil {
+			return zeroGCV, err
+		}
+		if step == 0 {
+			return zeroGCV, errors.New("GENERATE_ARRAY: step cannot be 0")
+		}
+		for n := start; (step > 0 && n <= end) || (step < 0 && n >= end); {
+			if len(values) == maxGenerateArrayElements {
+				return zeroGCV, fmt.Errorf("GENERATE_ARRAY: more than %d elements", maxGenerateArrayElements)
+			}
+			values = append(values, gcvctor.Int64Value(n).Value)
+			var ok bool
+			if n, ok = safeAddInt64(n, step); !ok {
+				break
+			}
+		}
+	} else {
+		var f [3]float64
+		for i, arg := range args {
+			var err error
+			if f[i], err = numberFromGCV(arg); err != nil {
+				return zeroGCV, err
+			}
+		}
+		start, end, step := f[0], f[1], f[2]
+		if step == 0 || math.IsNaN(step) {
+			return zeroGCV, errors.New("GENERATE_ARRAY: step cannot be 0 or NaN")
+		}
+		if math.IsInf(start, 0) || math.IsInf(end, 0) || math.IsNaN(start) || math.IsNaN(end) {
+			return zeroGCV, errors.New("GENERATE_ARRAY: start and end must be finite")
+		}
+		for i := 0; ; i++ {
+			v := start + float64(i)*step
+			if (step > 0 && v > end) || (step < 0 && v < end) {
+				break
+			}
+			if len(values) == maxGenerateArrayElements {
+				return zeroGCV, fmt.Errorf("GENERATE_ARRAY: more than %d elements", maxGenerateArrayElements)
+			}
+			values = append(values, gcvctor.Float64Value(v).Value)
+		}
+	}
+	return spanner.GenericColumnValue{
+		Type:  arrayType,
+		Value: structpb.NewListValue(&structpb.ListValue{Values: values}),
+	}, nil
+}
+
+func int64Args(args []spanner.GenericColumnValue) (start, end, step int64, err error) {
+	var n [3]int64
+	for i, arg := range args {
+		if n[i], err = int64FromGCV(arg); err != nil {
+			return 0, 0, 0, err
+		}
+	}
+	return n[0], n[1], n[2], nil
+}
+
+// fieldByName returns the field of a STRUCT value with a case-insensitive
+// name, NULL if the STRUCT is NULL.
+func fieldByName(s spanner.GenericColumnValue, name string) (spanner.GenericColumnValue, error) {
+	if s.Type.GetCode() != sppb.TypeCode_STRUCT {
+		return zeroGCV, fmt.Errorf("cannot access field %s on a value with type %s", name, spantype.FormatTypeNormal(s.Type))
+	}
+	for i, f := range s.Type.GetStructType().GetFields() {
+		if strings.EqualFold(f.GetName(), name) {
+			return structField(s, i), nil
+		}
+	}
+	return zeroGCV, fmt.Errorf("field name %s does not exist in %s", name, spantype.FormatTypeNormal(s.Type))
+}
+
+// isNumericCode reports whether values of code compare as numbers.
+func isNumericCode(code sppb.TypeCode) bool {
+	switch code {
+	case sppb.TypeCode_INT64, sppb.TypeCode_FLOAT32, sppb.TypeCode_FLOAT64, sppb.TypeCode_NUMERIC:
+		return true
+	}
+	return false
+}
+
+// checkComparable reports an error unless l and r have types whose values
+// can be compared.
+func checkComparable(l, r spanner.GenericColumnValue) error {
+	lc, rc := l.Type.GetCode(), r.Type.GetCode()
+	switch {
+	case isNumericCode(lc) && isNumericCode(rc):
+		return nil
+	case lc != rc:
+	case lc == sppb.TypeCode_BOOL, lc == sppb.TypeCode_STRING, lc == sppb.TypeCode_BYTES,
+		lc == sppb.TypeCode_DATE, lc == sppb.TypeCode_TIMESTAMP, lc == sppb.TypeCode_UUID:
+		return nil
+	}
+	return fmt.Errorf("%w: cannot compare %s and %s", ErrUnsupportedExpr,
+		spantype.FormatTypeNormal(l.Type), spantype.FormatTypeNormal(r.Type))
+}
+
+func isNaNGCV(gcv spanner.GenericColumnValue) bool {
+	code := gcv.Type.GetCode()
+	if isNullGCV(gcv) || (code != sppb.TypeCode_FLOAT32 && code != sppb.TypeCode_FLOAT64) {
+		return false
+	}
+	f, err := float64FromGCV(gcv, 64)
+	return err == nil && math.IsNaN(f)
+}
+
+// compare orders two non-NULL values of comparable types. NaN is less than
+// every other number.
+func (qe *queryEvaluator) compare(l, r spanner.GenericColumnValue) (int, error) {
+	if err := checkComparable(l, r); err != nil {
+		return 0, err
+	}
+	switch lc, rc := l.Type.GetCode(), r.Type.GetCode(); {
+	case lc == sppb.TypeCode_INT64 && rc == sppb.TypeCode_INT64:
+		a, err := int64FromGCV(l)
+		if err != nil {
+			return 0, err
+		}
+		b, err := int64FromGCV(r)
+		return cmp.Compare(a, b), err
+	case lc == sppb.TypeCode_NUMERIC || rc == sppb.TypeCode_NUMERIC:
+		if isNaNGCV(l) || isNaNGCV(r) {
+			return cmp.Compare(nanRank(l), nanRank(r)), nil
+		}
+		a, err := castGCV(l, typector.Numeric(), qe.o.castContext(""))
+		if err != nil {
+			return 0, err
+		}
+		b, err := castGCV(r, typector.Numeric(), qe.o.castContext(""))
+		if err != nil {
+			return 0, err
+		}
+		x, err := numericFromGCV(a)
+		if err != nil {
+			return 0, err
+		}
+		y, err := numericFromGCV(b)
+		if err != nil {
+			return 0, err
+		}
+		return x.Cmp(y), nil
+	case isNumericCode(lc):
+		a, err := numberOrFloatFromGCV(l)
+		if err != nil {
+			return 0, err
+		}
+		b, err := numberOrFloatFromGCV(r)
+		return cmp.Compare(a, b), err
+	case lc == sppb.TypeCode_BOOL:
+		a, err := boolFromGCV(l)
+		if err != nil {
+			return 0, err
+		}
+		b, err := boolFromGCV(r)
+		return cmp.Compare(boolRank(a), boolRank(b)), err
+	case lc == sppb.TypeCode_BYTES:
+		a, err := bytesFromGCV(l)
+		if err != nil {
+			return 0, err
+		}
+		b, err := bytesFromGCV(r)
+		return bytes.Compare(a, b), err
+	case lc == sppb.TypeCode_DATE:
+		a, err := dateFromGCV(l)
+		if err != nil {
+			return 0, err
+		}
+		b, err := dateFromGCV(r)
+		return a.Compare(b), err
+	case lc == sppb.TypeCode_TIMESTAMP:
+		a, err := timestampFromGCV(l, qe.o.castContext(""))
+		if err != nil {
+			return 0, err
+		}
+		b, err := timestampFromGCV(r, qe.o.castContext(""))
+		return a.Compare(b), err
+	default:
+		a, err := stringFromGCV(l)
+		if err != nil {
+			return 0, err
+		}
+		b, err := stringFromGCV(r)
+		return strings.Compare(a, b), err
+	}
+}
+
+// compareForOrder orders values for ORDER BY, with NULL first.
+func (qe *queryEvaluator) compareForOrder(l, r spanner.GenericColumnValue) (int, error) {
+	if err := checkComparable(l, r); err != nil {
+		return 0, err
+	}
+	switch ln, rn := isNullGCV(l), isNullGCV(r); {
+	case ln || rn:
+		return cmp.Compare(boolRank(!ln), boolRank(!rn)), nil
+	}
+	return qe.compare(l, r)
+}
+
+// numberOrFloatFromGCV is numberFromGCV that also accepts FLOAT32.
+func numberOrFloatFromGCV(gcv spanner.GenericColumnValue) (float64, error) {
+	if gcv.Type.GetCode() == sppb.TypeCode_FLOAT32 {
+		return float64FromGCV(gcv, 32)
+	}
+	return numberFromGCV(gcv)
+}
+
+func nanRank(gcv spanner.GenericColumnValue) int {
+	if isNaNGCV(gcv) {
+		return 0
+	}
+	return 1
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
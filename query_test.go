@@ -0,0 +1,173 @@
+package memebridge_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spantype"
+	"github.com/apstndb/spanvalue/gcvctor"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/apstndb/memebridge"
+)
+
+// formatResultSet renders columns as "name TYPE" and rows as JSON arrays of
+// wire values.
+func formatResultSet(t *testing.T, rs *sppb.ResultSet) (columns, rows []string) {
+	t.Helper()
+	for _, f := range rs.GetMetadata().GetRowType().GetFields() {
+		columns = append(columns, f.GetName()+" "+spantype.FormatTypeVerbose(f.GetType()))
+	}
+	for _, row := range rs.GetRows() {
+		b := must(protojson.Marshal(row))
+		rows = append(rows, strings.ReplaceAll(string(b), " ", ""))
+	}
+	return columns, rows
+}
+
+func TestParseQueryToResultSet(t *testing.T) {
+	tests := []struct {
+		sql         string
+		wantColumns []string
+		wantRows    []string
+	}{
+		{
+			`SELECT 1 AS a, 'x' AS b`,
+			[]string{"a INT64", "b STRING"},
+			[]string{`["1","x"]`},
+		},
+		{
+			`SELECT * FROM UNNEST([STRUCT(1 AS id, 'a' AS name)])`,
+			[]string{"id INT64", "name STRING"},
+			[]string{`["1","a"]`},
+		},
+		{
+			`SELECT x FROM UNNEST(GENERATE_ARRAY(1,5)) AS x WHERE x > 2 ORDER BY x DESC LIMIT 2`,
+			[]string{"x INT64"},
+			[]string{`["5"]`, `["4"]`},
+		},
+		{
+			`SELECT s.name, s, o FROM UNNEST([STRUCT(1 AS id, 'a' AS name), (2, 'b')]) AS s WITH OFFSET AS o WHERE id IN (2, 3)`,
+			[]string{"name STRING", "s STRUCT<id INT64, name STRING>", "o INT64"},
+			[]string{`["b",["2","b"],"1"]`},
+		},
+		{
+			`SELECT s.* FROM UNNEST([STRUCT(1 AS id, 'a' AS name)]) AS s`,
+			[]string{"id INT64", "name STRING"},
+			[]string{`["1","a"]`},
+		},
+		{
+			`SELECT x * 2 AS y, x / 2, -x FROM UNNEST([3, NULL, 1]) AS x ORDER BY x LIMIT 10 OFFSET 1`,
+			[]string{"y INT64", " FLOAT64", " INT64"},
+			[]string{`["2",0.5,"-1"]`, `["6",1.5,"-3"]`},
+		},
+		{
+			`SELECT x FROM UNNEST([1, 2, 3]) AS x LIMIT 9223372036854775807 OFFSET 1`,
+			[]string{"x INT64"},
+			[]string{`["2"]`, `["3"]`},
+		},
+		{
+			`SELECT 1 AS x LIMIT 9223372036854775807 OFFSET 9223372036854775807`,
+			[]string{"x INT64"},
+			nil,
+		},
+		{
+			`SELECT d FROM UNNEST([DATE '2024-01-02', DATE '2024-01-01']) AS d WHERE d BETWEEN '2024-01-01' AND '2024-01-01' OR d IS NULL`,
+			[]string{"d DATE"},
+			[]string{`["2024-01-01"]`},
+		},
+		{
+			`SELECT x FROM UNNEST(GENERATE_ARRAY(0, 1, 0.5)) AS x ORDER BY 1 DESC`,
+			[]string{"x FLOAT64"},
+			[]string{`[1]`, `[0.5]`, `[0]`},
+		},
+		{
+			`SELECT x FROM UNNEST([1, 2]) AS x WHERE x > 5`,
+			[]string{"x INT64"},
+			nil,
+		},
+		{
+			`SELECT NOT NULL AS a, x > 1 OR NULL AS b, NULL AND FALSE AS c, 'a' || NULL AS d, NULL || b'a' AS e, NULL || NULL AS f FROM UNNEST([0]) AS x`,
+			[]string{"a BOOL", "b BOOL", "c BOOL", "d STRING", "e BYTES", "f STRING"},
+			[]string{`[null,null,false,null,null,null]`},
+		},
+		{
+			`SELECT x FROM UNNEST([1, 2]) AS x WHERE x > 1 OR NULL`,
+			[]string{"x INT64"},
+			[]string{`["2"]`},
+		},
+		{
+			`(SELECT 'a' || 'b' AS s) UNION ALL (SELECT 'c') ORDER BY s`,
+			[]string{"s STRING"},
+			[]string{`["ab"]`, `["c"]`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			rs, err := memebridge.ParseQueryToResultSet("query.sql", tt.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			columns, rows := formatResultSet(t, rs)
+			if diff := cmp.Diff(tt.wantColumns, columns); diff != "" {
+				t.Errorf("columns mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantRows, rows); diff != "" {
+				t.Errorf("rows mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseQueryToResultSetParams(t *testing.T) {
+	params := map[string]spanner.GenericColumnValue{"n": gcvctor.Int64Value(3)}
+	rs, err := memebridge.ParseQueryToResultSet("query.sql",
+		`SELECT x FROM UNNEST(GENERATE_ARRAY(1, @n)) AS x LIMIT @n OFFSET 1`, memebridge.WithParams(params))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, rows := formatResultSet(t, rs); !cmp.Equal([]string{`["2"]`, `["3"]`}, rows) {
+		t.Errorf("rows = %v", rows)
+	}
+}
+
+func TestParseQueryToResultSetErrors(t *testing.T) {
+	tests := []struct {
+		sql     string
+		wantErr error
+		wantMsg string
+	}{
+		{`SELECT * FROM Singers`, memebridge.ErrUnsupportedQuery, "only UNNEST"},
+		{`SELECT DISTINCT 1`, memebridge.ErrUnsupportedQuery, "DISTINCT"},
+		{`SELECT COUNT(*) FROM UNNEST([1]) GROUP BY 1`, memebridge.ErrUnsupportedQuery, "GROUP BY"},
+		{`WITH t AS (SELECT 1) SELECT 1`, memebridge.ErrUnsupportedQuery, "WITH"},
+		{`SELECT 1 UNION DISTINCT SELECT 2`, memebridge.ErrUnsupportedQuery, "UNION DISTINCT"},
+		{`SELECT x`, nil, "unrecognized name: x"},
+		{`SELECT x FROM UNNEST([1]) AS x WHERE x`, nil, "expected BOOL"},
+		{`SELECT x FROM UNNEST([1]) AS x WHERE x = 'a'`, nil, "cannot coerce"},
+		{`SELECT b'a' < 1`, memebridge.ErrUnsupportedExpr, "cannot compare BYTES and INT64"},
+		{`SELECT 9223372036854775807 + 1`, nil, "int64 overflow"},
+		{`SELECT 1 / 0`, nil, "division by zero"},
+		{`SELECT * FROM UNNEST(GENERATE_ARRAY(1, 5, 0))`, nil, "step cannot be 0"},
+		{`SELECT 1 ORDER BY 2`, nil, "out of range"},
+		{`SELECT 1 LIMIT -1`, nil, "syntax error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			_, err := memebridge.ParseQueryToResultSet("query.sql", tt.sql)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("err = %v, want one containing %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package memebridge
+
+import (
+	"fmt"
+
+	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spantype/typector"
+	"github.com/apstndb/spanvalue/gcvctor"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ParamsToProto converts parameters, such as those parsed by cliparams, to
+// the params and param_types fields of an ExecuteSqlRequest for callers of
+// the raw gRPC API. A nil or empty map gives nil results.
+func ParamsToProto(params map[string]spanner.GenericColumnValue) (*structpb.Struct, map[string]*sppb.Type) {
+	if len(params) == 0 {
+		return nil, nil
+	}
+	fields := make(map[string]*structpb.Value, len(params))
+	types := make(map[string]*sppb.Type, len(params))
+	for name, gcv := range params {
+		fields[name] = ToProtoValue(gcv)
+		types[name] = gcv.Type
+	}
+	return &structpb.Struct{Fields: fields}, types
+}
+
+// ParamsFromProto converts the params and param_types fields of a request
+// back to GenericColumnValues. A parameter declared only in paramTypes is a
+// typed NULL. A parameter without a declared type must have a string value,
+// which is taken as a STRING; Spanner would infer its type from the SQL.
+func ParamsFromProto(params *structpb.Struct, paramTypes map[string]*sppb.Type) (map[string]spanner.GenericColumnValue, error) {
+	result := make(map[string]spanner.GenericColumnValue, len(paramTypes))
+	for name, typ := range paramTypes {
+		result[name] = gcvctor.NullOf(typ)
+	}
+	for name, v := range params.GetFields() {
+		typ, ok := paramTypes[name]
+		if !ok {
+			if _, isString := v.GetKind().(*structpb.Value_StringValue); !isString {
+				return nil, fmt.Errorf("parameter @%s has no type in param_types and a non-string value", name)
+			}
+			typ = typector.String()
+		}
+		result[name] = spanner.GenericColumnValue{Type: typ, Value: normalizeValue(v)}
+	}
+	return result, nil
+}
+
+// ParamsRequest is a request with query parameters, such as
+// *sppb.ExecuteSqlRequest, *sppb.PartitionQueryRequest or
+// *sppb.ExecuteBatchDmlRequest_Statement.
+type ParamsRequest interface {
+	GetParams() *structpb.Struct
+	GetParamTypes() map[string]*sppb.Type
+}
+
+// ParamsFromRequest converts the parameters of req with [ParamsFromProto].
+func ParamsFromRequest(req ParamsRequest) (map[string]spanner.GenericColumnValue, error) {
+	return ParamsFromProto(req.GetParams(), req.GetParamTypes())
+}
+
+// ParamsFromRequestJSON converts the parameters of an ExecuteSqlRequest in
+// the JSON form of the Spanner REST API, with "params" and "paramTypes"
+// fields, with [ParamsFromProto]. Other fields of the body are ignored.
+func ParamsFromRequestJSON(data []byte) (map[string]spanner.GenericColumnValue, error) {
+	var req sppb.ExecuteSqlRequest
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, &req); err != nil {
+		return nil, fmt.Errorf("parsing request JSON: %w", err)
+	}
+	return ParamsFromRequest(&req)
+}
+
+// ParamsToRequestJSON returns a REST API request body fragment holding the
+// "params" and "paramTypes" fields for params, for example
+//
+//	{"params":{"id":"1"},"paramTypes":{"id":{"code":"INT64"}}}
+//
+// Callers add "sql" and the other fields of their request.
+func ParamsToRequestJSON(params map[string]spanner.GenericColumnValue) ([]byte, error) {
+	values, types := ParamsToProto(params)
+	return protojson.Marshal(&sppb.ExecuteSqlRequest{Params: values, ParamTypes: types})
+}
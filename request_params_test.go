@@ -0,0 +1,78 @@
+package memebridge_test
+
+import (
+	"testing"
+
+	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spantype/typector"
+	"github.com/apstndb/spanvalue/gcvctor"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/apstndb/memebridge"
+)
+
+func TestParamsProtoRoundTrip(t *testing.T) {
+	params := map[string]spanner.GenericColumnValue{
+		"id":   gcvctor.Int64Value(1),
+		"tags": must(memebridge.ParseExprToGCV(`['a', NULL]`)),
+		"note": gcvctor.NullOf(typector.String()),
+	}
+	values, types := memebridge.ParamsToProto(params)
+	if got := values.GetFields()["note"].GetKind(); got == nil {
+		t.Errorf("NULL parameter has no wire value")
+	}
+	req := &sppb.ExecuteSqlRequest{Sql: "SELECT @id", Params: values, ParamTypes: types}
+	got, err := memebridge.ParamsFromRequest(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(params, got, protocmp.Transform()); diff != "" {
+		t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+	}
+
+	body, err := memebridge.ParamsToRequestJSON(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = memebridge.ParamsFromRequestJSON(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(params, got, protocmp.Transform()); diff != "" {
+		t.Errorf("JSON round trip mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParamsFromRequestJSON(t *testing.T) {
+	body := `{
+  "session": "projects/p/instances/i/databases/d/sessions/s",
+  "sql": "SELECT @d, @s, @n",
+  "params": {"d": "2024-01-01", "s": "x"},
+  "paramTypes": {"d": {"code": "DATE"}, "n": {"code": "ARRAY", "arrayElementType": {"code": "INT64"}}},
+  "queryMode": "PLAN"
+}`
+	got, err := memebridge.ParamsFromRequestJSON([]byte(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]spanner.GenericColumnValue{
+		"d": must(memebridge.ParseExprToGCV(`DATE '2024-01-01'`)),
+		"s": gcvctor.StringValue("x"),
+		"n": gcvctor.NullOf(typector.ElemCodeToArrayType(sppb.TypeCode_INT64)),
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, body := range []string{`{"params": {"n": 1}}`, `{"params": 1}`} {
+		if _, err := memebridge.ParamsFromRequestJSON([]byte(body)); err == nil {
+			t.Errorf("ParamsFromRequestJSON(%s): expected error", body)
+		}
+	}
+	if v, _ := memebridge.ParamsToProto(nil); v != nil {
+		t.Errorf("ParamsToProto(nil) = %v, want nil", protojson.Format(v))
+	}
+}
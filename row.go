@@ -0,0 +1,118 @@
+package memebridge
+
+import (
+	"fmt"
+
+	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spantype"
+	"google.golang.org/api/iterator"
+)
+
+// ParseExprToRow evaluates a STRUCT expression and returns it as a
+// *spanner.Row whose columns are the STRUCT fields, for unit tests of row
+// decoding code:
+//
+//	row, err := ParseExprToRow(`STRUCT(1 AS id, ['a'] AS tags, CAST(NULL AS STRING) AS note)`)
+//
+// A tuple such as (1, 'a') takes its column names and types from
+// [WithExpectedType] with a STRUCT type. Unnamed fields give columns named "".
+func ParseExprToRow(expr string, opts ...EvalOption) (*spanner.Row, error) {
+	gcv, err := ParseExprToGCV(expr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return GCVToRow(gcv)
+}
+
+// ParseExprToRows evaluates an ARRAY<STRUCT<...>> expression and returns its
+// elements as rows, as [ParseExprToRow] does for one STRUCT. With
+// [WithExpectedType], the type must be the ARRAY type.
+func ParseExprToRows(expr string, opts ...EvalOption) ([]*spanner.Row, error) {
+	gcv, err := ParseExprToGCV(expr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return GCVToRows(gcv)
+}
+
+// GCVToRow converts a non-NULL STRUCT value to a *spanner.Row.
+func GCVToRow(gcv spanner.GenericColumnValue) (*spanner.Row, error) {
+	if gcv.Type.GetCode() != sppb.TypeCode_STRUCT {
+		return nil, fmt.Errorf("row must be a STRUCT, got %s", spantype.FormatTypeNormal(gcv.Type))
+	}
+	if isNullGCV(gcv) {
+		return nil, fmt.Errorf("row must not be NULL")
+	}
+	fields := gcv.Type.GetStructType().GetFields()
+	names := make([]string, len(fields))
+	values := make([]any, len(fields))
+	for i, f := range fields {
+		names[i] = f.GetName()
+		values[i] = structField(gcv, i)
+	}
+	return spanner.NewRow(names, values)
+}
+
+// GCVToRows converts an ARRAY<STRUCT<...>> value to rows with [GCVToRow]. A
+// NULL array gives no rows.
+func GCVToRows(gcv spanner.GenericColumnValue) ([]*spanner.Row, error) {
+	if gcv.Type.GetCode() != sppb.TypeCode_ARRAY || gcv.Type.GetArrayElementType().GetCode() != sppb.TypeCode_STRUCT {
+		return nil, fmt.Errorf("rows must be an ARRAY<STRUCT<...>>, got %s", spantype.FormatTypeNormal(gcv.Type))
+	}
+	if isNullGCV(gcv) {
+		return nil, nil
+	}
+	values := gcv.Value.GetListValue().GetValues()
+	rows := make([]*spanner.Row, len(values))
+	for i, v := range values {
+		row, err := GCVToRow(spanner.GenericColumnValue{Type: gcv.Type.GetArrayElementType(), Value: normalizeValue(v)})
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
+		rows[i] = row
+	}
+	return rows, nil
+}
+
+// RowIterator is a stand-in for *spanner.RowIterator over fixed rows, for
+// code that reads rows through an interface with its Next, Do and Stop
+// methods.
+type RowIterator struct {
+	rows []*spanner.Row
+}
+
+// NewRowIterator returns an iterator over rows.
+func NewRowIterator(rows []*spanner.Row) *RowIterator {
+	return &RowIterator{rows: rows}
+}
+
+// Next returns the next row, or iterator.Done after the last row or Stop.
+func (it *RowIterator) Next() (*spanner.Row, error) {
+	if len(it.rows) == 0 {
+		return nil, iterator.Done
+	}
+	row := it.rows[0]
+	it.rows = it.rows[1:]
+	return row, nil
+}
+
+// Do calls f for each remaining row until f returns an error, which Do
+// returns. Like *spanner.RowIterator.Do, it stops the iterator.
+func (it *RowIterator) Do(f func(*spanner.Row) error) error {
+	defer it.Stop()
+	for {
+		row, err := it.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err := f(row); err != nil {
+			return err
+		}
+	}
+}
+
+// Stop discards the remaining rows.
+func (it *RowIterator) Stop() {
+	it.rows = nil
+}
@@ -0,0 +1,86 @@
+package memebridge_test
+
+import (
+	"testing"
+
+	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spantype/typector"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/apstndb/memebridge"
+)
+
+func TestParseExprToRow(t *testing.T) {
+	row, err := memebridge.ParseExprToRow(`STRUCT(1 AS id, ['a'] AS tags, CAST(NULL AS STRING) AS note)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		id   int64
+		tags []string
+		note spanner.NullString
+	)
+	if err := row.Columns(&id, &tags, &note); err != nil {
+		t.Fatal(err)
+	}
+	if id != 1 || !cmp.Equal(tags, []string{"a"}) || note.Valid {
+		t.Errorf("got id=%d tags=%v note=%v", id, tags, note)
+	}
+	if diff := cmp.Diff([]string{"id", "tags", "note"}, row.ColumnNames()); diff != "" {
+		t.Errorf("column names mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseExprToRowTuple(t *testing.T) {
+	typ := typector.MustNameTypeSlicesToStructType([]string{"SingerId", "Name"}, []*sppb.Type{typector.Int64(), typector.String()})
+	row, err := memebridge.ParseExprToRow(`(1, 'a')`, memebridge.WithExpectedType(typ))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s struct {
+		SingerId int64
+		Name     string
+	}
+	if err := row.ToStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if s.SingerId != 1 || s.Name != "a" {
+		t.Errorf("got %+v", s)
+	}
+}
+
+func TestParseExprToRows(t *testing.T) {
+	rows, err := memebridge.ParseExprToRows(`[STRUCT(1 AS id, 'a' AS name), (2, 'b')]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	err = memebridge.NewRowIterator(rows).Do(func(r *spanner.Row) error {
+		var name string
+		if err := r.ColumnByName("name", &name); err != nil {
+			return err
+		}
+		names = append(names, name)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"a", "b"}, names); diff != "" {
+		t.Errorf("names mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseExprToRowErrors(t *testing.T) {
+	for _, expr := range []string{`1`, `CAST(NULL AS STRUCT<a INT64>)`} {
+		if _, err := memebridge.ParseExprToRow(expr); err == nil {
+			t.Errorf("ParseExprToRow(%s): expected error", expr)
+		}
+	}
+	for _, expr := range []string{`[1]`, `[STRUCT(1 AS a), NULL]`} {
+		if _, err := memebridge.ParseExprToRows(expr); err == nil {
+			t.Errorf("ParseExprToRows(%s): expected error", expr)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package memebridge
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/cloudspannerecosystem/memefish"
+	"github.com/cloudspannerecosystem/memefish/ast"
+)
+
+// Schema is a set of table definitions built from Spanner DDL. It is the
+// input for schema-aware evaluation such as [Schema.InferParamTypes].
+//
+// Identifiers are matched case-insensitively, as in Spanner.
+type Schema struct {
+	tables map[string]*Table
+	order  []*Table
+}
+
+// Table is one table of a [Schema].
+type Table struct {
+	// Name is the table name as written in CREATE TABLE.
+	Name string
+	// Columns are the table columns in definition order.
+	Columns []*Column
+	// PrimaryKey lists the primary key column names in key order.
+	PrimaryKey []string
+}
+
+// Column is one column of a [Table].
+type Column struct {
+	// Name is the column name as written in the DDL.
+	Name string
+	// Type is the column type mapped by [MemefishSchemaTypeToSpannerpbType].
+	Type *sppb.Type
+	// Constraints are the length limits of the declared type.
+	Constraints TypeConstraints
+	// NotNull reports whether the column is declared NOT NULL.
+	NotNull bool
+	// Default is the DEFAULT expression of the column, or nil. ALTER
+	// COLUMN SET DEFAULT and DROP DEFAULT update it.
+	Default ast.Expr
+	// Generated is the expression of a generated column, or nil.
+	Generated ast.Expr
+	// Stored reports whether a generated column is STORED.
+	Stored bool
+	// Def is the column definition the column was last declared or altered
+	// by. ALTER COLUMN type and default changes update Type, Constraints,
+	// NotNull and Default but keep Def.
+	Def *ast.ColumnDef
+}
+
+// ParseSchema parses DDL statements with memefish and builds a [Schema].
+// filename is used only in error positions.
+func ParseSchema(filename, ddl string) (*Schema, error) {
+	ddls, err := memefish.ParseDDLs(filename, ddl)
+	if err != nil {
+		return nil, err
+	}
+	return NewSchema(ddls...)
+}
+
+// NewSchema builds a [Schema] from parsed DDL. CREATE TABLE, DROP TABLE and
+// the column-level ALTER TABLE forms (ADD COLUMN, DROP COLUMN, ALTER COLUMN
+// type changes) are applied in order; other DDL statements are ignored.
+func NewSchema(ddls ...ast.DDL) (*Schema, error) {
+	s := &Schema{tables: make(map[string]*Table)}
+	for _, ddl := range ddls {
+		if err := s.apply(ddl); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Tables returns the tables in definition order. The slice is a copy and
+// may be modified by the caller.
+func (s *Schema) Tables() []*Table {
+	return slices.Clone(s.order)
+}
+
+// Table looks up a table by name.
+func (s *Schema) Table(name string) (*Table, bool) {
+	if s == nil {
+		return nil, false
+	}
+	t, ok := s.tables[strings.ToUpper(name)]
+	return t, ok
+}
+
+// Column looks up a column by name.
+func (t *Table) Column(name string) (*Column, bool) {
+	for _, c := range t.Columns {
+		if strings.EqualFold(c.Name, name) {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+func (s *Schema) apply(ddl ast.DDL) error {
+	switch d := ddl.(type) {
+	case *ast.CreateTable:
+		name := pathName(d.Name)
+		if _, ok := s.Table(name); ok {
+			if d.IfNotExists {
+				return nil
+			}
+			return fmt.Errorf("duplicate table %s", name)
+		}
+		t := &Table{Name: name}
+		for _, def := range d.Columns {
+			col, err := NewColumn(def)
+			if err != nil {
+				return fmt.Errorf("table %s: %w", name, err)
+			}
+			if def.PrimaryKey {
+				t.PrimaryKey = append(t.PrimaryKey, col.Name)
+			}
+			t.Columns = append(t.Columns, col)
+		}
+		for _, key := range d.PrimaryKeys {
+			t.PrimaryKey = append(t.PrimaryKey, key.Name.Name)
+		}
+		s.tables[strings.ToUpper(name)] = t
+		s.order = append(s.order, t)
+	case *ast.DropTable:
+		name := pathName(d.Name)
+		t, ok := s.Table(name)
+		if !ok {
+			if d.IfExists {
+				return nil
+			}
+			return fmt.Errorf("unknown table %s", name)
+		}
+		delete(s.tables, strings.ToUpper(name))
+		for i, other := range s.order {
+			if other == t {
+				s.order = append(s.order[:i], s.order[i+1:]...)
+				break
+			}
+		}
+	case *ast.AlterTable:
+		name := pathName(d.Name)
+		t, ok := s.Table(name)
+		if !ok {
+			return fmt.Errorf("unknown table %s", name)
+		}
+		return t.alter(d.TableAlteration)
+	}
+	return nil
+}
+
+func (t *Table) alter(alteration ast.TableAlteration) error {
+	switch a := alteration.(type) {
+	case *ast.AddColumn:
+		if _, ok := t.Column(a.Column.Name.Name); ok {
+			if a.IfNotExists {
+				return nil
+			}
+			return fmt.Errorf("table %s: duplicate column %s", t.Name, a.Column.Name.Name)
+		}
+		col, err := NewColumn(a.Column)
+		if err != nil {
+			return fmt.Errorf("table %s: %w", t.Name, err)
+		}
+		t.Columns = append(t.Columns, col)
+	case *ast.DropColumn:
+		for i, c := range t.Columns {
+			if strings.EqualFold(c.Name, a.Name.Name) {
+				t.Columns = append(t.Columns[:i], t.Columns[i+1:]...)
+				return nil
+			}
+		}
+		return fmt.Errorf("table %s: unknown column %s", t.Name, a.Name.Name)
+	case *ast.AlterColumn:
+		col, ok := t.Column(a.Name.Name)
+		if !ok {
+			return fmt.Errorf("table %s: unknown column %s", t.Name, a.Name.Name)
+		}
+		switch alteration := a.Alteration.(type) {
+		case *ast.AlterColumnType:
+			typ, err := MemefishSchemaTypeToSpannerpbType(alteration.Type)
+			if err != nil {
+				return fmt.Errorf("table %s: column %s: %w", t.Name, col.Name, err)
+			}
+			constraints, err := MemefishSchemaTypeConstraints(alteration.Type)
+			if err != nil {
+				return fmt.Errorf("table %s: column %s: %w", t.Name, col.Name, err)
+			}
+			col.Type = typ
+			col.Constraints = constraints
+			col.NotNull = alteration.NotNull
+			// Like Spanner, a type change without DEFAULT drops the default.
+			col.Default = nil
+			if alteration.DefaultExpr != nil {
+				col.Default = alteration.DefaultExpr.Expr
+			}
+		case *ast.AlterColumnSetDefault:
+			col.Default = alteration.DefaultExpr.Expr
+		case *ast.AlterColumnDropDefault:
+			col.Default = nil
+		}
+	}
+	return nil
+}
+
+// NewColumn builds a [Column] from a column definition of CREATE TABLE or
+// ALTER TABLE ADD COLUMN, for callers that parse DDL themselves.
+func NewColumn(def *ast.ColumnDef) (*Column, error) {
+	typ, err := MemefishSchemaTypeToSpannerpbType(def.Type)
+	if err != nil {
+		return nil, fmt.Errorf("column %s: %w", def.Name.Name, err)
+	}
+	constraints, err := MemefishSchemaTypeConstraints(def.Type)
+	if err != nil {
+		return nil, fmt.Errorf("column %s: %w", def.Name.Name, err)
+	}
+	col := &Column{Name: def.Name.Name, Type: typ, Constraints: constraints, NotNull: def.NotNull, Def: def}
+	switch semantics := def.DefaultSemantics.(type) {
+	case *ast.ColumnDefaultExpr:
+		col.Default = semantics.Expr
+	case *ast.GeneratedColumnExpr:
+		col.Generated = semantics.Expr
+		col.Stored = !semantics.Stored.Invalid()
+	}
+	return col, nil
+}
+
+func pathName(path *ast.Path) string {
+	names := make([]string, len(path.Idents))
+	for i, ident := range path.Idents {
+		names[i] = ident.Name
+	}
+	return strings.Join(names, ".")
+}
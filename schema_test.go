@@ -0,0 +1,160 @@
+package memebridge_test
+
+import (
+	"testing"
+
+	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spantype/typector"
+	"github.com/apstndb/spanvalue/gcvctor"
+	"github.com/cloudspannerecosystem/memefish"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/apstndb/memebridge"
+)
+
+const testSchemaDDL = `
+CREATE TABLE Singers (
+  SingerId INT64 NOT NULL,
+  Name STRING(MAX),
+  Birthday DATE,
+  Tags ARRAY<STRING(MAX)>,
+) PRIMARY KEY (SingerId);
+
+CREATE TABLE Albums (
+  SingerId INT64 NOT NULL,
+  AlbumId INT64 NOT NULL,
+  Price NUMERIC,
+  ReleasedAt TIMESTAMP,
+) PRIMARY KEY (SingerId, AlbumId);
+
+ALTER TABLE Albums ADD COLUMN Rating FLOAT64;
+`
+
+func TestParseSchema(t *testing.T) {
+	schema, err := memebridge.ParseSchema("schema.sql", testSchemaDDL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(schema.Tables()); got != 2 {
+		t.Fatalf("len(Tables()) = %d, want 2", got)
+	}
+	tables := schema.Tables()
+	tables[0] = nil
+	if schema.Tables()[0] == nil {
+		t.Error("modifying the result of Tables() changed the schema")
+	}
+	albums := mustOk(schema.Table("albums"))
+	if diff := cmp.Diff([]string{"SingerId", "AlbumId"}, albums.PrimaryKey); diff != "" {
+		t.Errorf("PrimaryKey mismatch (-want +got):\n%s", diff)
+	}
+	rating := mustOk(albums.Column("RATING"))
+	if diff := cmp.Diff(typector.Float64(), rating.Type, protocmp.Transform()); diff != "" {
+		t.Errorf("Rating type mismatch (-want +got):\n%s", diff)
+	}
+	tags := mustOk(mustOk(schema.Table("Singers")).Column("Tags"))
+	if diff := cmp.Diff(typector.ElemCodeToArrayType(sppb.TypeCode_STRING), tags.Type, protocmp.Transform()); diff != "" {
+		t.Errorf("Tags type mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseSchemaErrors(t *testing.T) {
+	for _, ddl := range []string{
+		"CREATE TABLE T (Id INT64) PRIMARY KEY (Id); CREATE TABLE T (Id INT64) PRIMARY KEY (Id)",
+		"ALTER TABLE Missing ADD COLUMN C INT64",
+		"CREATE TABLE T (Id INT64, P examples.Proto) PRIMARY KEY (Id)",
+	} {
+		if _, err := memebridge.ParseSchema("", ddl); err == nil {
+			t.Errorf("ParseSchema(%q) succeeded, want error", ddl)
+		}
+	}
+}
+
+func TestInferParamTypes(t *testing.T) {
+	schema := must(memebridge.ParseSchema("", testSchemaDDL))
+	tests := []struct {
+		sql  string
+		want map[string]*sppb.Type
+	}{
+		{
+			"SELECT * FROM Singers WHERE Birthday = @d AND @name = Name",
+			map[string]*sppb.Type{"d": typector.Date(), "name": typector.String()},
+		},
+		{
+			"SELECT * FROM Albums AS a WHERE a.Price > (@p) AND a.SingerId IN (@s1, @s2)",
+			map[string]*sppb.Type{"p": typector.Numeric(), "s1": typector.Int64(), "s2": typector.Int64()},
+		},
+		{
+			"SELECT * FROM Albums WHERE ReleasedAt BETWEEN @lo AND @hi AND AlbumId IN UNNEST(@ids)",
+			map[string]*sppb.Type{
+				"lo":  typector.Timestamp(),
+				"hi":  typector.Timestamp(),
+				"ids": typector.ElemCodeToArrayType(sppb.TypeCode_INT64),
+			},
+		},
+		{
+			"INSERT INTO Singers (SingerId, Name, Birthday) VALUES (@id, @name, DEFAULT)",
+			map[string]*sppb.Type{"id": typector.Int64(), "name": typector.String()},
+		},
+		{
+			"UPDATE Albums SET Rating = @r WHERE SingerId = @s",
+			map[string]*sppb.Type{"r": typector.Float64(), "s": typector.Int64()},
+		},
+		{
+			"SELECT @unused, Name FROM Singers",
+			map[string]*sppb.Type{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt := must(memefish.ParseStatement("", tt.sql))
+			got, err := schema.InferParamTypes(stmt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("InferParamTypes mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestInferParamTypesConflict(t *testing.T) {
+	schema := must(memebridge.ParseSchema("", testSchemaDDL))
+	stmt := must(memefish.ParseStatement("", "SELECT * FROM Singers WHERE Name = @p OR Birthday = @p"))
+	if _, err := schema.InferParamTypes(stmt); err == nil {
+		t.Error("InferParamTypes succeeded, want conflict error")
+	}
+}
+
+func TestWithExpectedType(t *testing.T) {
+	tests := []struct {
+		expr    string
+		typ     *sppb.Type
+		want    spanner.GenericColumnValue
+		wantErr bool
+	}{
+		{`'2024-01-01'`, typector.Date(), must(memebridge.ParseExprToGCV(`DATE '2024-01-01'`)), false},
+		{`NULL`, typector.Date(), gcvctor.NullOf(typector.Date()), false},
+		{`1`, typector.Numeric(), must(memebridge.ParseExprToGCV(`CAST(1 AS NUMERIC)`)), false},
+		{`'x'`, typector.Int64(), spanner.GenericColumnValue{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := memebridge.ParseExprToGCV(tt.expr, memebridge.WithExpectedType(tt.typ))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseExprToGCV(%q) = %v, want error", tt.expr, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
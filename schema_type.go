@@ -0,0 +1,199 @@
+package memebridge
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spantype"
+	"github.com/apstndb/spantype/typector"
+	"github.com/cloudspannerecosystem/memefish"
+	"github.com/cloudspannerecosystem/memefish/ast"
+	"github.com/cloudspannerecosystem/memefish/token"
+)
+
+// TypeConstraints are the limits of a column type that spannerpb.Type does
+// not carry.
+type TypeConstraints struct {
+	// Length is n of STRING(n) or BYTES(n), in characters or bytes, or 0
+	// for MAX. For an ARRAY type, it is the length of the element type, as
+	// in ARRAY<STRING(10)>.
+	Length int64
+	// VectorLength is the vector_length of an ARRAY<FLOAT32> or
+	// ARRAY<FLOAT64> column, or 0 if it has none.
+	VectorLength int64
+}
+
+// ParseSchemaType parses a column type as written in DDL, such as
+// STRING(MAX), ARRAY<BYTES(16)> or ARRAY<FLOAT32>(vector_length=>128), or
+// as reported by INFORMATION_SCHEMA.COLUMNS.SPANNER_TYPE, which also spells
+// PROTO<my.pkg.Msg> and ENUM<my.Enum>. It returns the spannerpb.Type and the
+// limits it does not carry. The DDL spelling of a PROTO or ENUM type, a
+// bare my.pkg.Msg, cannot be told apart and is an error as in
+// [MemefishSchemaTypeToSpannerpbType].
+func ParseSchemaType(s string) (*sppb.Type, TypeConstraints, error) {
+	// Replace PROTO<name> and ENUM<name> by the name, which the DDL parser
+	// takes as a named type, and remember which one each name was.
+	kinds := make(map[string]sppb.TypeCode)
+	rewritten := protoOrEnumTypePattern.ReplaceAllStringFunc(strings.TrimSpace(s), func(m string) string {
+		sub := protoOrEnumTypePattern.FindStringSubmatch(m)
+		kinds[sub[2]] = sppb.TypeCode(sppb.TypeCode_value[strings.ToUpper(sub[1])])
+		return sub[2]
+	})
+
+	const prefix = "CREATE TABLE T (C "
+	ddl, err := memefish.ParseDDL("", prefix+rewritten+") PRIMARY KEY ()")
+	if err != nil {
+		return nil, TypeConstraints{}, fmt.Errorf("invalid schema type %q: %w", s, err)
+	}
+	// The column definition must be exactly the type, without NOT NULL,
+	// DEFAULT, OPTIONS or further columns.
+	create, ok := ddl.(*ast.CreateTable)
+	if !ok || len(create.Columns) != 1 || create.Columns[0].Type.End() != token.Pos(len(prefix)+len(rewritten)) {
+		return nil, TypeConstraints{}, fmt.Errorf("invalid schema type %q", s)
+	}
+	typ := create.Columns[0].Type
+
+	var t *sppb.Type
+	switch st := typ.(type) {
+	case *ast.NamedType:
+		t, ok = protoOrEnumType(st, kinds)
+	case *ast.ArraySchemaType:
+		if named, isNamed := st.Item.(*ast.NamedType); isNamed {
+			var elem *sppb.Type
+			if elem, ok = protoOrEnumType(named, kinds); ok {
+				t = typector.ElemTypeToArrayType(elem)
+			}
+		}
+	}
+	if t == nil {
+		if t, err = MemefishSchemaTypeToSpannerpbType(typ); err != nil {
+			return nil, TypeConstraints{}, err
+		}
+	}
+	c, err := MemefishSchemaTypeConstraints(typ)
+	if err != nil {
+		return nil, TypeConstraints{}, err
+	}
+	return t, c, nil
+}
+
+var protoOrEnumTypePattern = regexp.MustCompile(`(?i)\b(PROTO|ENUM)\s*<\s*([A-Za-z_][A-Za-z0-9_.]*)\s*>`)
+
+func protoOrEnumType(named *ast.NamedType, kinds map[string]sppb.TypeCode) (*sppb.Type, bool) {
+	fqn := pathName(&ast.Path{Idents: named.Path})
+	code, ok := kinds[fqn]
+	if !ok {
+		return nil, false
+	}
+	return &sppb.Type{Code: code, ProtoTypeFqn: fqn}, true
+}
+
+// MemefishSchemaTypeConstraints returns the length and vector length of a
+// memefish DDL column type, which [MemefishSchemaTypeToSpannerpbType] drops.
+func MemefishSchemaTypeConstraints(typ ast.SchemaType) (TypeConstraints, error) {
+	switch t := typ.(type) {
+	case *ast.SizedSchemaType:
+		if t.Max {
+			return TypeConstraints{}, nil
+		}
+		n, err := schemaTypeInt(t.Size)
+		if err != nil || n <= 0 {
+			return TypeConstraints{}, fmt.Errorf("invalid length of %s", t.SQL())
+		}
+		return TypeConstraints{Length: n}, nil
+	case *ast.ArraySchemaType:
+		if t.Item == nil {
+			return TypeConstraints{}, fmt.Errorf("invalid array schema type: %s", t.SQL())
+		}
+		c, err := MemefishSchemaTypeConstraints(t.Item)
+		if err != nil {
+			return TypeConstraints{}, err
+		}
+		for _, arg := range t.NamedArgs {
+			if !strings.EqualFold(arg.Name.Name, "vector_length") {
+				return TypeConstraints{}, fmt.Errorf("unknown argument %s of %s", arg.Name.Name, t.SQL())
+			}
+			n, err := schemaTypeInt(arg.Value)
+			if err != nil || n <= 0 {
+				return TypeConstraints{}, fmt.Errorf("invalid vector_length of %s", t.SQL())
+			}
+			c.VectorLength = n
+		}
+		if c.VectorLength > 0 {
+			if scalar, ok := t.Item.(*ast.ScalarSchemaType); !ok || scalar.Name != ast.Float32TypeName && scalar.Name != ast.Float64TypeName {
+				return TypeConstraints{}, fmt.Errorf("vector_length requires ARRAY<FLOAT32> or ARRAY<FLOAT64>, got %s", t.SQL())
+			}
+		}
+		return c, nil
+	}
+	return TypeConstraints{}, nil
+}
+
+func schemaTypeInt(v ast.Node) (int64, error) {
+	lit, ok := v.(*ast.IntLiteral)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedExpr, v.SQL())
+	}
+	return strconv.ParseInt(lit.Value, lit.Base, 64)
+}
+
+// FormatSchemaType renders t with the limits c in DDL spelling, such as
+// STRING(MAX), ARRAY<STRING(10)> or ARRAY<FLOAT32>(vector_length=>128).
+// PROTO and ENUM types render as their fully qualified name, as CREATE
+// TABLE writes them. Limits that t cannot have are an error.
+func FormatSchemaType(t *sppb.Type, c TypeConstraints) (string, error) {
+	if c.Length < 0 || c.VectorLength < 0 {
+		return "", fmt.Errorf("negative limits: %+v", c)
+	}
+	switch t.GetCode() {
+	case sppb.TypeCode_ARRAY:
+		elem := t.GetArrayElementType()
+		if elem.GetCode() == sppb.TypeCode_ARRAY {
+			return "", fmt.Errorf("%w: nested ARRAY", ErrUnsupportedType)
+		}
+		s, err := FormatSchemaType(elem, TypeConstraints{Length: c.Length})
+		if err != nil {
+			return "", err
+		}
+		s = "ARRAY<" + s + ">"
+		if c.VectorLength > 0 {
+			if code := elem.GetCode(); code != sppb.TypeCode_FLOAT32 && code != sppb.TypeCode_FLOAT64 {
+				return "", fmt.Errorf("vector_length requires ARRAY<FLOAT32> or ARRAY<FLOAT64>, got %s", s)
+			}
+			s += fmt.Sprintf("(vector_length=>%d)", c.VectorLength)
+		}
+		return s, nil
+	case sppb.TypeCode_STRUCT:
+		if c != (TypeConstraints{}) {
+			return "", fmt.Errorf("STRUCT cannot have limits %+v", c)
+		}
+		// Fields of a STRUCT column type are written as in queries, without
+		// lengths.
+		return spantype.FormatTypeVerbose(t), nil
+	case sppb.TypeCode_STRING, sppb.TypeCode_BYTES:
+		if c.VectorLength > 0 {
+			return "", fmt.Errorf("vector_length requires ARRAY<FLOAT32> or ARRAY<FLOAT64>, got %s", t.GetCode())
+		}
+		if c.Length == 0 {
+			return t.GetCode().String() + "(MAX)", nil
+		}
+		return fmt.Sprintf("%s(%d)", t.GetCode(), c.Length), nil
+	case sppb.TypeCode_PROTO, sppb.TypeCode_ENUM:
+		if c != (TypeConstraints{}) {
+			return "", fmt.Errorf("%s cannot have limits %+v", t.GetCode(), c)
+		}
+		if t.GetProtoTypeFqn() == "" {
+			return "", fmt.Errorf("%s type without a name", t.GetCode())
+		}
+		return t.GetProtoTypeFqn(), nil
+	case sppb.TypeCode_TYPE_CODE_UNSPECIFIED:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedType, t.GetCode())
+	}
+	if c != (TypeConstraints{}) {
+		return "", fmt.Errorf("%s cannot have limits %+v", t.GetCode(), c)
+	}
+	return t.GetCode().String(), nil
+}
@@ -0,0 +1,97 @@
+package memebridge_test
+
+import (
+	"testing"
+
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spantype/typector"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/apstndb/memebridge"
+)
+
+func TestParseSchemaType(t *testing.T) {
+	for _, tt := range []struct {
+		input       string
+		want        *sppb.Type
+		constraints memebridge.TypeConstraints
+		ddl         string
+	}{
+		{"INT64", typector.Int64(), memebridge.TypeConstraints{}, "INT64"},
+		{"STRING(MAX)", typector.String(), memebridge.TypeConstraints{}, "STRING(MAX)"},
+		{"BYTES(16)", typector.Bytes(), memebridge.TypeConstraints{Length: 16}, "BYTES(16)"},
+		{"ARRAY<STRING(1024)>", typector.ElemCodeToArrayType(sppb.TypeCode_STRING), memebridge.TypeConstraints{Length: 1024}, "ARRAY<STRING(1024)>"},
+		{"ARRAY<FLOAT32>(vector_length=>128)", typector.ElemCodeToArrayType(sppb.TypeCode_FLOAT32), memebridge.TypeConstraints{VectorLength: 128}, "ARRAY<FLOAT32>(vector_length=>128)"},
+		{"PROTO<my.pkg.Msg>", &sppb.Type{Code: sppb.TypeCode_PROTO, ProtoTypeFqn: "my.pkg.Msg"}, memebridge.TypeConstraints{}, "my.pkg.Msg"},
+		{"ARRAY<ENUM<my.Enum>>", typector.ElemTypeToArrayType(&sppb.Type{Code: sppb.TypeCode_ENUM, ProtoTypeFqn: "my.Enum"}), memebridge.TypeConstraints{}, "ARRAY<my.Enum>"},
+		{"STRUCT<a INT64, b STRING>", typector.MustNameTypeSlicesToStructType([]string{"a", "b"}, []*sppb.Type{typector.Int64(), typector.String()}), memebridge.TypeConstraints{}, "STRUCT<a INT64, b STRING>"},
+		{" uuid ", typector.UUID(), memebridge.TypeConstraints{}, "UUID"},
+	} {
+		got, constraints, err := memebridge.ParseSchemaType(tt.input)
+		if err != nil {
+			t.Errorf("ParseSchemaType(%q): %v", tt.input, err)
+			continue
+		}
+		if diff := cmp.Diff(tt.want, got, protocmp.Transform()); diff != "" {
+			t.Errorf("ParseSchemaType(%q) type mismatch (-want +got):\n%s", tt.input, diff)
+		}
+		if constraints != tt.constraints {
+			t.Errorf("ParseSchemaType(%q) constraints = %+v, want %+v", tt.input, constraints, tt.constraints)
+		}
+		ddl, err := memebridge.FormatSchemaType(got, constraints)
+		if err != nil || ddl != tt.ddl {
+			t.Errorf("FormatSchemaType(%q) = %q, %v; want %q", tt.input, ddl, err, tt.ddl)
+		}
+	}
+}
+
+func TestParseSchemaTypeErrors(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"INT64 NOT NULL",
+		"INT64, D INT64",
+		"STRING(0)",
+		"ARRAY<INT64>(vector_length=>3)",
+		"ARRAY<FLOAT64>(dims=>3)",
+		"my.pkg.Msg",
+	} {
+		if _, _, err := memebridge.ParseSchemaType(input); err == nil {
+			t.Errorf("ParseSchemaType(%q): expected error", input)
+		}
+	}
+}
+
+func TestFormatSchemaTypeErrors(t *testing.T) {
+	for _, tt := range []struct {
+		typ         *sppb.Type
+		constraints memebridge.TypeConstraints
+	}{
+		{typector.Int64(), memebridge.TypeConstraints{Length: 10}},
+		{typector.ElemCodeToArrayType(sppb.TypeCode_STRING), memebridge.TypeConstraints{VectorLength: 3}},
+		{typector.ElemTypeToArrayType(typector.ElemCodeToArrayType(sppb.TypeCode_INT64)), memebridge.TypeConstraints{}},
+	} {
+		if s, err := memebridge.FormatSchemaType(tt.typ, tt.constraints); err == nil {
+			t.Errorf("FormatSchemaType(%v, %+v) = %q, expected error", tt.typ, tt.constraints, s)
+		}
+	}
+}
+
+func TestColumnConstraints(t *testing.T) {
+	schema, err := memebridge.ParseSchema("schema.sql", `
+CREATE TABLE Docs (Id INT64, Title STRING(10), Embedding ARRAY<FLOAT32>(vector_length=>3)) PRIMARY KEY (Id);
+ALTER TABLE Docs ALTER COLUMN Title STRING(20);
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs, _ := schema.Table("Docs")
+	for name, want := range map[string]memebridge.TypeConstraints{
+		"Title":     {Length: 20},
+		"Embedding": {VectorLength: 3},
+	} {
+		if col, _ := docs.Column(name); col.Constraints != want {
+			t.Errorf("%s: Constraints = %+v, want %+v", name, col.Constraints, want)
+		}
+	}
+}
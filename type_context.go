@@ -0,0 +1,131 @@
+package memebridge
+
+import (
+	"errors"
+	"fmt"
+
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spantype"
+)
+
+// ErrTypeNotAllowed is wrapped by the errors of [ValidateType] for types
+// that Spanner rejects in the given context.
+var ErrTypeNotAllowed = errors.New("type not allowed")
+
+// Context is where a type is used, which decides the types Spanner accepts
+// there; see [ValidateType].
+type Context int
+
+const (
+	// ContextQueryParameter is the type of a query parameter.
+	ContextQueryParameter Context = iota + 1
+	// ContextColumn is the type of a table column.
+	ContextColumn
+	// ContextResultColumn is the type of a column of a query result.
+	ContextResultColumn
+	// ContextKeyColumn is the type of a primary key column.
+	ContextKeyColumn
+)
+
+func (c Context) String() string {
+	switch c {
+	case ContextQueryParameter:
+		return "query parameter"
+	case ContextColumn:
+		return "column"
+	case ContextResultColumn:
+		return "result column"
+	case ContextKeyColumn:
+		return "key column"
+	}
+	return fmt.Sprintf("Context(%d)", int(c))
+}
+
+// ValidateType reports an error wrapping [ErrTypeNotAllowed] if Spanner
+// rejects t in ctx, although memebridge can build values of it:
+//
+//   - an ARRAY directly in an ARRAY, such as ARRAY<ARRAY<INT64>>, anywhere
+//   - a STRUCT in a column type, at any depth, and INTERVAL columns
+//   - a STRUCT result column; ARRAY<STRUCT<...>> is allowed
+//   - an ARRAY, JSON or PROTO key column
+//
+// Types without a type code are rejected everywhere. The error names the
+// offending part of t, such as "element of field a".
+func ValidateType(t *sppb.Type, ctx Context) error {
+	switch ctx {
+	case ContextQueryParameter, ContextColumn, ContextResultColumn, ContextKeyColumn:
+	default:
+		return fmt.Errorf("unknown type context %v", ctx)
+	}
+	problem := topLevelTypeProblem(t, ctx)
+	if problem == "" {
+		problem = nestedTypeProblem(t, ctx, "")
+	}
+	if problem == "" {
+		return nil
+	}
+	return fmt.Errorf("%w: %s as a %s: %s", ErrTypeNotAllowed, spantype.FormatTypeNormal(t), ctx, problem)
+}
+
+// topLevelTypeProblem describes why t cannot be the whole type in ctx.
+func topLevelTypeProblem(t *sppb.Type, ctx Context) string {
+	switch code := t.GetCode(); ctx {
+	case ContextResultColumn:
+		if code == sppb.TypeCode_STRUCT {
+			return "a STRUCT cannot be returned as a column value; flatten its fields or wrap it in an ARRAY"
+		}
+	case ContextKeyColumn:
+		switch code {
+		case sppb.TypeCode_ARRAY, sppb.TypeCode_JSON, sppb.TypeCode_PROTO:
+			return fmt.Sprintf("%s cannot be a key", code)
+		}
+	}
+	return ""
+}
+
+// nestedTypeProblem walks t and describes the first part of it that ctx
+// does not allow, with path naming where t is in the whole type.
+func nestedTypeProblem(t *sppb.Type, ctx Context, path string) string {
+	at := func(problem string) string {
+		if path == "" {
+			return problem
+		}
+		return problem + " (at " + path + ")"
+	}
+	switch t.GetCode() {
+	case sppb.TypeCode_TYPE_CODE_UNSPECIFIED:
+		return at("type code is unspecified")
+	case sppb.TypeCode_INTERVAL:
+		if ctx == ContextColumn || ctx == ContextKeyColumn {
+			return at("INTERVAL cannot be stored in a column")
+		}
+	case sppb.TypeCode_ARRAY:
+		elem := t.GetArrayElementType()
+		if elem.GetCode() == sppb.TypeCode_ARRAY {
+			return at("an ARRAY cannot directly contain an ARRAY; wrap the inner ARRAY in a STRUCT")
+		}
+		return nestedTypeProblem(elem, ctx, joinTypePath("element", path))
+	case sppb.TypeCode_STRUCT:
+		if ctx == ContextColumn || ctx == ContextKeyColumn {
+			return at("STRUCT cannot be a column type")
+		}
+		for i, f := range t.GetStructType().GetFields() {
+			name := f.GetName()
+			if name == "" {
+				name = fmt.Sprintf("%d", i+1)
+			}
+			if problem := nestedTypeProblem(f.GetType(), ctx, joinTypePath("field "+name, path)); problem != "" {
+				return problem
+			}
+		}
+	}
+	return ""
+}
+
+// joinTypePath names part inside the part named by path.
+func joinTypePath(part, path string) string {
+	if path == "" {
+		return part
+	}
+	return part + " of " + path
+}
@@ -0,0 +1,68 @@
+package memebridge_test
+
+import (
+	"errors"
+	"testing"
+
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spantype/typector"
+
+	"github.com/apstndb/memebridge"
+)
+
+func TestValidateType(t *testing.T) {
+	arrayOf := typector.ElemTypeToArrayType
+	structOf := func(name string, typ *sppb.Type) *sppb.Type {
+		return typector.MustNameTypeSlicesToStructType([]string{name}, []*sppb.Type{typ})
+	}
+	nested := arrayOf(arrayOf(typector.Int64()))
+	for _, tt := range []struct {
+		typ     *sppb.Type
+		ctx     memebridge.Context
+		wantErr string
+	}{
+		{typector.Int64(), memebridge.ContextKeyColumn, ""},
+		{arrayOf(structOf("a", typector.Int64())), memebridge.ContextQueryParameter, ""},
+		{arrayOf(structOf("a", typector.Int64())), memebridge.ContextResultColumn, ""},
+		{arrayOf(typector.JSON()), memebridge.ContextColumn, ""},
+		{nested, memebridge.ContextQueryParameter,
+			"type not allowed: ARRAY<ARRAY<INT64>> as a query parameter: an ARRAY cannot directly contain an ARRAY; wrap the inner ARRAY in a STRUCT"},
+		{structOf("a", nested), memebridge.ContextQueryParameter,
+			"type not allowed: STRUCT<ARRAY<ARRAY<INT64>>> as a query parameter: an ARRAY cannot directly contain an ARRAY; wrap the inner ARRAY in a STRUCT (at field a)"},
+		{arrayOf(structOf("a", nested)), memebridge.ContextResultColumn,
+			"type not allowed: ARRAY<STRUCT<ARRAY<ARRAY<INT64>>>> as a result column: an ARRAY cannot directly contain an ARRAY; wrap the inner ARRAY in a STRUCT (at field a of element)"},
+		{structOf("a", typector.Int64()), memebridge.ContextResultColumn,
+			"type not allowed: STRUCT<INT64> as a result column: a STRUCT cannot be returned as a column value; flatten its fields or wrap it in an ARRAY"},
+		{arrayOf(structOf("a", typector.Int64())), memebridge.ContextColumn,
+			"type not allowed: ARRAY<STRUCT<INT64>> as a column: STRUCT cannot be a column type (at element)"},
+		{typector.JSON(), memebridge.ContextKeyColumn,
+			"type not allowed: JSON as a key column: JSON cannot be a key"},
+		{typector.Interval(), memebridge.ContextColumn,
+			"type not allowed: INTERVAL as a column: INTERVAL cannot be stored in a column"},
+		{&sppb.Type{}, memebridge.ContextResultColumn,
+			"type not allowed: TYPE_CODE_UNSPECIFIED as a result column: type code is unspecified"},
+	} {
+		err := memebridge.ValidateType(tt.typ, tt.ctx)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("ValidateType(%v, %v): %v", tt.typ, tt.ctx, err)
+		case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+			t.Errorf("ValidateType(%v, %v) = %v, want %q", tt.typ, tt.ctx, err, tt.wantErr)
+		case err != nil && !errors.Is(err, memebridge.ErrTypeNotAllowed):
+			t.Errorf("ValidateType(%v, %v) = %v, want ErrTypeNotAllowed", tt.typ, tt.ctx, err)
+		}
+	}
+}
+
+func TestWithParamContext(t *testing.T) {
+	const expr = `CAST(NULL AS ARRAY<ARRAY<INT64>>)`
+	if _, err := memebridge.ParseExprToGCV(expr); err != nil {
+		t.Fatalf("without WithParamContext: %v", err)
+	}
+	if _, err := memebridge.ParseExprToGCV(expr, memebridge.WithParamContext()); !errors.Is(err, memebridge.ErrTypeNotAllowed) {
+		t.Errorf("err = %v, want ErrTypeNotAllowed", err)
+	}
+	if _, err := memebridge.ParseExprToGCV(`[STRUCT(1 AS a)]`, memebridge.WithParamContext()); err != nil {
+		t.Errorf("ARRAY<STRUCT>: %v", err)
+	}
+}
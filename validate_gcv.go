@@ -0,0 +1,180 @@
+package memebridge
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spantype"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ValueError reports a wire value that does not match its type, found by
+// [ValidateGCV].
+type ValueError struct {
+	// Path locates the value inside the validated one, with 0-based ARRAY
+	// offsets and STRUCT field names (or offsets for unnamed fields), such
+	// as "[2].price". It is empty for the validated value itself.
+	Path string
+	// Type is the type of the value at Path.
+	Type *sppb.Type
+	// Err describes the problem.
+	Err error
+}
+
+func (e *ValueError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("invalid %s value: %v", spantype.FormatTypeNormal(e.Type), e.Err)
+	}
+	return fmt.Sprintf("invalid %s value at %s: %v", spantype.FormatTypeNormal(e.Type), e.Path, e.Err)
+}
+
+func (e *ValueError) Unwrap() error { return e.Err }
+
+// ValidateGCV checks that the wire value of gcv is one Spanner accepts for
+// its type, for values that did not come from memebridge evaluation, such
+// as decoded REST responses, hand-built fixtures or values kept by
+// [WithLegacyArrayWirePassthrough]. It walks ARRAY and STRUCT values and
+// checks every leaf: INT64 and ENUM are decimal strings in range, FLOAT64
+// and FLOAT32 are numbers or the strings "NaN", "Infinity" and
+// "-Infinity", BYTES and PROTO are base64, NUMERIC strings are decimals
+// within 29 integer and 9 fractional digits, DATE, TIMESTAMP, UUID,
+// INTERVAL and JSON strings parse, and a STRUCT has one value per field.
+// NULL is valid for every type, and TIMESTAMP accepts the commit timestamp
+// placeholder of mutations. The first invalid value is returned as a
+// [*ValueError].
+func ValidateGCV(gcv spanner.GenericColumnValue) error {
+	return validateWireValue(gcv.Type, gcv.Value, "")
+}
+
+func validateWireValue(t *sppb.Type, v *structpb.Value, path string) error {
+	fail := func(format string, args ...any) error {
+		return &ValueError{Path: path, Type: t, Err: fmt.Errorf(format, args...)}
+	}
+	if v == nil {
+		return fail("missing value")
+	}
+	if _, ok := v.GetKind().(*structpb.Value_NullValue); ok {
+		if t.GetCode() == sppb.TypeCode_TYPE_CODE_UNSPECIFIED {
+			return fail("type code is unspecified")
+		}
+		return nil
+	}
+	gcv := spanner.GenericColumnValue{Type: t, Value: v}
+
+	var err error
+	switch t.GetCode() {
+	case sppb.TypeCode_BOOL:
+		_, err = boolFromGCV(gcv)
+	case sppb.TypeCode_INT64, sppb.TypeCode_ENUM:
+		_, err = int64FromGCV(gcv)
+	case sppb.TypeCode_FLOAT64, sppb.TypeCode_FLOAT32:
+		err = validateFloatWireValue(v, t.GetCode())
+	case sppb.TypeCode_STRING:
+		_, err = stringFromGCV(gcv)
+	case sppb.TypeCode_BYTES, sppb.TypeCode_PROTO:
+		_, err = bytesFromGCV(gcv)
+	case sppb.TypeCode_DATE:
+		_, err = dateFromGCV(gcv)
+	case sppb.TypeCode_TIMESTAMP:
+		var s string
+		if s, err = stringFromGCV(gcv); err == nil && s != commitTimestampPlaceholderString {
+			_, err = time.Parse(time.RFC3339Nano, s)
+		}
+	case sppb.TypeCode_NUMERIC:
+		err = validateNumericWireValue(gcv)
+	case sppb.TypeCode_JSON:
+		var s string
+		if s, err = stringFromGCV(gcv); err == nil && !json.Valid([]byte(s)) {
+			err = errors.New("not valid JSON")
+		}
+	case sppb.TypeCode_UUID:
+		var s string
+		if s, err = stringFromGCV(gcv); err == nil {
+			_, err = uuid.Parse(s)
+		}
+	case sppb.TypeCode_INTERVAL:
+		var s string
+		if s, err = stringFromGCV(gcv); err == nil {
+			_, err = spanner.ParseInterval(s)
+		}
+	case sppb.TypeCode_ARRAY:
+		list, ok := v.GetKind().(*structpb.Value_ListValue)
+		if !ok {
+			return fail("expected ARRAY wire value, got %T", v.GetKind())
+		}
+		for i, elem := range list.ListValue.GetValues() {
+			if err := validateWireValue(t.GetArrayElementType(), elem, path+"["+strconv.Itoa(i)+"]"); err != nil {
+				return err
+			}
+		}
+	case sppb.TypeCode_STRUCT:
+		list, ok := v.GetKind().(*structpb.Value_ListValue)
+		if !ok {
+			return fail("expected STRUCT wire value, got %T", v.GetKind())
+		}
+		fields := t.GetStructType().GetFields()
+		values := list.ListValue.GetValues()
+		if len(values) != len(fields) {
+			return fail("has %d values for %d fields", len(values), len(fields))
+		}
+		for i, f := range fields {
+			name := f.GetName()
+			if name == "" {
+				name = strconv.Itoa(i)
+			}
+			if err := validateWireValue(f.GetType(), values[i], path+"."+name); err != nil {
+				return err
+			}
+		}
+	default:
+		return fail("%w: %s", ErrUnsupportedType, t.GetCode())
+	}
+	if err != nil {
+		return &ValueError{Path: path, Type: t, Err: err}
+	}
+	return nil
+}
+
+// validateNumericWireValue accepts a decimal string within the precision
+// and scale of NUMERIC.
+func validateNumericWireValue(gcv spanner.GenericColumnValue) error {
+	n, err := numericFromGCV(gcv)
+	if err != nil {
+		return err
+	}
+	scaled := new(big.Rat).Mul(n, new(big.Rat).SetInt(numericScaleFactor))
+	if !scaled.IsInt() {
+		return fmt.Errorf("has more than %d fractional digits", spanner.NumericScaleDigits)
+	}
+	if new(big.Int).Abs(scaled.Num()).Cmp(maxScaledNumeric) > 0 {
+		return fmt.Errorf("has more than %d integer digits", spanner.NumericPrecisionDigits-spanner.NumericScaleDigits)
+	}
+	return nil
+}
+
+// validateFloatWireValue accepts a number, finite within the range of
+// FLOAT32 for a FLOAT32, or one of the string forms of non-finite values.
+func validateFloatWireValue(v *structpb.Value, code sppb.TypeCode) error {
+	switch k := v.GetKind().(type) {
+	case *structpb.Value_NumberValue:
+		if code == sppb.TypeCode_FLOAT32 && math.Abs(k.NumberValue) > math.MaxFloat32 && !math.IsInf(k.NumberValue, 0) {
+			return fmt.Errorf("%v is out of range for FLOAT32", k.NumberValue)
+		}
+		return nil
+	case *structpb.Value_StringValue:
+		switch k.StringValue {
+		case "NaN", "Infinity", "-Infinity":
+			return nil
+		}
+		return fmt.Errorf("string wire value %q is not NaN, Infinity or -Infinity", k.StringValue)
+	}
+	return fmt.Errorf("expected floating-point wire value, got %T", v.GetKind())
+}
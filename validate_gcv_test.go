@@ -0,0 +1,103 @@
+package memebridge_test
+
+import (
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/apstndb/spantype/typector"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/apstndb/memebridge"
+)
+
+func TestValidateGCV(t *testing.T) {
+	for _, expr := range []string{
+		`[STRUCT(1 AS id, 1.5 AS price, b'\x00' AS raw, NULL AS note)]`,
+		`[CAST('NaN' AS FLOAT64), CAST('inf' AS FLOAT64)]`,
+		`(DATE '2024-01-01', TIMESTAMP '2024-01-01T00:00:00Z', NUMERIC '1.25', JSON '{"a": 1}')`,
+		`(CAST('0f8fad5b-d9cb-469f-a165-70867728950e' AS UUID), INTERVAL 1 DAY, PENDING_COMMIT_TIMESTAMP())`,
+		`CAST(NULL AS ARRAY<STRING>)`,
+	} {
+		if err := memebridge.ValidateGCV(must(memebridge.ParseExprToGCV(expr))); err != nil {
+			t.Errorf("%s: %v", expr, err)
+		}
+	}
+}
+
+func TestValidateGCVErrors(t *testing.T) {
+	item := typector.MustNameTypeSlicesToStructType([]string{"id", "price"}, []*sppb.Type{typector.Int64(), typector.Float64()})
+	items := typector.ElemTypeToArrayType(item)
+	row := func(values ...*structpb.Value) *structpb.Value {
+		return structpb.NewListValue(&structpb.ListValue{Values: values})
+	}
+	for _, tt := range []struct {
+		name string
+		gcv  spanner.GenericColumnValue
+		path string
+		want string
+	}{
+		{
+			"INT64 out of range",
+			spanner.GenericColumnValue{Type: items, Value: row(
+				row(structpb.NewStringValue("1"), structpb.NewNumberValue(1)),
+				row(structpb.NewStringValue("9223372036854775808"), structpb.NewNumberValue(1)),
+			)},
+			"[1].id",
+			`invalid INT64 value at [1].id: strconv.ParseInt: parsing "9223372036854775808": value out of range`,
+		},
+		{
+			"FLOAT64 string form",
+			spanner.GenericColumnValue{Type: items, Value: row(row(structpb.NewStringValue("1"), structpb.NewStringValue("1.5")))},
+			"[0].price",
+			`invalid FLOAT64 value at [0].price: string wire value "1.5" is not NaN, Infinity or -Infinity`,
+		},
+		{
+			"STRUCT arity",
+			spanner.GenericColumnValue{Type: items, Value: row(row(structpb.NewStringValue("1")))},
+			"[0]",
+			`invalid STRUCT<INT64, FLOAT64> value at [0]: has 1 values for 2 fields`,
+		},
+		{
+			"BYTES base64",
+			spanner.GenericColumnValue{Type: typector.Bytes(), Value: structpb.NewStringValue("!!")},
+			"",
+			`invalid BYTES value: illegal base64 data at input byte 0`,
+		},
+		{
+			"JSON",
+			spanner.GenericColumnValue{Type: typector.JSON(), Value: structpb.NewStringValue("{")},
+			"",
+			`invalid JSON value: not valid JSON`,
+		},
+		{
+			"NUMERIC precision",
+			spanner.GenericColumnValue{Type: typector.Numeric(), Value: structpb.NewStringValue("123456789012345678901234567890")},
+			"",
+			`invalid NUMERIC value: has more than 29 integer digits`,
+		},
+		{
+			"NUMERIC scale",
+			spanner.GenericColumnValue{Type: typector.Numeric(), Value: structpb.NewStringValue("1.1234567891")},
+			"",
+			`invalid NUMERIC value: has more than 9 fractional digits`,
+		},
+		{
+			"DATE",
+			spanner.GenericColumnValue{Type: typector.ElemCodeToArrayType(sppb.TypeCode_DATE), Value: row(structpb.NewStringValue("2024-02-30"))},
+			"[0]",
+			`invalid DATE value at [0]: parsing time "2024-02-30": day out of range`,
+		},
+	} {
+		err := memebridge.ValidateGCV(tt.gcv)
+		var verr *memebridge.ValueError
+		if !errors.As(err, &verr) {
+			t.Errorf("%s: err = %v, want a *ValueError", tt.name, err)
+			continue
+		}
+		if verr.Path != tt.path || err.Error() != tt.want {
+			t.Errorf("%s: err = %q at %q, want %q at %q", tt.name, err, verr.Path, tt.want, tt.path)
+		}
+	}
+}